@@ -1,48 +1,251 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
 	"os"
 	"pvz-test/internal/app"
+	"pvz-test/internal/config"
+	"pvz-test/internal/grpchandler"
 	"pvz-test/internal/handler"
+	"pvz-test/internal/metrics"
+	"pvz-test/internal/rbac"
 	"pvz-test/internal/repository"
 	"pvz-test/internal/service"
 	"pvz-test/pkg/httpserver"
+	"pvz-test/pkg/pvz_v1"
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 )
 
 func main() {
 
 	logrus.SetFormatter(new(logrus.JSONFormatter))
 
-	if err := godotenv.Load(); err != nil {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		logrus.Fatalf("Loading env variables error: %s", err.Error())
 	}
 
-	app.Migrations()
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		logrus.Fatalf("Config load error: %s", err.Error())
+	}
+
+	migrationVersion := app.Migrations(cfg.Postgres.ConnURL, cfg.Postgres.SSLMode, cfg.Debug())
 
 	db, err := repository.NewPostgresDB(repository.Config{
-		Host:     os.Getenv("POSTGRES_HOST"),
-		Port:     os.Getenv("POSTGRES_PORT"),
-		Usename:  os.Getenv("POSTGRES_USERNAME"),
-		Password: os.Getenv("POSTGRES_PASSWORD"),
-		DBName:   os.Getenv("POSTGRES_DATABASE"),
-		SSLmode:  "disable",
+		Host:            cfg.Postgres.Host,
+		Port:            cfg.Postgres.Port,
+		Usename:         cfg.Postgres.Username,
+		Password:        cfg.Postgres.Password,
+		DBName:          cfg.Postgres.Database,
+		SSLmode:         cfg.Postgres.SSLMode,
+		MaxOpenConns:    cfg.Postgres.MaxOpenConns,
+		MaxIdleConns:    cfg.Postgres.MaxIdleConns,
+		ConnMaxLifetime: cfg.Postgres.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.Postgres.ConnMaxIdleTime,
 	})
 	if err != nil {
 		logrus.Fatalf("DB init fail: %s", err.Error())
 	}
 	logrus.Info("DB init complete")
 
+	signingKeys, err := service.ParseKeySpecs(cfg.JWT.Keys)
+	if err != nil {
+		logrus.Fatalf("JWT keys parse error: %s", err.Error())
+	}
+	keyring, err := service.NewKeyring(cfg.JWT.ActiveKID, signingKeys...)
+	if err != nil {
+		logrus.Fatalf("JWT keyring init error: %s", err.Error())
+	}
+
+	passwordHasher, err := service.NewPasswordHasher(cfg.Password.Hasher)
+	if err != nil {
+		logrus.Fatalf("Password hasher init error: %s", err.Error())
+	}
+
+	policy, err := rbac.FromConfig(cfg.RBAC.Roles)
+	if err != nil {
+		logrus.Fatalf("RBAC policy error: %s", err.Error())
+	}
+
+	readiness := app.NewReadiness()
 	repos := repository.NewRepository(db)
-	service := service.NewService(repos)
-	handlers := handler.NewHandler(service)
+	service := service.NewService(repos, service.Config{
+		Auth: service.AuthConfig{
+			Keyring:        keyring,
+			TokenTTL:       cfg.JWT.TokenTTL,
+			RefreshTTL:     cfg.JWT.RefreshTTL,
+			PasswordHasher: passwordHasher,
+			PasswordSalt:   cfg.Password.Salt,
+		},
+		Health: service.HealthConfig{
+			CheckTimeout:     cfg.Health.CheckTimeout,
+			MigrationVersion: migrationVersion,
+			MaxPoolUsage:     cfg.Health.MaxPoolUsage,
+			Readiness:        readiness,
+		},
+		Outbox: service.OutboxConfig{
+			Sinks:     eventSinks(cfg.Outbox, repos),
+			BatchSize: cfg.Outbox.BatchSize,
+			Lease:     cfg.Outbox.Lease,
+			RetryBase: cfg.Outbox.RetryBase,
+			RetryMax:  cfg.Outbox.RetryMax,
+		},
+		Webhooks: service.WebhookDeliveryConfig{
+			BatchSize:   cfg.Webhooks.BatchSize,
+			Lease:       cfg.Webhooks.Lease,
+			Timeout:     cfg.Webhooks.Timeout,
+			MaxAttempts: cfg.Webhooks.MaxAttempts,
+			RetryBase:   cfg.Webhooks.RetryBase,
+			RetryMax:    cfg.Webhooks.RetryMax,
+		},
+		IdempotencyTTL: cfg.Idempotency.TTL,
+		CityCacheTTL:   cfg.PVZ.CityCacheTTL,
+	})
+	handlers := handler.NewHandler(service, handler.Config{
+		DefaultPageSize: cfg.PVZ.DefaultPageSize,
+		MaxPageSize:     cfg.PVZ.MaxPageSize,
+		MaxBatchSize:    cfg.PVZ.MaxBatchSize,
+		Policy:          policy,
+	})
+
+	if err := service.Revocations.Load(); err != nil {
+		logrus.Fatalf("Revocation list load error: %s", err.Error())
+	}
+	syncCtx, stopSync := context.WithCancel(context.Background())
+	go service.Revocations.Sync(syncCtx, cfg.JWT.RevocationSyncInterval)
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	go service.Idempotency.Purge(purgeCtx, cfg.Idempotency.PurgeInterval)
+
+	dispatchCtx, stopDispatch := context.WithCancel(context.Background())
+	dispatchDone := make(chan struct{})
+	go func() {
+		service.Outbox.Run(dispatchCtx, cfg.Outbox.PollInterval)
+		close(dispatchDone)
+	}()
+	deliveryCtx, stopDelivery := context.WithCancel(context.Background())
+	deliveryDone := make(chan struct{})
+	go func() {
+		service.Webhooks.Run(deliveryCtx, cfg.Webhooks.PollInterval)
+		close(deliveryDone)
+	}()
 
-	srv := new(httpserver.Server)
-	if err := srv.Start(os.Getenv("SERVER_ADDRESS"), handlers.InitRoutes()); err != nil {
-		logrus.Fatalf("Running error: %s", err.Error())
+	lifecycle := app.NewLifecycle(readiness, cfg.Shutdown.Delay, cfg.Shutdown.Timeout)
+	errCh := make(chan error, 3)
+
+	srv := httpserver.New(cfg.HTTP.Address, handlers.InitRoutes(), httpserver.Config{
+		ReadTimeout:    cfg.HTTP.ReadTimeout,
+		WriteTimeout:   cfg.HTTP.WriteTimeout,
+		MaxHeaderBytes: cfg.HTTP.MaxHeaderBytes,
+	})
+	go func() {
+		if err := srv.Start(); err != nil {
+			errCh <- fmt.Errorf("http: %w", err)
+		}
+	}()
+
+	grpcServer := grpc.NewServer()
+	pvz_v1.RegisterPVZServiceServer(grpcServer, grpchandler.NewPVZServer(service.Pvz))
+	go func() {
+		lis, err := net.Listen("tcp", cfg.GRPC.Address)
+		if err != nil {
+			errCh <- fmt.Errorf("gRPC listen: %w", err)
+			return
+		}
+		if err := grpcServer.Serve(lis); err != nil {
+			errCh <- fmt.Errorf("gRPC: %w", err)
+		}
+	}()
+
+	metricsSrv := httpserver.New(cfg.Metrics.Address, metrics.Handler(), httpserver.Config{})
+	go func() {
+		if err := metricsSrv.Start(); err != nil {
+			errCh <- fmt.Errorf("metrics: %w", err)
+		}
+	}()
+
+	lifecycle.OnShutdown("HTTP server", srv.Shutdown)
+	lifecycle.OnShutdown("gRPC server", func(ctx context.Context) error {
+		return gracefulStopGRPC(ctx, grpcServer)
+	})
+	lifecycle.OnShutdown("revocation sync", func(ctx context.Context) error {
+		stopSync()
+		return nil
+	})
+	lifecycle.OnShutdown("idempotency key purge", func(ctx context.Context) error {
+		stopPurge()
+		return nil
+	})
+	lifecycle.OnShutdown("outbox dispatcher", func(ctx context.Context) error {
+		stopDispatch()
+		select {
+		case <-dispatchDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	lifecycle.OnShutdown("webhook deliverer", func(ctx context.Context) error {
+		stopDelivery()
+		select {
+		case <-deliveryDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	lifecycle.OnShutdown("metrics server", metricsSrv.Shutdown)
+	lifecycle.OnShutdown("DB pool", func(ctx context.Context) error {
+		return db.Close()
+	})
+
+	readiness.SetReady(true)
+	logrus.Info("Server started")
+
+	if err := lifecycle.Wait(errCh); err != nil {
+		logrus.Fatalf("Shutdown error: %s", err.Error())
 	}
+	logrus.Info("Server stopped")
+}
 
+// eventSinks builds the outbox sinks named in the config, which Validate has
+// already checked.
+func eventSinks(cfg config.OutboxConfig, repos *repository.Repository) []service.EventSink {
+	var sinks []service.EventSink
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "log":
+			sinks = append(sinks, service.LogSink{})
+		case "webhook":
+			sinks = append(sinks, service.NewWebhookSink(cfg.WebhookURL, cfg.WebhookTimeout))
+		case "subscriptions":
+			sinks = append(sinks, service.NewSubscriptionSink(repos.WebhookRepository))
+		}
+	}
+	return sinks
+}
+
+// gracefulStopGRPC waits for in-flight RPCs to finish and forces the
+// remaining connections closed once ctx expires.
+func gracefulStopGRPC(ctx context.Context, server *grpc.Server) error {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		return ctx.Err()
+	}
 }
@@ -3,7 +3,6 @@ package app
 import (
 	"errors"
 	"log"
-	"os"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -19,13 +18,14 @@ const (
 	_defaultTimeout  = time.Second
 )
 
-func Migrations() {
-	databaseURL, ok := os.LookupEnv("POSTGRES_CONN")
-	if !ok || len(databaseURL) == 0 {
-		log.Fatalf("migrate: environment variable not declared: PG_URL")
+// Migrations applies pending migrations and returns the resulting schema
+// version, which readiness checks compare against the database.
+func Migrations(databaseURL, sslMode string, debug bool) uint {
+	if len(databaseURL) == 0 {
+		log.Fatalf("migrate: database url is empty")
 	}
 
-	databaseURL += "?sslmode=disable"
+	databaseURL += "?sslmode=" + sslMode
 
 	var (
 		attempts = _defaultAttempts
@@ -48,8 +48,7 @@ func Migrations() {
 		log.Fatalf("Migrate: postgres connect error: %s", err)
 	}
 
-	envMode, ok := os.LookupEnv("ENV")
-	if ok && envMode == `debug` {
+	if debug {
 		logrus.Debug(`debugging mode is enabled, so the table is cleared when you start`)
 		err = m.Down()
 		if err != nil && !errors.Is(err, migrate.ErrNoChange) {
@@ -64,8 +63,13 @@ func Migrations() {
 
 	if errors.Is(err, migrate.ErrNoChange) {
 		log.Printf("Migrate: no change")
-		return
+	} else {
+		log.Printf("Migrate: up success")
 	}
 
-	log.Printf("Migrate: up success")
+	version, _, err := m.Version()
+	if err != nil {
+		log.Fatalf("Migrate: version error: %s", err)
+	}
+	return version
 }
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Readiness reports whether the instance should receive new traffic. It is
+// flipped to not ready before the servers start draining.
+type Readiness struct {
+	ready atomic.Bool
+}
+
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+func (r *Readiness) SetReady(ready bool) {
+	r.ready.Store(ready)
+}
+
+func (r *Readiness) IsReady() bool {
+	return r.ready.Load()
+}
+
+type shutdownStep struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Lifecycle runs registered shutdown steps in registration order once the
+// process receives SIGINT or SIGTERM.
+type Lifecycle struct {
+	readiness *Readiness
+	delay     time.Duration
+	timeout   time.Duration
+	steps     []shutdownStep
+}
+
+// NewLifecycle creates a Lifecycle that waits delay after flipping readiness,
+// so load balancers stop routing to the instance, and then gives the shutdown
+// steps timeout to finish.
+func NewLifecycle(readiness *Readiness, delay, timeout time.Duration) *Lifecycle {
+	return &Lifecycle{readiness: readiness, delay: delay, timeout: timeout}
+}
+
+func (l *Lifecycle) OnShutdown(name string, fn func(ctx context.Context) error) {
+	l.steps = append(l.steps, shutdownStep{name: name, fn: fn})
+}
+
+// Wait blocks until a termination signal arrives or one of the servers
+// reports a fatal error on errCh, then shuts everything down.
+func (l *Lifecycle) Wait(errCh <-chan error) error {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	var runErr error
+	select {
+	case sig := <-quit:
+		logrus.Infof("received signal %s, shutting down", sig)
+	case runErr = <-errCh:
+		logrus.Errorf("server error: %s, shutting down", runErr.Error())
+	}
+
+	return errors.Join(runErr, l.Shutdown())
+}
+
+func (l *Lifecycle) Shutdown() error {
+	l.readiness.SetReady(false)
+	if l.delay > 0 {
+		time.Sleep(l.delay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+	defer cancel()
+
+	var errs []error
+	for _, step := range l.steps {
+		if err := step.fn(ctx); err != nil {
+			logrus.Errorf("%s shutdown error: %s", step.name, err.Error())
+			errs = append(errs, err)
+			continue
+		}
+		logrus.Infof("%s stopped", step.name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,78 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"pvz-test/internal/app"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycle_Shutdown(t *testing.T) {
+	t.Run("Steps run in order after readiness flips", func(t *testing.T) {
+		readiness := app.NewReadiness()
+		readiness.SetReady(true)
+		lifecycle := app.NewLifecycle(readiness, 0, time.Second)
+
+		var order []string
+		lifecycle.OnShutdown("http", func(ctx context.Context) error {
+			assert.False(t, readiness.IsReady())
+			order = append(order, "http")
+			return nil
+		})
+		lifecycle.OnShutdown("db", func(ctx context.Context) error {
+			order = append(order, "db")
+			return nil
+		})
+
+		assert.NoError(t, lifecycle.Shutdown())
+		assert.Equal(t, []string{"http", "db"}, order)
+	})
+
+	t.Run("Failed step does not stop the rest", func(t *testing.T) {
+		lifecycle := app.NewLifecycle(app.NewReadiness(), 0, time.Second)
+
+		stepErr := errors.New("drain failed")
+		dbClosed := false
+		lifecycle.OnShutdown("http", func(ctx context.Context) error {
+			return stepErr
+		})
+		lifecycle.OnShutdown("db", func(ctx context.Context) error {
+			dbClosed = true
+			return nil
+		})
+
+		assert.ErrorIs(t, lifecycle.Shutdown(), stepErr)
+		assert.True(t, dbClosed)
+	})
+
+	t.Run("Steps share the drain timeout", func(t *testing.T) {
+		lifecycle := app.NewLifecycle(app.NewReadiness(), 0, 10*time.Millisecond)
+
+		lifecycle.OnShutdown("http", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		assert.ErrorIs(t, lifecycle.Shutdown(), context.DeadlineExceeded)
+	})
+}
+
+func TestLifecycle_Wait_ServerError(t *testing.T) {
+	lifecycle := app.NewLifecycle(app.NewReadiness(), 0, time.Second)
+
+	stopped := false
+	lifecycle.OnShutdown("http", func(ctx context.Context) error {
+		stopped = true
+		return nil
+	})
+
+	errCh := make(chan error, 1)
+	serverErr := errors.New("address already in use")
+	errCh <- serverErr
+
+	assert.ErrorIs(t, lifecycle.Wait(errCh), serverErr)
+	assert.True(t, stopped)
+}
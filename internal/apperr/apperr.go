@@ -0,0 +1,80 @@
+// Package apperr defines error kinds shared by the repository, service and
+// transport layers. Errors of a kind match its sentinel with errors.Is, so
+// handlers can pick a status code without knowing the concrete error.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrForbidden    = errors.New("forbidden")
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrUnprocessable is a well-formed request that conflicts with an
+	// earlier one, such as a reused idempotency key.
+	ErrUnprocessable = errors.New("unprocessable")
+)
+
+// Error is an error of a given kind. Its message is meant to be shown to the
+// client.
+type Error struct {
+	kind    error
+	message string
+}
+
+// New returns an error of the given kind. It is used both for package level
+// sentinels and for one-off errors.
+func New(kind error, message string) *Error {
+	return &Error{kind: kind, message: message}
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.kind
+}
+
+func NotFound(format string, args ...interface{}) error {
+	return New(ErrNotFound, fmt.Sprintf(format, args...))
+}
+
+func Conflict(format string, args ...interface{}) error {
+	return New(ErrConflict, fmt.Sprintf(format, args...))
+}
+
+func Validation(format string, args ...interface{}) error {
+	return New(ErrValidation, fmt.Sprintf(format, args...))
+}
+
+func Forbidden(format string, args ...interface{}) error {
+	return New(ErrForbidden, fmt.Sprintf(format, args...))
+}
+
+func Unauthorized(format string, args ...interface{}) error {
+	return New(ErrUnauthorized, fmt.Sprintf(format, args...))
+}
+
+func Unprocessable(format string, args ...interface{}) error {
+	return New(ErrUnprocessable, fmt.Sprintf(format, args...))
+}
+
+// ValidationError reports a single invalid input field.
+type ValidationError struct {
+	Field  string
+	Value  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %s", e.Field, e.Value, e.Reason)
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
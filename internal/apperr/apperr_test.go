@@ -0,0 +1,40 @@
+package apperr_test
+
+import (
+	"errors"
+	"fmt"
+	"pvz-test/internal/apperr"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_Is(t *testing.T) {
+	errPvzNotFound := apperr.New(apperr.ErrNotFound, "pvz not found")
+	wrapped := fmt.Errorf("%w: 42", errPvzNotFound)
+
+	assert.ErrorIs(t, wrapped, errPvzNotFound)
+	assert.ErrorIs(t, wrapped, apperr.ErrNotFound)
+	assert.NotErrorIs(t, wrapped, apperr.ErrConflict)
+	assert.Equal(t, "pvz not found: 42", wrapped.Error())
+}
+
+func TestValidationError_Is(t *testing.T) {
+	err := fmt.Errorf("add item: %w", &apperr.ValidationError{Field: "type", Value: "furniture", Reason: "unknown type"})
+
+	assert.ErrorIs(t, err, apperr.ErrValidation)
+	var validationErr *apperr.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "type", validationErr.Field)
+	assert.Equal(t, `invalid type "furniture": unknown type`, validationErr.Error())
+}
+
+func TestConstructors(t *testing.T) {
+	assert.ErrorIs(t, apperr.NotFound("city %d", 1), apperr.ErrNotFound)
+	assert.ErrorIs(t, apperr.Conflict("busy"), apperr.ErrConflict)
+	assert.ErrorIs(t, apperr.Validation("bad"), apperr.ErrValidation)
+	assert.ErrorIs(t, apperr.Forbidden("no"), apperr.ErrForbidden)
+	assert.ErrorIs(t, apperr.Unauthorized("who"), apperr.ErrUnauthorized)
+	assert.ErrorIs(t, apperr.Unprocessable("reused"), apperr.ErrUnprocessable)
+	assert.Equal(t, "city 1", apperr.NotFound("city %d", 1).Error())
+}
@@ -0,0 +1,387 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Env         string            `yaml:"env" env:"ENV"`
+	HTTP        HTTPConfig        `yaml:"http"`
+	GRPC        GRPCConfig        `yaml:"grpc"`
+	Metrics     MetricsConfig     `yaml:"metrics"`
+	Postgres    PostgresConfig    `yaml:"postgres"`
+	JWT         JWTConfig         `yaml:"jwt"`
+	Password    PasswordConfig    `yaml:"password"`
+	Health      HealthConfig      `yaml:"health"`
+	Shutdown    ShutdownConfig    `yaml:"shutdown"`
+	PVZ         PVZConfig         `yaml:"pvz"`
+	RBAC        RBACConfig        `yaml:"rbac"`
+	Outbox      OutboxConfig      `yaml:"outbox"`
+	Webhooks    WebhooksConfig    `yaml:"webhooks"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+}
+
+type HTTPConfig struct {
+	Address        string        `yaml:"address" env:"SERVER_ADDRESS"`
+	ReadTimeout    time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout   time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	MaxHeaderBytes int           `yaml:"max_header_bytes" env:"HTTP_MAX_HEADER_BYTES"`
+}
+
+type GRPCConfig struct {
+	Address string `yaml:"address" env:"GRPC_ADDRESS"`
+}
+
+type MetricsConfig struct {
+	Address string `yaml:"address" env:"METRICS_ADDRESS"`
+}
+
+type PostgresConfig struct {
+	Host     string `yaml:"host" env:"POSTGRES_HOST"`
+	Port     string `yaml:"port" env:"POSTGRES_PORT"`
+	Username string `yaml:"username" env:"POSTGRES_USERNAME"`
+	Password string `yaml:"password" env:"POSTGRES_PASSWORD"`
+	Database string `yaml:"database" env:"POSTGRES_DATABASE"`
+	SSLMode  string `yaml:"sslmode" env:"POSTGRES_SSLMODE"`
+	// ConnURL is used by migrations. It is built from the fields above when empty.
+	ConnURL         string        `yaml:"conn_url" env:"POSTGRES_CONN"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"POSTGRES_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"POSTGRES_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"POSTGRES_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"POSTGRES_CONN_MAX_IDLE_TIME"`
+}
+
+type JWTConfig struct {
+	Keys                   string        `yaml:"keys" env:"JWT_KEYS"`
+	ActiveKID              string        `yaml:"active_kid" env:"JWT_ACTIVE_KID"`
+	TokenTTL               time.Duration `yaml:"token_ttl" env:"JWT_TOKEN_TTL"`
+	RefreshTTL             time.Duration `yaml:"refresh_ttl" env:"JWT_REFRESH_TTL"`
+	RevocationSyncInterval time.Duration `yaml:"revocation_sync_interval" env:"REVOCATION_SYNC_INTERVAL"`
+}
+
+type PasswordConfig struct {
+	Hasher string `yaml:"hasher" env:"PASSWORD_HASHER"`
+	Salt   string `yaml:"salt" env:"PASSWORD_SALT"`
+}
+
+type HealthConfig struct {
+	CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT"`
+	MaxPoolUsage float64       `yaml:"max_pool_usage" env:"HEALTH_MAX_POOL_USAGE"`
+}
+
+type ShutdownConfig struct {
+	Delay   time.Duration `yaml:"delay" env:"SHUTDOWN_DELAY"`
+	Timeout time.Duration `yaml:"timeout" env:"SHUTDOWN_TIMEOUT"`
+}
+
+type PVZConfig struct {
+	// CityCacheTTL bounds how long city catalog changes made by other instances stay unseen.
+	CityCacheTTL    time.Duration `yaml:"city_cache_ttl" env:"PVZ_CITY_CACHE_TTL"`
+	DefaultPageSize int           `yaml:"default_page_size" env:"PVZ_DEFAULT_PAGE_SIZE"`
+	MaxPageSize     int           `yaml:"max_page_size" env:"PVZ_MAX_PAGE_SIZE"`
+	MaxBatchSize    int           `yaml:"max_batch_size" env:"PVZ_MAX_BATCH_SIZE"`
+}
+
+type RBACConfig struct {
+	// Roles maps a role to its permissions. Entries replace the built-in
+	// permissions of that role or add a new role.
+	Roles map[string][]string `yaml:"roles"`
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL"`
+	BatchSize    int           `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE"`
+	Lease        time.Duration `yaml:"lease" env:"OUTBOX_LEASE"`
+	RetryBase    time.Duration `yaml:"retry_base" env:"OUTBOX_RETRY_BASE"`
+	RetryMax     time.Duration `yaml:"retry_max" env:"OUTBOX_RETRY_MAX"`
+	// Sinks lists where events are delivered: log, webhook, subscriptions.
+	Sinks          []string      `yaml:"sinks" env:"OUTBOX_SINKS"`
+	WebhookURL     string        `yaml:"webhook_url" env:"OUTBOX_WEBHOOK_URL"`
+	WebhookTimeout time.Duration `yaml:"webhook_timeout" env:"OUTBOX_WEBHOOK_TIMEOUT"`
+}
+
+type WebhooksConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval" env:"WEBHOOK_POLL_INTERVAL"`
+	BatchSize    int           `yaml:"batch_size" env:"WEBHOOK_BATCH_SIZE"`
+	Lease        time.Duration `yaml:"lease" env:"WEBHOOK_LEASE"`
+	Timeout      time.Duration `yaml:"timeout" env:"WEBHOOK_TIMEOUT"`
+	MaxAttempts  int           `yaml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+	RetryBase    time.Duration `yaml:"retry_base" env:"WEBHOOK_RETRY_BASE"`
+	RetryMax     time.Duration `yaml:"retry_max" env:"WEBHOOK_RETRY_MAX"`
+}
+
+type IdempotencyConfig struct {
+	// TTL is how long a response is replayed for retries with the same Idempotency-Key.
+	TTL           time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL"`
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"IDEMPOTENCY_PURGE_INTERVAL"`
+}
+
+func Default() Config {
+	return Config{
+		Env: "production",
+		HTTP: HTTPConfig{
+			Address:        "0.0.0.0:8080",
+			ReadTimeout:    10 * time.Second,
+			WriteTimeout:   10 * time.Second,
+			MaxHeaderBytes: 1 << 20,
+		},
+		GRPC:    GRPCConfig{Address: "0.0.0.0:3000"},
+		Metrics: MetricsConfig{Address: "0.0.0.0:9000"},
+		Postgres: PostgresConfig{
+			Port:            "5432",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 5 * time.Minute,
+			ConnMaxIdleTime: time.Minute,
+		},
+		JWT: JWTConfig{
+			TokenTTL:               15 * time.Minute,
+			RefreshTTL:             30 * 24 * time.Hour,
+			RevocationSyncInterval: 30 * time.Second,
+		},
+		Password: PasswordConfig{Hasher: "argon2id"},
+		Health:   HealthConfig{CheckTimeout: 2 * time.Second, MaxPoolUsage: 0.9},
+		Shutdown: ShutdownConfig{Delay: 5 * time.Second, Timeout: 15 * time.Second},
+		PVZ: PVZConfig{
+			CityCacheTTL:    time.Minute,
+			DefaultPageSize: 10,
+			MaxPageSize:     30,
+			MaxBatchSize:    100,
+		},
+		Outbox: OutboxConfig{
+			PollInterval:   time.Second,
+			BatchSize:      100,
+			Lease:          time.Minute,
+			RetryBase:      time.Second,
+			RetryMax:       10 * time.Minute,
+			Sinks:          []string{"log", "subscriptions"},
+			WebhookTimeout: 5 * time.Second,
+		},
+		Webhooks: WebhooksConfig{
+			PollInterval: time.Second,
+			BatchSize:    50,
+			Lease:        time.Minute,
+			Timeout:      5 * time.Second,
+			MaxAttempts:  10,
+			RetryBase:    5 * time.Second,
+			RetryMax:     time.Hour,
+		},
+		Idempotency: IdempotencyConfig{TTL: 24 * time.Hour, PurgeInterval: time.Hour},
+	}
+}
+
+// Load builds the configuration from defaults, an optional YAML file,
+// environment variables and command line flags, each overriding the previous
+// one. The YAML file is taken from the -config flag or CONFIG_FILE.
+func Load(args []string) (Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("pvz", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path to YAML config file")
+	env := fs.String("env", "", "environment name, debug recreates the schema on start")
+	httpAddress := fs.String("http-address", "", "HTTP listen address")
+	grpcAddress := fs.String("grpc-address", "", "gRPC listen address")
+	metricsAddress := fs.String("metrics-address", "", "metrics listen address")
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if *configFile != "" {
+		if err := loadYAML(*configFile, &cfg); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := loadEnv(reflect.ValueOf(&cfg).Elem()); err != nil {
+		return Config{}, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "env":
+			cfg.Env = *env
+		case "http-address":
+			cfg.HTTP.Address = *httpAddress
+		case "grpc-address":
+			cfg.GRPC.Address = *grpcAddress
+		case "metrics-address":
+			cfg.Metrics.Address = *metricsAddress
+		}
+	})
+
+	if cfg.Postgres.ConnURL == "" {
+		cfg.Postgres.ConnURL = cfg.Postgres.URL()
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func (c Config) Debug() bool {
+	return c.Env == "debug"
+}
+
+func (c PostgresConfig) URL() string {
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(c.Username, c.Password),
+		Host:   c.Host + ":" + c.Port,
+		Path:   c.Database,
+	}
+	return u.String()
+}
+
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.HTTP.Address != "", "http address is required")
+	check(c.HTTP.ReadTimeout > 0, "http read timeout must be positive")
+	check(c.HTTP.WriteTimeout > 0, "http write timeout must be positive")
+	check(c.HTTP.MaxHeaderBytes > 0, "http max header bytes must be positive")
+	check(c.GRPC.Address != "", "grpc address is required")
+	check(c.Metrics.Address != "", "metrics address is required")
+
+	check(c.Postgres.Host != "", "postgres host is required")
+	check(c.Postgres.Username != "", "postgres username is required")
+	check(c.Postgres.Database != "", "postgres database is required")
+	check(c.Postgres.MaxOpenConns >= 0, "postgres max open conns must not be negative")
+	check(c.Postgres.MaxOpenConns == 0 || c.Postgres.MaxIdleConns <= c.Postgres.MaxOpenConns,
+		"postgres max idle conns %d exceeds max open conns %d", c.Postgres.MaxIdleConns, c.Postgres.MaxOpenConns)
+
+	check(c.JWT.Keys != "", "jwt keys are required")
+	check(c.JWT.ActiveKID != "", "jwt active kid is required")
+	check(c.JWT.TokenTTL > 0, "jwt token ttl must be positive")
+	check(c.JWT.RefreshTTL > c.JWT.TokenTTL, "jwt refresh ttl must be longer than token ttl")
+	check(c.JWT.RevocationSyncInterval > 0, "revocation sync interval must be positive")
+
+	check(c.Health.CheckTimeout > 0, "health check timeout must be positive")
+	check(c.Health.MaxPoolUsage > 0 && c.Health.MaxPoolUsage <= 1, "health max pool usage must be in (0, 1]")
+	check(c.Shutdown.Delay >= 0, "shutdown delay must not be negative")
+	check(c.Shutdown.Timeout > 0, "shutdown timeout must be positive")
+
+	check(c.PVZ.CityCacheTTL > 0, "city cache ttl must be positive")
+	check(c.PVZ.DefaultPageSize > 0, "default page size must be positive")
+	check(c.PVZ.MaxPageSize >= c.PVZ.DefaultPageSize,
+		"max page size %d is less than default page size %d", c.PVZ.MaxPageSize, c.PVZ.DefaultPageSize)
+	check(c.PVZ.MaxBatchSize > 0, "max batch size must be positive")
+
+	check(c.Outbox.PollInterval > 0, "outbox poll interval must be positive")
+	check(c.Outbox.BatchSize > 0, "outbox batch size must be positive")
+	check(c.Outbox.Lease > 0, "outbox lease must be positive")
+	check(c.Outbox.RetryBase > 0, "outbox retry base must be positive")
+	check(c.Outbox.RetryMax >= c.Outbox.RetryBase, "outbox retry max must not be less than retry base")
+	for _, sink := range c.Outbox.Sinks {
+		switch sink {
+		case "log", "subscriptions":
+		case "webhook":
+			check(c.Outbox.WebhookURL != "", "outbox webhook url is required for the webhook sink")
+			check(c.Outbox.WebhookTimeout > 0, "outbox webhook timeout must be positive")
+		default:
+			check(false, "unknown outbox sink %q", sink)
+		}
+	}
+
+	check(c.Webhooks.PollInterval > 0, "webhook poll interval must be positive")
+	check(c.Webhooks.BatchSize > 0, "webhook batch size must be positive")
+	check(c.Webhooks.Timeout > 0, "webhook timeout must be positive")
+	check(c.Webhooks.Lease > c.Webhooks.Timeout, "webhook lease must be longer than webhook timeout")
+	check(c.Webhooks.MaxAttempts > 0, "webhook max attempts must be positive")
+	check(c.Webhooks.RetryBase > 0, "webhook retry base must be positive")
+	check(c.Webhooks.RetryMax >= c.Webhooks.RetryBase, "webhook retry max must not be less than retry base")
+	check(c.Idempotency.TTL > 0, "idempotency ttl must be positive")
+	check(c.Idempotency.PurgeInterval > 0, "idempotency purge interval must be positive")
+
+	return errors.Join(errs...)
+}
+
+func loadYAML(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv overrides fields that have an env tag with the variables that are set.
+func loadEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			if err := loadEnv(value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := field.Tag.Get("env")
+		if name == "" {
+			continue
+		}
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setValue(value, strings.TrimSpace(raw)); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func setValue(v reflect.Value, raw string) error {
+	switch v.Interface().(type) {
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case string:
+		v.SetString(raw)
+	case int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case []string:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported config type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"pvz-test/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setRequiredEnv(t *testing.T) {
+	t.Setenv("POSTGRES_HOST", "localhost")
+	t.Setenv("POSTGRES_USERNAME", "postgres")
+	t.Setenv("POSTGRES_DATABASE", "pvz_db")
+	t.Setenv("JWT_KEYS", "default:HS256:secret")
+	t.Setenv("JWT_ACTIVE_KID", "default")
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	setRequiredEnv(t)
+
+	cfg, err := config.Load(nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "0.0.0.0:8080", cfg.HTTP.Address)
+	assert.Equal(t, 10*time.Second, cfg.HTTP.ReadTimeout)
+	assert.Equal(t, 10, cfg.PVZ.DefaultPageSize)
+	assert.Equal(t, 30, cfg.PVZ.MaxPageSize)
+	assert.Equal(t, time.Minute, cfg.PVZ.CityCacheTTL)
+	assert.Equal(t, "postgres://postgres:@localhost:5432/pvz_db", cfg.Postgres.ConnURL)
+	assert.Equal(t, []string{"log", "subscriptions"}, cfg.Outbox.Sinks)
+	assert.Equal(t, 10, cfg.Webhooks.MaxAttempts)
+	assert.Equal(t, 24*time.Hour, cfg.Idempotency.TTL)
+	assert.False(t, cfg.Debug())
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	setRequiredEnv(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+http:
+  address: 0.0.0.0:8081
+  read_timeout: 3s
+grpc:
+  address: 0.0.0.0:3001
+postgres:
+  max_open_conns: 50
+  max_idle_conns: 10
+pvz:
+  city_cache_ttl: 10s
+rbac:
+  roles:
+    auditor: [pvz:read, reception:read]
+`), 0o600))
+
+	t.Setenv("GRPC_ADDRESS", "0.0.0.0:3002")
+	t.Setenv("PVZ_MAX_PAGE_SIZE", "50")
+
+	cfg, err := config.Load([]string{"-config", path, "-http-address", "0.0.0.0:8082", "-env", "debug"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "0.0.0.0:8082", cfg.HTTP.Address, "flag overrides file")
+	assert.Equal(t, 3*time.Second, cfg.HTTP.ReadTimeout, "file overrides default")
+	assert.Equal(t, "0.0.0.0:3002", cfg.GRPC.Address, "env overrides file")
+	assert.Equal(t, 50, cfg.Postgres.MaxOpenConns)
+	assert.Equal(t, 50, cfg.PVZ.MaxPageSize)
+	assert.Equal(t, 10*time.Second, cfg.PVZ.CityCacheTTL)
+	assert.Equal(t, []string{"pvz:read", "reception:read"}, cfg.RBAC.Roles["auditor"])
+	assert.True(t, cfg.Debug())
+}
+
+func TestLoad_Bad(t *testing.T) {
+	t.Run("Missing required settings", func(t *testing.T) {
+		t.Setenv("POSTGRES_HOST", "")
+		t.Setenv("JWT_KEYS", "")
+
+		_, err := config.Load(nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "postgres host is required")
+		assert.Contains(t, err.Error(), "jwt keys are required")
+	})
+
+	t.Run("Malformed env value", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("JWT_TOKEN_TTL", "fifteen minutes")
+
+		_, err := config.Load(nil)
+		assert.ErrorContains(t, err, "invalid JWT_TOKEN_TTL")
+	})
+
+	t.Run("Page size limits", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("PVZ_DEFAULT_PAGE_SIZE", "40")
+
+		_, err := config.Load(nil)
+		assert.ErrorContains(t, err, "max page size 30 is less than default page size 40")
+	})
+
+	t.Run("Webhook sink without url", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("OUTBOX_SINKS", "log,webhook,kafka")
+
+		_, err := config.Load(nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "outbox webhook url is required for the webhook sink")
+		assert.Contains(t, err.Error(), `unknown outbox sink "kafka"`)
+	})
+
+	t.Run("Missing config file", func(t *testing.T) {
+		setRequiredEnv(t)
+
+		_, err := config.Load([]string{"-config", filepath.Join(t.TempDir(), "missing.yaml")})
+		assert.ErrorContains(t, err, "read config file")
+	})
+}
@@ -0,0 +1,69 @@
+package grpchandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pvz-test/internal/models"
+	"pvz-test/internal/service"
+	"pvz-test/pkg/pvz_v1"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type PVZServer struct {
+	pvz_v1.UnimplementedPVZServiceServer
+	services service.Pvz
+}
+
+func NewPVZServer(services service.Pvz) *PVZServer {
+	return &PVZServer{services: services}
+}
+
+func (s *PVZServer) GetPVZList(ctx context.Context, req *pvz_v1.GetPVZListRequest) (*pvz_v1.GetPVZListResponse, error) {
+	pvzs, err := s.services.GetPVZList()
+	if err != nil {
+		logrus.Errorf("failed to fetch pvz list: %s", err.Error())
+		return nil, status.Error(codes.Internal, "failed to fetch pvz list")
+	}
+
+	resp := &pvz_v1.GetPVZListResponse{Pvzs: make([]*pvz_v1.PVZ, 0, len(pvzs))}
+	for _, pvz := range pvzs {
+		resp.Pvzs = append(resp.Pvzs, toProtoPVZ(pvz))
+	}
+	return resp, nil
+}
+
+func (s *PVZServer) GetPVZ(ctx context.Context, req *pvz_v1.GetPVZRequest) (*pvz_v1.GetPVZResponse, error) {
+	pvzID, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid pvz id")
+	}
+
+	pvz, err := s.services.GetPVZByID(pvzID)
+	if err != nil {
+		if errors.Is(err, service.ErrPvzNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		logrus.Errorf("failed to fetch pvz %s: %s", pvzID, err.Error())
+		return nil, status.Error(codes.Internal, "failed to fetch pvz")
+	}
+	// The API has no status field, so a deactivated PVZ is hidden as in the list.
+	if !pvz.Active() {
+		return nil, status.Error(codes.NotFound, fmt.Sprintf("%s: %s", service.ErrPvzNotFound, pvzID))
+	}
+
+	return &pvz_v1.GetPVZResponse{Pvz: toProtoPVZ(pvz)}, nil
+}
+
+func toProtoPVZ(pvz models.PVZ) *pvz_v1.PVZ {
+	return &pvz_v1.PVZ{
+		Id:               pvz.ID.String(),
+		RegistrationDate: timestamppb.New(pvz.RegistrationDate),
+		City:             pvz.City,
+	}
+}
@@ -0,0 +1,150 @@
+package grpchandler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"pvz-test/internal/grpchandler"
+	"pvz-test/internal/models"
+	"pvz-test/internal/service"
+	"pvz-test/pkg/pvz_v1"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type MockPvzService struct {
+	mock.Mock
+}
+
+func (m *MockPvzService) CreatePvz(actor models.Actor, city string) (models.PVZ, error) {
+	args := m.Called(actor, city)
+	return args.Get(0).(models.PVZ), args.Error(1)
+}
+
+func (m *MockPvzService) GetFilteredPVZ(start, end *time.Time, includeEmpty bool, status models.PVZStatus, limit, offset int) ([]models.PVZResponse, error) {
+	args := m.Called(start, end, includeEmpty, status, limit, offset)
+	return args.Get(0).([]models.PVZResponse), args.Error(1)
+}
+
+func (m *MockPvzService) GetPVZList() ([]models.PVZ, error) {
+	args := m.Called()
+	return args.Get(0).([]models.PVZ), args.Error(1)
+}
+
+func (m *MockPvzService) GetPVZByID(pvzID uuid.UUID) (models.PVZ, error) {
+	args := m.Called(pvzID)
+	return args.Get(0).(models.PVZ), args.Error(1)
+}
+
+func (m *MockPvzService) UpdatePVZ(actor models.Actor, pvzID uuid.UUID, city string) (models.PVZ, error) {
+	args := m.Called(actor, pvzID, city)
+	return args.Get(0).(models.PVZ), args.Error(1)
+}
+
+func (m *MockPvzService) DeactivatePVZ(actor models.Actor, pvzID uuid.UUID) (models.PVZ, error) {
+	args := m.Called(actor, pvzID)
+	return args.Get(0).(models.PVZ), args.Error(1)
+}
+
+func newTestClient(t *testing.T, svc service.Pvz) pvz_v1.PVZServiceClient {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	pvz_v1.RegisterPVZServiceServer(srv, grpchandler.NewPVZServer(svc))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pvz_v1.NewPVZServiceClient(conn)
+}
+
+func TestPVZServer_GetPVZList(t *testing.T) {
+	mockService := new(MockPvzService)
+	client := newTestClient(t, mockService)
+
+	t.Run("Successful fetch", func(t *testing.T) {
+		expected := []models.PVZ{
+			{ID: uuid.New(), RegistrationDate: time.Now().UTC(), City: "Москва"},
+			{ID: uuid.New(), RegistrationDate: time.Now().UTC(), City: "Казань"},
+		}
+		mockService.On("GetPVZList").Return(expected, nil).Once()
+
+		resp, err := client.GetPVZList(context.Background(), &pvz_v1.GetPVZListRequest{})
+		require.NoError(t, err)
+		require.Len(t, resp.GetPvzs(), 2)
+		for i, pvz := range resp.GetPvzs() {
+			assert.Equal(t, expected[i].ID.String(), pvz.GetId())
+			assert.Equal(t, expected[i].City, pvz.GetCity())
+			assert.True(t, expected[i].RegistrationDate.Equal(pvz.GetRegistrationDate().AsTime()))
+		}
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		mockService.On("GetPVZList").Return([]models.PVZ{}, errors.New("database error")).Once()
+
+		_, err := client.GetPVZList(context.Background(), &pvz_v1.GetPVZListRequest{})
+		assert.Equal(t, codes.Internal, status.Code(err))
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestPVZServer_GetPVZ(t *testing.T) {
+	mockService := new(MockPvzService)
+	client := newTestClient(t, mockService)
+
+	t.Run("PVZ found", func(t *testing.T) {
+		expected := models.PVZ{ID: uuid.New(), RegistrationDate: time.Now().UTC(), City: "Санкт-Петербург"}
+		mockService.On("GetPVZByID", expected.ID).Return(expected, nil).Once()
+
+		resp, err := client.GetPVZ(context.Background(), &pvz_v1.GetPVZRequest{Id: expected.ID.String()})
+		require.NoError(t, err)
+		assert.Equal(t, expected.ID.String(), resp.GetPvz().GetId())
+		assert.Equal(t, expected.City, resp.GetPvz().GetCity())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("PVZ not found", func(t *testing.T) {
+		pvzID := uuid.New()
+		mockService.On("GetPVZByID", pvzID).
+			Return(models.PVZ{}, fmt.Errorf("%w: %s", service.ErrPvzNotFound, pvzID)).Once()
+
+		_, err := client.GetPVZ(context.Background(), &pvz_v1.GetPVZRequest{Id: pvzID.String()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("PVZ deactivated", func(t *testing.T) {
+		deactivatedAt := time.Now().UTC()
+		pvz := models.PVZ{ID: uuid.New(), RegistrationDate: time.Now().UTC(), City: "Москва", DeactivatedAt: &deactivatedAt}
+		mockService.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+
+		resp, err := client.GetPVZ(context.Background(), &pvz_v1.GetPVZRequest{Id: pvz.ID.String()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Nil(t, resp)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		_, err := client.GetPVZ(context.Background(), &pvz_v1.GetPVZRequest{Id: "badPvzId"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+	"pvz-test/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const userIdParam = "userId"
+
+func (h *Handler) GetPVZEmployees(c *gin.Context) {
+	pvzID, err := uuid.Parse(c.Param(pvzIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", pvzIdParam))
+		return
+	}
+
+	employees, err := h.services.Assignment.GetPVZEmployees(pvzID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, employees)
+}
+
+func (h *Handler) AssignEmployee(c *gin.Context) {
+	pvzID, userID, ok := assignmentParams(c)
+	if !ok {
+		return
+	}
+
+	assignment, err := h.services.Assignment.AssignEmployee(pvzID, userID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+func (h *Handler) UnassignEmployee(c *gin.Context) {
+	pvzID, userID, ok := assignmentParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.Assignment.UnassignEmployee(pvzID, userID); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func assignmentParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	pvzID, err := uuid.Parse(c.Param(pvzIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", pvzIdParam))
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, err := uuid.Parse(c.Param(userIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", userIdParam))
+		return uuid.Nil, uuid.Nil, false
+	}
+	return pvzID, userID, true
+}
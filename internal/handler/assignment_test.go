@@ -0,0 +1,143 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"pvz-test/internal/handler"
+	"pvz-test/internal/models"
+	"pvz-test/internal/rbac"
+	"pvz-test/internal/service"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAssignmentService struct {
+	mock.Mock
+}
+
+func (m *MockAssignmentService) AssignEmployee(pvzID, userID uuid.UUID) (models.EmployeeAssignment, error) {
+	args := m.Called(pvzID, userID)
+	return args.Get(0).(models.EmployeeAssignment), args.Error(1)
+}
+
+func (m *MockAssignmentService) UnassignEmployee(pvzID, userID uuid.UUID) error {
+	args := m.Called(pvzID, userID)
+	return args.Error(0)
+}
+
+func (m *MockAssignmentService) GetPVZEmployees(pvzID uuid.UUID) ([]models.EmployeeAssignment, error) {
+	args := m.Called(pvzID)
+	return args.Get(0).([]models.EmployeeAssignment), args.Error(1)
+}
+
+func TestHandler_AssignEmployee(t *testing.T) {
+	mockService := new(MockAssignmentService)
+	h := handler.NewHandler(&service.Service{Assignment: mockService}, handler.Config{})
+	routes := func(r gin.IRoutes) {
+		r.PUT("/pvz/:pvzId/employees/:userId", h.AssignEmployee)
+	}
+
+	t.Run("Assigned", func(t *testing.T) {
+		expected := models.EmployeeAssignment{UserID: uuid.New(), PVZID: uuid.New(), AssignedAt: time.Now().UTC()}
+		mockService.On("AssignEmployee", expected.PVZID, expected.UserID).Return(expected, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodPut, "/pvz/"+expected.PVZID.String()+"/employees/"+expected.UserID.String(), nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.StaffManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.EmployeeAssignment
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, expected.UserID, response.UserID)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Not an employee", func(t *testing.T) {
+		pvzID, userID := uuid.New(), uuid.New()
+		mockService.On("AssignEmployee", pvzID, userID).
+			Return(models.EmployeeAssignment{}, fmt.Errorf("%w: %s", service.ErrNotEmployee, userID)).Once()
+
+		req, _ := http.NewRequest(http.MethodPut, "/pvz/"+pvzID.String()+"/employees/"+userID.String(), nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.StaffManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Invalid user id", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPut, "/pvz/"+uuid.New().String()+"/employees/bad", nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.StaffManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"message":"userId parse error"}`, w.Body.String())
+	})
+
+	t.Run("Employee forbidden", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPut, "/pvz/"+uuid.New().String()+"/employees/"+uuid.New().String(), nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, employee, rbac.StaffManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestHandler_UnassignEmployee(t *testing.T) {
+	mockService := new(MockAssignmentService)
+	h := handler.NewHandler(&service.Service{Assignment: mockService}, handler.Config{})
+	routes := func(r gin.IRoutes) {
+		r.DELETE("/pvz/:pvzId/employees/:userId", h.UnassignEmployee)
+	}
+
+	t.Run("Unassigned", func(t *testing.T) {
+		pvzID, userID := uuid.New(), uuid.New()
+		mockService.On("UnassignEmployee", pvzID, userID).Return(nil).Once()
+
+		req, _ := http.NewRequest(http.MethodDelete, "/pvz/"+pvzID.String()+"/employees/"+userID.String(), nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.StaffManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("Not assigned", func(t *testing.T) {
+		pvzID, userID := uuid.New(), uuid.New()
+		mockService.On("UnassignEmployee", pvzID, userID).
+			Return(fmt.Errorf("%w: user %s, pvz %s", service.ErrAssignmentNotFound, userID, pvzID)).Once()
+
+		req, _ := http.NewRequest(http.MethodDelete, "/pvz/"+pvzID.String()+"/employees/"+userID.String(), nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.StaffManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestHandler_GetPVZEmployees(t *testing.T) {
+	mockService := new(MockAssignmentService)
+	h := handler.NewHandler(&service.Service{Assignment: mockService}, handler.Config{})
+	routes := func(r gin.IRoutes) {
+		r.GET("/pvz/:pvzId/employees", h.GetPVZEmployees)
+	}
+
+	pvzID := uuid.New()
+	employees := []models.EmployeeAssignment{{UserID: uuid.New(), PVZID: pvzID}}
+	mockService.On("GetPVZEmployees", pvzID).Return(employees, nil).Once()
+
+	req, _ := http.NewRequest(http.MethodGet, "/pvz/"+pvzID.String()+"/employees", nil)
+	w := httptest.NewRecorder()
+	newTestRouter(h, moderator, rbac.StaffManage, routes).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []models.EmployeeAssignment
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response, 1)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) GetAuditEvents(c *gin.Context) {
+	var q models.GetAuditQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		abortWithError(c, apperr.Validation("invalid query parameters"))
+		return
+	}
+	filter := models.AuditFilter{
+		Action: q.Action,
+		Start:  q.StartDate,
+		End:    q.EndDate,
+	}
+	for _, id := range []struct {
+		name  string
+		value string
+		dest  **uuid.UUID
+	}{
+		{"actorId", q.ActorID, &filter.ActorID},
+		{"pvzId", q.PVZID, &filter.PVZID},
+		{"entityId", q.EntityID, &filter.EntityID},
+	} {
+		if id.value == "" {
+			continue
+		}
+		parsed, err := uuid.Parse(id.value)
+		if err != nil {
+			abortWithError(c, apperr.Validation("%s parse error", id.name))
+			return
+		}
+		*id.dest = &parsed
+	}
+
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 1 || q.Limit > h.cfg.MaxPageSize {
+		q.Limit = h.cfg.DefaultPageSize
+	}
+
+	filter.Limit = q.Limit
+	filter.Offset = (q.Page - 1) * q.Limit
+
+	events, err := h.services.Audit.GetAuditEvents(filter)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
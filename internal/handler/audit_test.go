@@ -0,0 +1,71 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"pvz-test/internal/handler"
+	"pvz-test/internal/models"
+	"pvz-test/internal/rbac"
+	"pvz-test/internal/service"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) GetAuditEvents(filter models.AuditFilter) ([]models.AuditEvent, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]models.AuditEvent), args.Error(1)
+}
+
+func TestHandler_GetAuditEvents(t *testing.T) {
+	mockService := new(MockAuditService)
+	h := handler.NewHandler(&service.Service{Audit: mockService}, handler.Config{DefaultPageSize: 10, MaxPageSize: 30})
+	routes := func(r gin.IRoutes) {
+		r.GET("/audit", h.GetAuditEvents)
+	}
+
+	t.Run("Filters and pagination", func(t *testing.T) {
+		actorID, pvzID := uuid.New(), uuid.New()
+		filter := models.AuditFilter{
+			ActorID: &actorID,
+			PVZID:   &pvzID,
+			Action:  models.AuditProductDeleted,
+			Limit:   5,
+			Offset:  5,
+		}
+		events := []models.AuditEvent{{ID: uuid.New(), ActorID: actorID, PVZID: pvzID, Action: models.AuditProductDeleted}}
+		mockService.On("GetAuditEvents", filter).Return(events, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodGet,
+			"/audit?actorId="+actorID.String()+"&pvzId="+pvzID.String()+"&action=product.deleted&page=2&limit=5", nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.AuditRead, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid actor id", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/audit?actorId=nope", nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.AuditRead, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"message":"actorId parse error"}`, w.Body.String())
+	})
+
+	t.Run("Employee forbidden", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/audit", nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, employee, rbac.AuditRead, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
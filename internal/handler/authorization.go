@@ -2,7 +2,9 @@ package handler
 
 import (
 	"net/http"
+	"pvz-test/internal/apperr"
 	"pvz-test/internal/models"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -11,59 +13,61 @@ import (
 func (h *Handler) Login(c *gin.Context) {
 	var input models.LoginRequest
 
-	if err := c.BindJSON(&input); err != nil {
-		newErrorResponse(c, http.StatusUnauthorized, `binding error`)
+	if err := c.ShouldBindJSON(&input); err != nil {
+		abortWithError(c, apperr.Validation("binding error"))
 		return
 	}
 
-	token, err := h.services.Authorization.Login(input)
+	tokens, err := h.services.Authorization.Login(input)
 	if err != nil {
 		logrus.Info(err)
-		newErrorResponse(c, http.StatusUnauthorized, `Unauthorized`)
+		abortWithError(c, apperr.Unauthorized("Unauthorized"))
 		return
 	}
 
 	logrus.Info("user logged: ", input.Email)
-	c.JSON(http.StatusOK, token)
+	setRefreshCookie(c, tokens)
+	c.JSON(http.StatusOK, tokens.AccessToken)
 }
 
 func (h *Handler) DummyLogin(c *gin.Context) {
 	var req models.DummyLoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, `binding error`)
+		abortWithError(c, apperr.Validation("binding error"))
 		return
 	}
 
-	if err := h.validate.Struct(&req); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, `role validate error`)
+	if err := h.validate.Struct(&req); err != nil || !h.cfg.Policy.HasRole(req.Role) {
+		abortWithError(c, apperr.Validation("role validate error"))
 		return
 	}
 
-	token, err := h.services.DummyLogin(req.Role)
+	tokens, err := h.services.DummyLogin(req.Role)
 	if err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		abortWithError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, token)
+	setRefreshCookie(c, tokens)
+	c.JSON(http.StatusOK, tokens.AccessToken)
 }
 
 func (h *Handler) Register(c *gin.Context) {
 	var input models.RegisterRequest
 
-	if err := c.BindJSON(&input); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	if err := c.ShouldBindJSON(&input); err != nil {
+		abortWithError(c, apperr.Validation("binding error"))
 		return
 	}
 
 	if err := h.validate.Struct(&input); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		abortWithError(c, apperr.Validation("%s", err.Error()))
 		return
 	}
 
 	user, err := h.services.Authorization.Register(input)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		abortWithError(c, err)
 		return
 	}
 
@@ -74,3 +78,60 @@ func (h *Handler) Register(c *gin.Context) {
 		Role:  user.Role,
 	})
 }
+
+func (h *Handler) RefreshToken(c *gin.Context) {
+	refreshToken := refreshTokenFromRequest(c)
+	if refreshToken == "" {
+		abortWithError(c, apperr.Unauthorized("refresh token is required"))
+		return
+	}
+
+	tokens, err := h.services.Authorization.Refresh(refreshToken)
+	if err != nil {
+		logrus.Info(err)
+		clearRefreshCookie(c)
+		abortWithError(c, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+
+	setRefreshCookie(c, tokens)
+	c.JSON(http.StatusOK, tokens.AccessToken)
+}
+
+func (h *Handler) Logout(c *gin.Context) {
+	accessToken := c.GetString(accessTokenCtx)
+
+	if err := h.services.Authorization.Logout(accessToken, refreshTokenFromRequest(c)); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	clearRefreshCookie(c)
+	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
+
+func refreshTokenFromRequest(c *gin.Context) string {
+	var req models.RefreshTokenRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err == nil && req.RefreshToken != "" {
+			return req.RefreshToken
+		}
+	}
+
+	cookie, err := c.Cookie(refreshTokenCookie)
+	if err != nil {
+		return ""
+	}
+	return cookie
+}
+
+func setRefreshCookie(c *gin.Context, tokens models.TokenPair) {
+	maxAge := int(time.Until(tokens.RefreshExpiresAt).Seconds())
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(refreshTokenCookie, tokens.RefreshToken, maxAge, refreshTokenPath, "", c.Request.TLS != nil, true)
+}
+
+func clearRefreshCookie(c *gin.Context) {
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(refreshTokenCookie, "", -1, refreshTokenPath, "", c.Request.TLS != nil, true)
+}
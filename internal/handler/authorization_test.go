@@ -8,8 +8,11 @@ import (
 	"net/http/httptest"
 	"pvz-test/internal/handler"
 	"pvz-test/internal/models"
+	"pvz-test/internal/rbac"
+	"pvz-test/internal/repository"
 	"pvz-test/internal/service"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -21,9 +24,9 @@ type MockAuthorizationService struct {
 	mock.Mock
 }
 
-func (m *MockAuthorizationService) Login(input models.LoginRequest) (string, error) {
+func (m *MockAuthorizationService) Login(input models.LoginRequest) (models.TokenPair, error) {
 	args := m.Called(input)
-	return args.String(0), args.Error(1)
+	return args.Get(0).(models.TokenPair), args.Error(1)
 }
 
 func (m *MockAuthorizationService) Register(input models.RegisterRequest) (models.UserResponse, error) {
@@ -31,9 +34,19 @@ func (m *MockAuthorizationService) Register(input models.RegisterRequest) (model
 	return args.Get(0).(models.UserResponse), args.Error(1)
 }
 
-func (m *MockAuthorizationService) DummyLogin(role models.Role) (string, error) {
+func (m *MockAuthorizationService) DummyLogin(role models.Role) (models.TokenPair, error) {
 	args := m.Called(role)
-	return args.String(0), args.Error(1)
+	return args.Get(0).(models.TokenPair), args.Error(1)
+}
+
+func (m *MockAuthorizationService) Refresh(refreshToken string) (models.TokenPair, error) {
+	args := m.Called(refreshToken)
+	return args.Get(0).(models.TokenPair), args.Error(1)
+}
+
+func (m *MockAuthorizationService) Logout(accessToken, refreshToken string) error {
+	args := m.Called(accessToken, refreshToken)
+	return args.Error(0)
 }
 
 func (m *MockAuthorizationService) ParseToken(token string) (uuid.UUID, models.Role, error) {
@@ -43,17 +56,18 @@ func (m *MockAuthorizationService) ParseToken(token string) (uuid.UUID, models.R
 
 func TestHandler_DummyLogin(t *testing.T) {
 	mockService := new(MockAuthorizationService)
-	h := handler.NewHandler(&service.Service{Authorization: mockService})
+	h := handler.NewHandler(&service.Service{Authorization: mockService}, handler.Config{})
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
 	router.POST("/dummy-login", h.DummyLogin)
 
 	t.Run("Successful dummy login", func(t *testing.T) {
 		role := models.RoleModerator
 		token := "mockToken"
 
-		mockService.On("DummyLogin", role).Return(token, nil)
+		mockService.On("DummyLogin", role).Return(models.TokenPair{AccessToken: token, RefreshToken: "mockRefresh", RefreshExpiresAt: time.Now().Add(time.Hour)}, nil)
 
 		body, _ := json.Marshal(models.DummyLoginRequest{Role: role})
 		req, _ := http.NewRequest(http.MethodPost, "/dummy-login", bytes.NewBuffer(body))
@@ -64,6 +78,8 @@ func TestHandler_DummyLogin(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.JSONEq(t, `"mockToken"`, w.Body.String())
+		assert.Contains(t, w.Header().Get("Set-Cookie"), "refresh_token=mockRefresh")
+		assert.Contains(t, w.Header().Get("Set-Cookie"), "HttpOnly")
 		mockService.AssertExpectations(t)
 	})
 
@@ -90,10 +106,21 @@ func TestHandler_DummyLogin(t *testing.T) {
 		assert.JSONEq(t, `{"message":"role validate error"}`, w.Body.String())
 	})
 
+	t.Run("Unknown role", func(t *testing.T) {
+		body, _ := json.Marshal(models.DummyLoginRequest{Role: "auditor"})
+		req, _ := http.NewRequest(http.MethodPost, "/dummy-login", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
 	t.Run("Service error", func(t *testing.T) {
 		role := models.RoleEmployee
 
-		mockService.On("DummyLogin", role).Return("", errors.New("service error"))
+		mockService.On("DummyLogin", role).Return(models.TokenPair{}, errors.New("service error"))
 
 		body, _ := json.Marshal(models.DummyLoginRequest{Role: role})
 		req, _ := http.NewRequest(http.MethodPost, "/dummy-login", bytes.NewBuffer(body))
@@ -102,17 +129,18 @@ func TestHandler_DummyLogin(t *testing.T) {
 
 		router.ServeHTTP(w, req)
 
-		assert.Equal(t, http.StatusBadRequest, w.Code)
-		assert.JSONEq(t, `{"message":"service error"}`, w.Body.String())
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.JSONEq(t, `{"message":"Internal Server Error"}`, w.Body.String())
 		mockService.AssertExpectations(t)
 	})
 }
 func TestHandler_Register_Bad(t *testing.T) {
 	mockService := new(MockAuthorizationService)
-	h := handler.NewHandler(&service.Service{Authorization: mockService})
+	h := handler.NewHandler(&service.Service{Authorization: mockService}, handler.Config{})
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
 	router.POST("/register", h.Register)
 
 	t.Run("Binding error", func(t *testing.T) {
@@ -159,17 +187,38 @@ func TestHandler_Register_Bad(t *testing.T) {
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
-		assert.JSONEq(t, `{"message":"service error"}`, w.Body.String())
+		assert.JSONEq(t, `{"message":"Internal Server Error"}`, w.Body.String())
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("User exists", func(t *testing.T) {
+		input := models.RegisterRequest{
+			Email:    "taken@example.com",
+			Password: "password123",
+			Role:     string(models.RoleEmployee),
+		}
+
+		mockService.On("Register", input).Return(models.UserResponse{}, repository.ErrUserExists)
+
+		body, _ := json.Marshal(input)
+		req, _ := http.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.JSONEq(t, `{"message":"user already exists"}`, w.Body.String())
+	})
 }
 
 func TestHandler_Register_Good(t *testing.T) {
 	mockService := new(MockAuthorizationService)
-	h := handler.NewHandler(&service.Service{Authorization: mockService})
+	h := handler.NewHandler(&service.Service{Authorization: mockService}, handler.Config{})
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
 	router.POST("/register", h.Register)
 
 	t.Run("Successful registration", func(t *testing.T) {
@@ -202,10 +251,11 @@ func TestHandler_Register_Good(t *testing.T) {
 
 func TestHandler_Login_Good(t *testing.T) {
 	mockService := new(MockAuthorizationService)
-	h := handler.NewHandler(&service.Service{Authorization: mockService})
+	h := handler.NewHandler(&service.Service{Authorization: mockService}, handler.Config{})
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
 	router.POST("/login", h.Login)
 
 	t.Run("Successful login", func(t *testing.T) {
@@ -215,7 +265,7 @@ func TestHandler_Login_Good(t *testing.T) {
 		}
 		token := "mockToken"
 
-		mockService.On("Login", input).Return(token, nil)
+		mockService.On("Login", input).Return(models.TokenPair{AccessToken: token}, nil)
 
 		body, _ := json.Marshal(input)
 		req, _ := http.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(body))
@@ -232,10 +282,11 @@ func TestHandler_Login_Good(t *testing.T) {
 }
 func TestHandler_Login_Bad(t *testing.T) {
 	mockService := new(MockAuthorizationService)
-	h := handler.NewHandler(&service.Service{Authorization: mockService})
+	h := handler.NewHandler(&service.Service{Authorization: mockService}, handler.Config{})
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
 	router.POST("/login", h.Login)
 	t.Run("Service error", func(t *testing.T) {
 		input := models.LoginRequest{
@@ -243,7 +294,7 @@ func TestHandler_Login_Bad(t *testing.T) {
 			Password: "password123",
 		}
 
-		mockService.On("Login", input).Return("", errors.New("unauthorized"))
+		mockService.On("Login", input).Return(models.TokenPair{}, errors.New("unauthorized"))
 
 		body, _ := json.Marshal(input)
 		req, _ := http.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(body))
@@ -257,3 +308,152 @@ func TestHandler_Login_Bad(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 }
+
+func TestHandler_RefreshToken(t *testing.T) {
+	mockService := new(MockAuthorizationService)
+	h := handler.NewHandler(&service.Service{Authorization: mockService}, handler.Config{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
+	router.POST("/token/refresh", h.RefreshToken)
+
+	t.Run("Refresh token from body", func(t *testing.T) {
+		mockService.On("Refresh", "bodyRefresh").Return(models.TokenPair{
+			AccessToken:      "newAccess",
+			RefreshToken:     "newRefresh",
+			RefreshExpiresAt: time.Now().Add(time.Hour),
+		}, nil)
+
+		body, _ := json.Marshal(models.RefreshTokenRequest{RefreshToken: "bodyRefresh"})
+		req, _ := http.NewRequest(http.MethodPost, "/token/refresh", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `"newAccess"`, w.Body.String())
+		assert.Contains(t, w.Header().Get("Set-Cookie"), "refresh_token=newRefresh")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Refresh token from cookie", func(t *testing.T) {
+		mockService.On("Refresh", "cookieRefresh").Return(models.TokenPair{AccessToken: "newAccess"}, nil)
+
+		req, _ := http.NewRequest(http.MethodPost, "/token/refresh", nil)
+		req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "cookieRefresh"})
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Missing refresh token", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/token/refresh", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.JSONEq(t, `{"message":"refresh token is required"}`, w.Body.String())
+	})
+
+	t.Run("Reused refresh token", func(t *testing.T) {
+		mockService.On("Refresh", "reused").Return(models.TokenPair{}, errors.New("refresh token reused"))
+
+		req, _ := http.NewRequest(http.MethodPost, "/token/refresh", nil)
+		req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "reused"})
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.JSONEq(t, `{"message":"Unauthorized"}`, w.Body.String())
+		assert.Contains(t, w.Header().Get("Set-Cookie"), "Max-Age=0")
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestHandler_Logout(t *testing.T) {
+	mockService := new(MockAuthorizationService)
+	h := handler.NewHandler(&service.Service{Authorization: mockService}, handler.Config{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
+	router.POST("/logout", h.JWTMiddleware(), h.Logout)
+
+	mockService.On("ParseToken", "accessToken").Return(uuid.New(), models.RoleEmployee, nil)
+	mockService.On("Logout", "accessToken", "refreshToken").Return(nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/logout", nil)
+	req.Header.Set("Authorization", "Bearer accessToken")
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "refreshToken"})
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "Max-Age=0")
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_RequirePermission(t *testing.T) {
+	policy, err := rbac.FromConfig(map[string][]string{"auditor": {"pvz:read"}})
+	assert.NoError(t, err)
+	h := handler.NewHandler(&service.Service{}, handler.Config{Policy: policy})
+
+	gin.SetMode(gin.TestMode)
+	newRouter := func(role *models.Role) *gin.Engine {
+		router := gin.New()
+		router.Use(handler.ErrorMiddleware())
+		router.Use(func(c *gin.Context) {
+			if role != nil {
+				c.Set("role", *role)
+			}
+		})
+		router.GET("/pvz", h.RequirePermission(rbac.PVZRead), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		router.POST("/pvz", h.RequirePermission(rbac.PVZCreate), func(c *gin.Context) {
+			c.Status(http.StatusCreated)
+		})
+		return router
+	}
+
+	auditor := models.Role("auditor")
+	client := models.RoleClient
+
+	tests := []struct {
+		name   string
+		role   *models.Role
+		method string
+		status int
+	}{
+		{name: "No role", role: nil, method: http.MethodGet, status: http.StatusUnauthorized},
+		{name: "Configured role allowed", role: &auditor, method: http.MethodGet, status: http.StatusOK},
+		{name: "Configured role forbidden", role: &auditor, method: http.MethodPost, status: http.StatusForbidden},
+		{name: "Built-in role forbidden", role: &client, method: http.MethodGet, status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, "/pvz", nil)
+			w := httptest.NewRecorder()
+			newRouter(tt.role).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+
+	t.Run("Forbidden message names the permission", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/pvz", nil)
+		w := httptest.NewRecorder()
+		newRouter(&auditor).ServeHTTP(w, req)
+
+		assert.JSONEq(t, `{"message":"permission pvz:create required"}`, w.Body.String())
+	})
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const cityIdParam = "cityId"
+
+func (h *Handler) CreateCity(c *gin.Context) {
+	var req models.CityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, apperr.Validation("binding error"))
+		return
+	}
+	if err := h.validate.Struct(&req); err != nil {
+		abortWithError(c, apperr.Validation("city name is required"))
+		return
+	}
+
+	city, err := h.services.City.CreateCity(req.Name)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, city)
+}
+
+func (h *Handler) GetCities(c *gin.Context) {
+	cities, err := h.services.City.GetCities()
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cities)
+}
+
+func (h *Handler) GetCity(c *gin.Context) {
+	cityID, err := uuid.Parse(c.Param(cityIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", cityIdParam))
+		return
+	}
+
+	city, err := h.services.City.GetCityByID(cityID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, city)
+}
+
+func (h *Handler) UpdateCity(c *gin.Context) {
+	cityID, err := uuid.Parse(c.Param(cityIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", cityIdParam))
+		return
+	}
+
+	var req models.CityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, apperr.Validation("binding error"))
+		return
+	}
+	if err := h.validate.Struct(&req); err != nil {
+		abortWithError(c, apperr.Validation("city name is required"))
+		return
+	}
+
+	city, err := h.services.City.UpdateCity(cityID, req.Name)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, city)
+}
+
+func (h *Handler) DeleteCity(c *gin.Context) {
+	cityID, err := uuid.Parse(c.Param(cityIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", cityIdParam))
+		return
+	}
+
+	if err := h.services.City.DeleteCity(cityID); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,176 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"pvz-test/internal/handler"
+	"pvz-test/internal/models"
+	"pvz-test/internal/rbac"
+	"pvz-test/internal/repository"
+	"pvz-test/internal/service"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCityService struct {
+	mock.Mock
+}
+
+func (m *MockCityService) CreateCity(name string) (models.City, error) {
+	args := m.Called(name)
+	return args.Get(0).(models.City), args.Error(1)
+}
+
+func (m *MockCityService) GetCities() ([]models.City, error) {
+	args := m.Called()
+	return args.Get(0).([]models.City), args.Error(1)
+}
+
+func (m *MockCityService) GetCityByID(cityID uuid.UUID) (models.City, error) {
+	args := m.Called(cityID)
+	return args.Get(0).(models.City), args.Error(1)
+}
+
+func (m *MockCityService) UpdateCity(cityID uuid.UUID, name string) (models.City, error) {
+	args := m.Called(cityID, name)
+	return args.Get(0).(models.City), args.Error(1)
+}
+
+func (m *MockCityService) DeleteCity(cityID uuid.UUID) error {
+	args := m.Called(cityID)
+	return args.Error(0)
+}
+
+func TestHandler_CreateCity(t *testing.T) {
+	mockService := new(MockCityService)
+	h := handler.NewHandler(&service.Service{City: mockService}, handler.Config{})
+	routes := func(r gin.IRoutes) {
+		r.POST("/cities", h.CreateCity)
+	}
+
+	t.Run("Employee is forbidden", func(t *testing.T) {
+		router := newTestRouter(h, employee, rbac.CityManage, routes)
+
+		body, _ := json.Marshal(models.CityRequest{Name: "Новосибирск"})
+		req, _ := http.NewRequest(http.MethodPost, "/cities", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("City created", func(t *testing.T) {
+		router := newTestRouter(h, moderator, rbac.CityManage, routes)
+		city := models.City{ID: uuid.New(), Name: "Новосибирск"}
+		mockService.On("CreateCity", "Новосибирск").Return(city, nil).Once()
+
+		body, _ := json.Marshal(models.CityRequest{Name: "Новосибирск"})
+		req, _ := http.NewRequest(http.MethodPost, "/cities", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("City already exists", func(t *testing.T) {
+		router := newTestRouter(h, moderator, rbac.CityManage, routes)
+		mockService.On("CreateCity", "Москва").Return(models.City{}, repository.ErrCityExists).Once()
+
+		body, _ := json.Marshal(models.CityRequest{Name: "Москва"})
+		req, _ := http.NewRequest(http.MethodPost, "/cities", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.JSONEq(t, `{"message":"city already exists"}`, w.Body.String())
+	})
+
+	t.Run("Empty name", func(t *testing.T) {
+		router := newTestRouter(h, moderator, rbac.CityManage, routes)
+
+		body, _ := json.Marshal(models.CityRequest{})
+		req, _ := http.NewRequest(http.MethodPost, "/cities", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_UpdateCity(t *testing.T) {
+	mockService := new(MockCityService)
+	h := handler.NewHandler(&service.Service{City: mockService}, handler.Config{})
+	routes := func(r gin.IRoutes) {
+		r.PATCH("/cities/:cityId", h.UpdateCity)
+	}
+	router := newTestRouter(h, moderator, rbac.CityManage, routes)
+
+	t.Run("City not found", func(t *testing.T) {
+		cityID := uuid.New()
+		mockService.On("UpdateCity", cityID, "Казань").Return(models.City{}, service.ErrCityNotFound).Once()
+
+		body, _ := json.Marshal(models.CityRequest{Name: "Казань"})
+		req, _ := http.NewRequest(http.MethodPatch, "/cities/"+cityID.String(), bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid city id", func(t *testing.T) {
+		body, _ := json.Marshal(models.CityRequest{Name: "Казань"})
+		req, _ := http.NewRequest(http.MethodPatch, "/cities/bad", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_DeleteCity(t *testing.T) {
+	mockService := new(MockCityService)
+	h := handler.NewHandler(&service.Service{City: mockService}, handler.Config{})
+	routes := func(r gin.IRoutes) {
+		r.DELETE("/cities/:cityId", h.DeleteCity)
+	}
+	router := newTestRouter(h, moderator, rbac.CityManage, routes)
+
+	t.Run("City deleted", func(t *testing.T) {
+		cityID := uuid.New()
+		mockService.On("DeleteCity", cityID).Return(nil).Once()
+
+		req, _ := http.NewRequest(http.MethodDelete, "/cities/"+cityID.String(), nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("City has pvz", func(t *testing.T) {
+		cityID := uuid.New()
+		mockService.On("DeleteCity", cityID).Return(repository.ErrCityInUse).Once()
+
+		req, _ := http.NewRequest(http.MethodDelete, "/cities/"+cityID.String(), nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+	mockService.AssertExpectations(t)
+}
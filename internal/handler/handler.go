@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"pvz-test/internal/metrics"
+	"pvz-test/internal/rbac"
 	"pvz-test/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -10,37 +12,102 @@ import (
 type Handler struct {
 	services *service.Service
 	validate *validator.Validate
+	cfg      Config
+}
+
+type Config struct {
+	DefaultPageSize int
+	MaxPageSize     int
+	MaxBatchSize    int
+	Policy          *rbac.Policy
 }
 
 const pvzIdParam = "pvzId"
 
-func NewHandler(services *service.Service) *Handler {
+func NewHandler(services *service.Service, cfg Config) *Handler {
+	if cfg.DefaultPageSize < 1 {
+		cfg.DefaultPageSize = 10
+	}
+	if cfg.MaxPageSize < cfg.DefaultPageSize {
+		cfg.MaxPageSize = 30
+	}
+	if cfg.MaxBatchSize < 1 {
+		cfg.MaxBatchSize = 100
+	}
+	if cfg.Policy == nil {
+		cfg.Policy = rbac.DefaultPolicy()
+	}
+
 	return &Handler{
 		services: services,
 		validate: validator.New(),
+		cfg:      cfg,
 	}
 }
 
 func (h *Handler) InitRoutes() *gin.Engine {
 
 	router := gin.New()
+	router.Use(metrics.GinMiddleware())
+	router.Use(ErrorMiddleware())
+
+	router.GET("/healthz", h.Healthz)
+	router.GET("/readyz", h.Readyz)
 
 	api := router.Group("/api")
 	{
 		api.POST("/register", h.Register)
 		api.POST("/login", h.Login)
 		api.POST("/dummyLogin", h.DummyLogin)
+		api.POST("/token/refresh", h.RefreshToken)
 
-		api.Use(h.JWTMiddleware())
+		api.Use(h.JWTMiddleware(), h.IdempotencyMiddleware())
 		{
-			api.POST("/pvz", h.CreatePVZ)
-			api.GET("/pvz", h.GetPVZList)
+			api.POST("/logout", h.Logout)
+
+			api.POST("/pvz", h.RequirePermission(rbac.PVZCreate), h.CreatePVZ)
+			api.GET("/pvz", h.RequirePermission(rbac.PVZRead), h.GetPVZList)
+			api.GET("/pvz/:pvzId", h.RequirePermission(rbac.PVZManage), h.GetPVZ)
+			api.PATCH("/pvz/:pvzId", h.RequirePermission(rbac.PVZManage), h.UpdatePVZ)
+			api.POST("/pvz/:pvzId/deactivate", h.RequirePermission(rbac.PVZManage), h.DeactivatePVZ)
+
+			employees := api.Group("/pvz/:pvzId/employees", h.RequirePermission(rbac.StaffManage))
+			{
+				employees.GET("", h.GetPVZEmployees)
+				employees.PUT("/:userId", h.AssignEmployee)
+				employees.DELETE("/:userId", h.UnassignEmployee)
+			}
+
+			cities := api.Group("/cities", h.RequirePermission(rbac.CityManage))
+			{
+				cities.GET("", h.GetCities)
+				cities.POST("", h.CreateCity)
+				cities.GET("/:cityId", h.GetCity)
+				cities.PATCH("/:cityId", h.UpdateCity)
+				cities.DELETE("/:cityId", h.DeleteCity)
+			}
+
+			api.POST("/receptions", h.RequirePermission(rbac.ReceptionWrite), h.CreateReception)
+			api.GET("/receptions/:receptionId", h.RequirePermission(rbac.ReceptionRead), h.GetReception)
+			api.GET("/pvz/:pvzId/receptions", h.RequirePermission(rbac.ReceptionRead), h.GetReceptions)
+			api.POST("/pvz/:pvzId/close_last_reception", h.RequirePermission(rbac.ReceptionWrite), h.CloseReception)
+
+			api.GET("/audit", h.RequirePermission(rbac.AuditRead), h.GetAuditEvents)
 
-			api.POST("/receptions", h.CreateReception)
-			api.POST("/pvz/:pvzId/close_last_reception", h.CloseReception)
+			webhooks := api.Group("/webhooks", h.RequirePermission(rbac.WebhookManage))
+			{
+				webhooks.GET("", h.GetWebhooks)
+				webhooks.POST("", h.CreateWebhook)
+				webhooks.DELETE("/:webhookId", h.DeleteWebhook)
+				webhooks.GET("/:webhookId/deliveries", h.GetWebhookDeliveries)
+				webhooks.POST("/:webhookId/deliveries/:deliveryId/retry", h.RetryWebhookDelivery)
+			}
 
-			api.POST("/products", h.AddItem)
-			api.POST("/pvz/:pvzId/delete_last_product", h.RemoveLastItem)
+			api.POST("/products", h.RequirePermission(rbac.ProductWrite), h.AddItem)
+			api.GET("/products", h.RequirePermission(rbac.ReceptionRead), h.FindProduct)
+			api.POST("/receptions/:receptionId/products/batch", h.RequirePermission(rbac.ProductWrite), h.AddItemsBatch)
+			api.POST("/pvz/:pvzId/delete_last_product", h.RequirePermission(rbac.ProductWrite), h.RemoveLastItem)
+			api.DELETE("/receptions/:receptionId/products/:productId", h.RequirePermission(rbac.ProductWrite), h.RemoveItem)
 		}
 	}
 
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+	"pvz-test/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.Health.Liveness())
+}
+
+func (h *Handler) Readyz(c *gin.Context) {
+	report := h.services.Health.Readiness(c.Request.Context())
+	if report.Status != models.HealthStatusOK {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,102 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"pvz-test/internal/handler"
+	"pvz-test/internal/models"
+	"pvz-test/internal/service"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHealthService struct {
+	mock.Mock
+}
+
+func (m *MockHealthService) Liveness() models.HealthReport {
+	args := m.Called()
+	return args.Get(0).(models.HealthReport)
+}
+
+func (m *MockHealthService) Readiness(ctx context.Context) models.HealthReport {
+	args := m.Called(ctx)
+	return args.Get(0).(models.HealthReport)
+}
+
+func TestHandler_Healthz(t *testing.T) {
+	mockService := new(MockHealthService)
+	h := handler.NewHandler(&service.Service{Health: mockService}, handler.Config{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
+	router.GET("/healthz", h.Healthz)
+
+	mockService.On("Liveness").Return(models.HealthReport{Status: models.HealthStatusOK})
+
+	req, _ := http.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_Readyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Ready", func(t *testing.T) {
+		mockService := new(MockHealthService)
+		h := handler.NewHandler(&service.Service{Health: mockService}, handler.Config{})
+		router := gin.New()
+		router.Use(handler.ErrorMiddleware())
+		router.GET("/readyz", h.Readyz)
+
+		mockService.On("Readiness", mock.Anything).Return(models.HealthReport{
+			Status: models.HealthStatusOK,
+			Components: map[string]models.ComponentHealth{
+				"postgres": {Status: models.HealthStatusOK},
+			},
+		})
+
+		req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status":"ok","components":{"postgres":{"status":"ok"}}}`, w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Not ready", func(t *testing.T) {
+		mockService := new(MockHealthService)
+		h := handler.NewHandler(&service.Service{Health: mockService}, handler.Config{})
+		router := gin.New()
+		router.Use(handler.ErrorMiddleware())
+		router.GET("/readyz", h.Readyz)
+
+		mockService.On("Readiness", mock.Anything).Return(models.HealthReport{
+			Status: models.HealthStatusFail,
+			Components: map[string]models.ComponentHealth{
+				"postgres": {Status: models.HealthStatusFail, Error: "connection refused"},
+			},
+		})
+
+		req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.JSONEq(t, `{"status":"fail","components":{"postgres":{"status":"fail","error":"connection refused"}}}`, w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// responseRecorder keeps a copy of the response body written by the
+// handlers so that it can be stored for replays.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware makes POST requests with an Idempotency-Key header
+// safe to retry. The first successful response for a user, route and key is
+// stored and replayed for identical retries. A retry with a different body
+// is rejected. It expects JWTMiddleware to have set the user.
+func (h *Handler) IdempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" || h.services.Idempotency == nil {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(c, apperr.Validation("%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortWithError(c, apperr.Validation("failed to read request body"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(body)
+
+		idemKey := models.IdempotencyKey{
+			UserID: currentActor(c).UserID,
+			Route:  c.Request.Method + " " + c.Request.URL.Path,
+			Key:    key,
+		}
+		stored, err := h.services.Idempotency.Begin(idemKey, hex.EncodeToString(sum[:]))
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if stored != nil {
+			c.Header(idempotentReplayedHeader, "true")
+			c.Data(stored.StatusCode, stored.ContentType, stored.Body)
+			c.Abort()
+			return
+		}
+
+		// A panicking handler must not leave the key reserved, otherwise
+		// every retry is rejected as in progress until the key expires.
+		defer func() {
+			if r := recover(); r != nil {
+				h.releaseIdempotencyKey(idemKey)
+				panic(r)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		renderError(c)
+
+		status := recorder.Status()
+		if status < http.StatusOK || status >= http.StatusMultipleChoices {
+			h.releaseIdempotencyKey(idemKey)
+			return
+		}
+		response := models.StoredResponse{
+			StatusCode:  status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}
+		if err := h.services.Idempotency.Complete(idemKey, response); err != nil {
+			logrus.Errorf("failed to store idempotent response: %s", err.Error())
+		}
+	}
+}
+
+func (h *Handler) releaseIdempotencyKey(key models.IdempotencyKey) {
+	if err := h.services.Idempotency.Release(key); err != nil {
+		logrus.Errorf("failed to release idempotency key: %s", err.Error())
+	}
+}
@@ -0,0 +1,187 @@
+package handler_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/handler"
+	"pvz-test/internal/models"
+	"pvz-test/internal/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryIdempotencyRepository keeps idempotency keys in a map and ignores
+// expiry.
+type memoryIdempotencyRepository struct {
+	records map[models.IdempotencyKey]models.IdempotencyRecord
+}
+
+func (r *memoryIdempotencyRepository) ReserveKey(key models.IdempotencyKey, requestHash string, ttl time.Duration) (models.IdempotencyRecord, bool, error) {
+	if record, ok := r.records[key]; ok {
+		return record, false, nil
+	}
+	r.records[key] = models.IdempotencyRecord{RequestHash: requestHash}
+	return models.IdempotencyRecord{}, true, nil
+}
+
+func (r *memoryIdempotencyRepository) SaveResponse(key models.IdempotencyKey, response models.StoredResponse) error {
+	record := r.records[key]
+	record.Response = &response
+	r.records[key] = record
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) DeleteKey(key models.IdempotencyKey) error {
+	delete(r.records, key)
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) DeleteExpiredKeys() (int64, error) {
+	return 0, nil
+}
+
+func newIdempotencyRouter(repo *memoryIdempotencyRepository, userID uuid.UUID, calls *int) *gin.Engine {
+	h := handler.NewHandler(&service.Service{Idempotency: service.NewIdempotencyStore(repo, time.Hour)}, handler.Config{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(handler.ErrorMiddleware())
+	router.Use(func(c *gin.Context) {
+		c.Set("userId", userID)
+	})
+	router.Use(h.IdempotencyMiddleware())
+	router.POST("/pvz", func(c *gin.Context) {
+		*calls++
+		c.JSON(http.StatusCreated, gin.H{"call": *calls})
+	})
+	router.POST("/fail", func(c *gin.Context) {
+		*calls++
+		_ = c.Error(apperr.Conflict("no active reception"))
+	})
+	router.POST("/panic", func(c *gin.Context) {
+		*calls++
+		panic("handler failed")
+	})
+	return router
+}
+
+func postWithKey(router *gin.Engine, path, key, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler_IdempotencyMiddleware(t *testing.T) {
+	t.Run("Retry replays the first response", func(t *testing.T) {
+		var calls int
+		router := newIdempotencyRouter(&memoryIdempotencyRepository{records: map[models.IdempotencyKey]models.IdempotencyRecord{}}, uuid.New(), &calls)
+
+		first := postWithKey(router, "/pvz", "key-1", `{"city":"Москва"}`)
+		retry := postWithKey(router, "/pvz", "key-1", `{"city":"Москва"}`)
+
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.JSONEq(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+		assert.True(t, strings.HasPrefix(retry.Header().Get("Content-Type"), "application/json"))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Same key with a different body", func(t *testing.T) {
+		var calls int
+		router := newIdempotencyRouter(&memoryIdempotencyRepository{records: map[models.IdempotencyKey]models.IdempotencyRecord{}}, uuid.New(), &calls)
+
+		postWithKey(router, "/pvz", "key-1", `{"city":"Москва"}`)
+		w := postWithKey(router, "/pvz", "key-1", `{"city":"Казань"}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Keys are separate per user", func(t *testing.T) {
+		var calls int
+		repo := &memoryIdempotencyRepository{records: map[models.IdempotencyKey]models.IdempotencyRecord{}}
+
+		postWithKey(newIdempotencyRouter(repo, uuid.New(), &calls), "/pvz", "key-1", `{}`)
+		w := postWithKey(newIdempotencyRouter(repo, uuid.New(), &calls), "/pvz", "key-1", `{}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Request in progress", func(t *testing.T) {
+		var calls int
+		userID := uuid.New()
+		repo := &memoryIdempotencyRepository{records: map[models.IdempotencyKey]models.IdempotencyRecord{}}
+		router := newIdempotencyRouter(repo, userID, &calls)
+		sum := sha256.Sum256(nil)
+		_, _, _ = repo.ReserveKey(models.IdempotencyKey{UserID: userID, Route: "POST /pvz", Key: "key-1"}, hex.EncodeToString(sum[:]), time.Hour)
+
+		w := postWithKey(router, "/pvz", "key-1", ``)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("Failed request is not stored", func(t *testing.T) {
+		var calls int
+		router := newIdempotencyRouter(&memoryIdempotencyRepository{records: map[models.IdempotencyKey]models.IdempotencyRecord{}}, uuid.New(), &calls)
+
+		first := postWithKey(router, "/fail", "key-1", `{}`)
+		retry := postWithKey(router, "/fail", "key-1", `{}`)
+
+		assert.Equal(t, http.StatusConflict, first.Code)
+		assert.JSONEq(t, `{"message":"no active reception"}`, first.Body.String())
+		assert.Equal(t, http.StatusConflict, retry.Code)
+		assert.Empty(t, retry.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Panicking request releases the key", func(t *testing.T) {
+		var calls int
+		repo := &memoryIdempotencyRepository{records: map[models.IdempotencyKey]models.IdempotencyRecord{}}
+		router := newIdempotencyRouter(repo, uuid.New(), &calls)
+
+		first := postWithKey(router, "/panic", "key-1", `{}`)
+		retry := postWithKey(router, "/panic", "key-1", `{}`)
+
+		assert.Equal(t, http.StatusInternalServerError, first.Code)
+		assert.Equal(t, http.StatusInternalServerError, retry.Code)
+		assert.Equal(t, 2, calls)
+		assert.Empty(t, repo.records)
+	})
+
+	t.Run("Without a key", func(t *testing.T) {
+		var calls int
+		router := newIdempotencyRouter(&memoryIdempotencyRepository{records: map[models.IdempotencyKey]models.IdempotencyRecord{}}, uuid.New(), &calls)
+
+		postWithKey(router, "/pvz", "", `{}`)
+		postWithKey(router, "/pvz", "", `{}`)
+
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Key too long", func(t *testing.T) {
+		var calls int
+		router := newIdempotencyRouter(&memoryIdempotencyRepository{records: map[models.IdempotencyKey]models.IdempotencyRecord{}}, uuid.New(), &calls)
+
+		w := postWithKey(router, "/pvz", strings.Repeat("k", 256), `{}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, 0, calls)
+	})
+}
@@ -1,61 +1,113 @@
 package handler
 
 import (
-	"fmt"
 	"net/http"
+	"pvz-test/internal/apperr"
 	"pvz-test/internal/models"
+	"pvz-test/internal/rbac"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+const productIdParam = "productId"
+
 func (h *Handler) RemoveLastItem(c *gin.Context) {
-	role, ok := c.Get(roleCtx)
-	if !ok || role != models.RoleEmployee {
-		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+	pvzID, err := uuid.Parse(c.Param(pvzIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", pvzIdParam))
 		return
 	}
 
-	pvzIDStr := c.Param(pvzIdParam)
-	if pvzIDStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing pvzId parameter"})
+	logrus.Infof("start to delete last item from: %s", pvzID)
+	err = h.services.Reception.DeleteItem(currentActor(c), pvzID)
+	if err != nil {
+		abortWithError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{"message": "item deleted successfully"})
 
-	pvzID, err := uuid.Parse(pvzIDStr)
+}
+func (h *Handler) RemoveItem(c *gin.Context) {
+	receptionID, err := uuid.Parse(c.Param(receptionIdParam))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("failed to delete item: %s", err.Error())})
+		abortWithError(c, apperr.Validation("%s parse error", receptionIdParam))
 		return
 	}
-
-	logrus.Infof("start to delete last item from: %s", pvzID)
-	err = h.services.Reception.DeleteItem(pvzID)
+	productID, err := uuid.Parse(c.Param(productIdParam))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to delete item: %s", err.Error())})
+		abortWithError(c, apperr.Validation("%s parse error", productIdParam))
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "item deleted successfully"})
 
-}
-func (h *Handler) AddItem(c *gin.Context) {
-	role, ok := c.Get(roleCtx)
-	if !ok || role != models.RoleEmployee {
-		c.JSON(http.StatusForbidden, fmt.Errorf("role verification error"))
+	if err := h.services.Reception.DeleteItemByID(currentActor(c), receptionID, productID); err != nil {
+		abortWithError(c, err)
 		return
 	}
+	c.Status(http.StatusNoContent)
+}
 
+func (h *Handler) AddItem(c *gin.Context) {
 	var req models.AddProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		abortWithError(c, apperr.Validation("invalid request"))
 		return
 	}
 
-	item, err := h.services.Reception.AddItem(req.PvzID, req.Type)
+	details := models.ItemDetails{Barcode: req.Barcode, SKU: req.SKU, Description: req.Description}
+	item, err := h.services.Reception.AddItem(currentActor(c), req.PvzID, req.Type, details)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		abortWithError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, item)
 
 }
+
+func (h *Handler) AddItemsBatch(c *gin.Context) {
+	receptionID, err := uuid.Parse(c.Param(receptionIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", receptionIdParam))
+		return
+	}
+
+	var req models.AddProductsBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, apperr.Validation("invalid request"))
+		return
+	}
+	if len(req.Products) > h.cfg.MaxBatchSize {
+		abortWithError(c, apperr.Validation("batch must contain at most %d products", h.cfg.MaxBatchSize))
+		return
+	}
+
+	newItems := make([]models.NewItem, len(req.Products))
+	for i, product := range req.Products {
+		newItems[i] = models.NewItem{
+			Type:        models.ItemType(product.Type),
+			ItemDetails: models.ItemDetails{Barcode: product.Barcode, SKU: product.SKU, Description: product.Description},
+		}
+	}
+	result, err := h.services.Reception.AddItems(currentActor(c), receptionID, newItems)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) FindProduct(c *gin.Context) {
+	barcode := c.Query("barcode")
+	if barcode == "" {
+		abortWithError(c, apperr.Validation("barcode is required"))
+		return
+	}
+
+	location, err := h.services.Reception.FindProduct(barcode, h.can(c, rbac.PVZReadDeactivated))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, location)
+}
@@ -3,10 +3,14 @@ package handler_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"pvz-test/internal/apperr"
 	"pvz-test/internal/handler"
 	"pvz-test/internal/models"
+	"pvz-test/internal/rbac"
+	"pvz-test/internal/repository"
 	"pvz-test/internal/service"
 	"testing"
 
@@ -20,40 +24,66 @@ type MockReceptionService struct {
 	mock.Mock
 }
 
-func (m *MockReceptionService) DeleteItem(pvzID uuid.UUID) error {
-	args := m.Called(pvzID)
+func (m *MockReceptionService) DeleteItemByID(actor models.Actor, receptionID, itemID uuid.UUID) error {
+	args := m.Called(actor, receptionID, itemID)
 	return args.Error(0)
 }
 
-func (m *MockReceptionService) AddItem(pvzID uuid.UUID, itemType string) (models.Item, error) {
-	args := m.Called(pvzID, itemType)
+func (m *MockReceptionService) DeleteItem(actor models.Actor, pvzID uuid.UUID) error {
+	args := m.Called(actor, pvzID)
+	return args.Error(0)
+}
+
+func (m *MockReceptionService) AddItem(actor models.Actor, pvzID uuid.UUID, itemType string, details models.ItemDetails) (models.Item, error) {
+	args := m.Called(actor, pvzID, itemType, details)
 	return args.Get(0).(models.Item), args.Error(1)
 }
 
-func (m *MockReceptionService) CloseActiveReception(pvzID uuid.UUID) (models.Reception, error) {
-	args := m.Called(pvzID)
+func (m *MockReceptionService) AddItems(actor models.Actor, receptionID uuid.UUID, newItems []models.NewItem) (models.BatchAddResult, error) {
+	args := m.Called(actor, receptionID, newItems)
+	return args.Get(0).(models.BatchAddResult), args.Error(1)
+}
+
+func (m *MockReceptionService) FindProduct(barcode string, includeDeactivated bool) (models.ProductLocation, error) {
+	args := m.Called(barcode, includeDeactivated)
+	return args.Get(0).(models.ProductLocation), args.Error(1)
+}
+
+func (m *MockReceptionService) CloseActiveReception(actor models.Actor, pvzID uuid.UUID) (models.Reception, error) {
+	args := m.Called(actor, pvzID)
 	return args.Get(0).(models.Reception), args.Error(1)
 }
 
-func (m *MockReceptionService) CreateReception(pvzID uuid.UUID) (models.Reception, error) {
-	args := m.Called(pvzID)
+func (m *MockReceptionService) CreateReception(actor models.Actor, pvzID uuid.UUID) (models.Reception, error) {
+	args := m.Called(actor, pvzID)
 	return args.Get(0).(models.Reception), args.Error(1)
 }
 
+func (m *MockReceptionService) GetReceptions(pvzID uuid.UUID, includeDeactivated bool, filter models.ReceptionFilter) ([]models.ReceptionBlock, error) {
+	args := m.Called(pvzID, includeDeactivated, filter)
+	return args.Get(0).([]models.ReceptionBlock), args.Error(1)
+}
+
+func (m *MockReceptionService) GetReception(receptionID uuid.UUID, includeDeactivated bool) (models.ReceptionBlock, error) {
+	args := m.Called(receptionID, includeDeactivated)
+	return args.Get(0).(models.ReceptionBlock), args.Error(1)
+}
+
 func TestHandler_RemoveLastItem(t *testing.T) {
 	mockService := new(MockReceptionService)
-	h := handler.NewHandler(&service.Service{Reception: mockService})
+	h := handler.NewHandler(&service.Service{Reception: mockService}, handler.Config{})
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
 	router.POST("/pvz/:pvzId/delete_last_product", func(c *gin.Context) {
 		c.Set("role", models.RoleEmployee)
-		h.RemoveLastItem(c)
-	})
+		c.Set("userId", employeeID)
+	}, h.RequirePermission(rbac.ProductWrite), h.RemoveLastItem)
 
 	t.Run("Successful removal", func(t *testing.T) {
 		pvzID := uuid.New()
-		mockService.On("DeleteItem", pvzID).Return(nil)
+		mockService.On("DeleteItem", employee, pvzID).Return(nil)
 
 		req, _ := http.NewRequest(http.MethodPost, "/pvz/"+pvzID.String()+"/delete_last_product?pvz_id="+pvzID.String(), nil)
 		w := httptest.NewRecorder()
@@ -75,7 +105,7 @@ func TestHandler_RemoveLastItem(t *testing.T) {
 
 	t.Run("Error during removal", func(t *testing.T) {
 		pvzID := uuid.New()
-		mockService.On("DeleteItem", pvzID).Return(assert.AnError)
+		mockService.On("DeleteItem", employee, pvzID).Return(assert.AnError)
 
 		req, _ := http.NewRequest(http.MethodPost, "/pvz/"+pvzID.String()+"/delete_last_product?pvz_id="+pvzID.String(), nil)
 		w := httptest.NewRecorder()
@@ -89,20 +119,21 @@ func TestHandler_RemoveLastItem(t *testing.T) {
 
 func TestHandler_AddItem(t *testing.T) {
 	mockService := new(MockReceptionService)
-	h := handler.NewHandler(&service.Service{Reception: mockService})
+	h := handler.NewHandler(&service.Service{Reception: mockService}, handler.Config{})
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
 	router.POST("/products", func(c *gin.Context) {
 		c.Set("role", models.RoleEmployee)
-		h.AddItem(c)
-	})
+		c.Set("userId", employeeID)
+	}, h.RequirePermission(rbac.ProductWrite), h.AddItem)
 
 	t.Run("Successful addition", func(t *testing.T) {
 		pvzID := uuid.New()
 		itemType := "electronics"
 		expectedItem := models.Item{ID: uuid.New(), ReceptionID: uuid.New(), Type: models.ItemTypeElectronics}
-		mockService.On("AddItem", pvzID, itemType).Return(expectedItem, nil)
+		mockService.On("AddItem", employee, pvzID, itemType, models.ItemDetails{}).Return(expectedItem, nil)
 
 		body, _ := json.Marshal(models.AddProductRequest{PvzID: pvzID, Type: itemType})
 		req, _ := http.NewRequest(http.MethodPost, "/products", bytes.NewBuffer(body))
@@ -128,7 +159,7 @@ func TestHandler_AddItem(t *testing.T) {
 	t.Run("Error during addition", func(t *testing.T) {
 		pvzID := uuid.New()
 		itemType := "electronics"
-		mockService.On("AddItem", pvzID, itemType).Return(models.Item{}, assert.AnError)
+		mockService.On("AddItem", employee, pvzID, itemType, models.ItemDetails{}).Return(models.Item{}, assert.AnError)
 
 		body, _ := json.Marshal(models.AddProductRequest{PvzID: pvzID, Type: itemType})
 		req, _ := http.NewRequest(http.MethodPost, "/products", bytes.NewBuffer(body))
@@ -137,7 +168,292 @@ func TestHandler_AddItem(t *testing.T) {
 
 		router.ServeHTTP(w, req)
 
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("No active reception", func(t *testing.T) {
+		pvzID := uuid.New()
+		mockService.On("AddItem", employee, pvzID, "shoes", models.ItemDetails{}).
+			Return(models.Item{}, fmt.Errorf("%w for PVZ %s", repository.ErrNoActiveReception, pvzID))
+
+		body, _ := json.Marshal(models.AddProductRequest{PvzID: pvzID, Type: "shoes"})
+		req, _ := http.NewRequest(http.MethodPost, "/products", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"message":"no active reception for PVZ `+pvzID.String()+`"}`, w.Body.String())
+	})
+
+	t.Run("Invalid item type", func(t *testing.T) {
+		pvzID := uuid.New()
+		mockService.On("AddItem", employee, pvzID, "furniture", models.ItemDetails{}).
+			Return(models.Item{}, &apperr.ValidationError{Field: "type", Value: "furniture", Reason: "unknown"})
+
+		body, _ := json.Marshal(models.AddProductRequest{PvzID: pvzID, Type: "furniture"})
+		req, _ := http.NewRequest(http.MethodPost, "/products", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid type")
+	})
+}
+
+func TestHandler_AddItemsBatch(t *testing.T) {
+	mockService := new(MockReceptionService)
+	h := handler.NewHandler(&service.Service{Reception: mockService}, handler.Config{MaxBatchSize: 3})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
+	router.POST("/receptions/:receptionId/products/batch", func(c *gin.Context) {
+		c.Set("role", models.RoleEmployee)
+		c.Set("userId", employeeID)
+	}, h.RequirePermission(rbac.ProductWrite), h.AddItemsBatch)
+
+	batch := func(types ...string) []byte {
+		req := models.AddProductsBatchRequest{}
+		for _, itemType := range types {
+			req.Products = append(req.Products, models.BatchProduct{Type: itemType})
+		}
+		body, _ := json.Marshal(req)
+		return body
+	}
+
+	t.Run("Per-item results", func(t *testing.T) {
+		receptionID := uuid.New()
+		item := models.Item{ID: uuid.New(), ReceptionID: receptionID, Type: models.ItemTypeShoes}
+		result := models.BatchAddResult{
+			Added:  1,
+			Failed: 1,
+			Results: []models.BatchItemResult{
+				{Index: 0, Product: &item},
+				{Index: 1, Error: `invalid type "furniture": must be one of electronics, clothing, shoes`},
+			},
+		}
+		mockService.On("AddItems", employee, receptionID, []models.NewItem{{Type: models.ItemTypeShoes}, {Type: "furniture"}}).Return(result, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/receptions/"+receptionID.String()+"/products/batch", bytes.NewBuffer(batch("shoes", "furniture")))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.BatchAddResult
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.Added)
+		assert.Len(t, response.Results, 2)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Product without type is passed on for a per-item result", func(t *testing.T) {
+		receptionID := uuid.New()
+		result := models.BatchAddResult{
+			Failed:  1,
+			Results: []models.BatchItemResult{{Index: 0, Error: `invalid type "": must be one of electronics, clothing, shoes`}},
+		}
+		mockService.On("AddItems", employee, receptionID, []models.NewItem{{}}).Return(result, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/receptions/"+receptionID.String()+"/products/batch", bytes.NewBufferString(`{"products":[{}]}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Batch too large", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/receptions/"+uuid.New().String()+"/products/batch",
+			bytes.NewBuffer(batch("shoes", "shoes", "shoes", "shoes")))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"message":"batch must contain at most 3 products"}`, w.Body.String())
+	})
+
+	t.Run("Empty batch", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/receptions/"+uuid.New().String()+"/products/batch", bytes.NewBuffer(batch()))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Reception closed", func(t *testing.T) {
+		receptionID := uuid.New()
+		mockService.On("AddItems", employee, receptionID, []models.NewItem{{Type: models.ItemTypeShoes}}).
+			Return(models.BatchAddResult{}, fmt.Errorf("%w: reception %s is closed", repository.ErrNoActiveReception, receptionID)).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/receptions/"+receptionID.String()+"/products/batch", bytes.NewBuffer(batch("shoes")))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestHandler_RemoveItem(t *testing.T) {
+	mockService := new(MockReceptionService)
+	h := handler.NewHandler(&service.Service{Reception: mockService}, handler.Config{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
+	router.DELETE("/receptions/:receptionId/products/:productId", func(c *gin.Context) {
+		c.Set("role", models.RoleEmployee)
+		c.Set("userId", employeeID)
+	}, h.RequirePermission(rbac.ProductWrite), h.RemoveItem)
+
+	t.Run("Product deleted", func(t *testing.T) {
+		receptionID, productID := uuid.New(), uuid.New()
+		mockService.On("DeleteItemByID", employee, receptionID, productID).Return(nil).Once()
+
+		req, _ := http.NewRequest(http.MethodDelete, "/receptions/"+receptionID.String()+"/products/"+productID.String(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		receptionID, productID := uuid.New(), uuid.New()
+		mockService.On("DeleteItemByID", employee, receptionID, productID).
+			Return(fmt.Errorf("%w: %s", service.ErrProductNotFound, productID)).Once()
+
+		req, _ := http.NewRequest(http.MethodDelete, "/receptions/"+receptionID.String()+"/products/"+productID.String(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.JSONEq(t, `{"message":"product not found: `+productID.String()+`"}`, w.Body.String())
+	})
+
+	t.Run("Invalid product id", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodDelete, "/receptions/"+uuid.New().String()+"/products/bad", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
 		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_AddItem_Details(t *testing.T) {
+	mockService := new(MockReceptionService)
+	h := handler.NewHandler(&service.Service{Reception: mockService}, handler.Config{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
+	router.POST("/products", func(c *gin.Context) {
+		c.Set("role", models.RoleEmployee)
+		c.Set("userId", employeeID)
+	}, h.RequirePermission(rbac.ProductWrite), h.AddItem)
+
+	t.Run("Barcode and SKU are passed on", func(t *testing.T) {
+		pvzID := uuid.New()
+		barcode, sku := "4006381333931", "SH-42"
+		details := models.ItemDetails{Barcode: &barcode, SKU: &sku}
+		mockService.On("AddItem", employee, pvzID, "shoes", details).
+			Return(models.Item{ID: uuid.New(), Type: models.ItemTypeShoes, ItemDetails: details}, nil).Once()
+
+		body, _ := json.Marshal(models.AddProductRequest{PvzID: pvzID, Type: "shoes", Barcode: &barcode, SKU: &sku})
+		req, _ := http.NewRequest(http.MethodPost, "/products", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var item models.Item
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &item))
+		assert.Equal(t, details, item.ItemDetails)
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("Barcode already received", func(t *testing.T) {
+		pvzID := uuid.New()
+		barcode := "4006381333931"
+		mockService.On("AddItem", employee, pvzID, "shoes", models.ItemDetails{Barcode: &barcode}).
+			Return(models.Item{}, fmt.Errorf("%w: %s", repository.ErrBarcodeReceived, barcode)).Once()
+
+		body, _ := json.Marshal(models.AddProductRequest{PvzID: pvzID, Type: "shoes", Barcode: &barcode})
+		req, _ := http.NewRequest(http.MethodPost, "/products", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.JSONEq(t, `{"message":"barcode is already in an open reception: 4006381333931"}`, w.Body.String())
+	})
+}
+
+func TestHandler_FindProduct(t *testing.T) {
+	mockService := new(MockReceptionService)
+	h := handler.NewHandler(&service.Service{Reception: mockService}, handler.Config{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
+	router.GET("/products", func(c *gin.Context) {
+		c.Set("role", models.RoleEmployee)
+	}, h.RequirePermission(rbac.ReceptionRead), h.FindProduct)
+
+	t.Run("Found", func(t *testing.T) {
+		barcode := "4006381333931"
+		location := models.ProductLocation{
+			Product:   models.Item{ID: uuid.New(), Type: models.ItemTypeShoes, ItemDetails: models.ItemDetails{Barcode: &barcode}},
+			Reception: models.Reception{ID: uuid.New(), Status: models.ReceptionStatusInProgress},
+			PVZ:       models.PVZ{ID: uuid.New(), City: "Казань"},
+		}
+		mockService.On("FindProduct", barcode, false).Return(location, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/products?barcode="+barcode, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.ProductLocation
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, location.PVZ.City, response.PVZ.City)
+		assert.Equal(t, location.Reception.ID, response.Reception.ID)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockService.On("FindProduct", "PVZ-1", false).
+			Return(models.ProductLocation{}, fmt.Errorf("%w: barcode %s", service.ErrProductNotFound, "PVZ-1")).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/products?barcode=PVZ-1", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Moderator sees deactivated PVZ", func(t *testing.T) {
+		barcode := "4006381333931"
+		mockService.On("FindProduct", barcode, true).Return(models.ProductLocation{}, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/products?barcode="+barcode, nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.ReceptionRead, func(r gin.IRoutes) {
+			r.GET("/products", h.FindProduct)
+		}).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Missing barcode", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/products", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
 }
@@ -1,43 +1,79 @@
 package handler
 
 import (
-	"net/http"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/models"
+	"pvz-test/internal/rbac"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 const (
 	authorizationHeader = "Authorization"
 	userCtx             = "userId"
 	roleCtx             = "role"
+	accessTokenCtx      = "accessToken"
+
+	refreshTokenCookie = "refresh_token"
+	refreshTokenPath   = "/api"
 )
 
 func (h *Handler) JWTMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader(authorizationHeader)
 		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization token is required"})
-			c.Abort()
+			abortWithError(c, apperr.Unauthorized("Authorization token is required"))
 			return
 		}
 
 		if len(tokenString) < (len("Bearer ")) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
+			abortWithError(c, apperr.Unauthorized("Invalid or expired token"))
 			return
 		}
 		tokenString = tokenString[len("Bearer "):]
 
 		userId, role, err := h.services.Authorization.ParseToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
+			abortWithError(c, apperr.Unauthorized("Invalid or expired token"))
 			return
 		}
 
 		c.Set(userCtx, userId)
 		c.Set(roleCtx, role)
+		c.Set(accessTokenCtx, tokenString)
 
 		c.Next()
 	}
 }
+
+// RequirePermission rejects requests whose role is not granted perm by the
+// handler policy. It expects JWTMiddleware to have set the role.
+func (h *Handler) RequirePermission(perm rbac.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(roleCtx); !ok {
+			abortWithError(c, apperr.Unauthorized("unauthorized"))
+			return
+		}
+		if !h.can(c, perm) {
+			abortWithError(c, apperr.Forbidden("permission %s required", perm))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func (h *Handler) can(c *gin.Context, perm rbac.Permission) bool {
+	role, _ := c.Get(roleCtx)
+	r, ok := role.(models.Role)
+	return ok && h.cfg.Policy.Allows(r, perm)
+}
+
+// currentActor returns the caller set by JWTMiddleware. Missing values are
+// left zero.
+func currentActor(c *gin.Context) models.Actor {
+	userID, _ := c.Value(userCtx).(uuid.UUID)
+	role, _ := c.Value(roleCtx).(models.Role)
+	return models.Actor{UserID: userID, Role: role}
+}
@@ -1,36 +1,29 @@
 package handler
 
 import (
-	"fmt"
-	"log"
 	"net/http"
+	"pvz-test/internal/apperr"
 	"pvz-test/internal/models"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 func (h *Handler) CreatePVZ(c *gin.Context) {
 	var req models.PVZRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong request format"})
+		abortWithError(c, apperr.Validation("Wrong request format"))
 		return
 	}
 
 	if err := h.validate.Struct(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong city format"})
+		abortWithError(c, apperr.Validation("Wrong city format"))
 		return
 	}
 
-	userRole, _ := c.Get(roleCtx)
-	if userRole != models.RoleModerator {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only moderators can create PVZ"})
-		return
-	}
-
-	newPVZ, err := h.services.CreatePvz(req.City)
+	newPVZ, err := h.services.CreatePvz(currentActor(c), req.City)
 	if err != nil {
-		log.Println("failed to create pvz:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Pvz creation error"})
+		abortWithError(c, err)
 		return
 	}
 
@@ -38,31 +31,89 @@ func (h *Handler) CreatePVZ(c *gin.Context) {
 }
 
 func (h *Handler) GetPVZList(c *gin.Context) {
-	userRole, _ := c.Get(roleCtx)
-	if userRole != models.RoleModerator && userRole != models.RoleEmployee {
-		c.JSON(http.StatusUnauthorized, fmt.Errorf("unauthorized"))
-		return
-	}
-
 	var q models.GetPVZListQuery
 	if err := c.ShouldBindQuery(&q); err != nil {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("invalid query parameters"))
+		abortWithError(c, apperr.Validation("invalid query parameters"))
 		return
 	}
 	if q.Page < 1 {
 		q.Page = 1
 	}
-	if q.Limit < 1 || q.Limit > 30 {
-		q.Limit = 10
+	if q.Limit < 1 || q.Limit > h.cfg.MaxPageSize {
+		q.Limit = h.cfg.DefaultPageSize
+	}
+	if q.Status == "" {
+		q.Status = models.PVZStatusActive
+	}
+	if err := h.validate.Struct(&q); err != nil {
+		abortWithError(c, apperr.Validation("invalid query parameters"))
+		return
 	}
 	offset := (q.Page - 1) * q.Limit
 
-	pvzList, err := h.services.Pvz.GetFilteredPVZ(q.StartDate, q.EndDate, q.Limit, offset)
+	pvzList, err := h.services.Pvz.GetFilteredPVZ(q.StartDate, q.EndDate, q.IncludeEmpty, q.Status, q.Limit, offset)
 	if err != nil {
-		log.Println("failed to fetch pvz list:", err)
-		c.JSON(http.StatusInternalServerError, fmt.Errorf("failed to fetch pvz list"))
+		abortWithError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, pvzList)
 }
+
+func (h *Handler) GetPVZ(c *gin.Context) {
+	pvzID, err := uuid.Parse(c.Param(pvzIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", pvzIdParam))
+		return
+	}
+
+	pvz, err := h.services.Pvz.GetPVZByID(pvzID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pvz)
+}
+
+func (h *Handler) UpdatePVZ(c *gin.Context) {
+	pvzID, err := uuid.Parse(c.Param(pvzIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", pvzIdParam))
+		return
+	}
+
+	var req models.UpdatePVZRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, apperr.Validation("binding error"))
+		return
+	}
+	if err := h.validate.Struct(&req); err != nil {
+		abortWithError(c, apperr.Validation("Wrong city format"))
+		return
+	}
+
+	pvz, err := h.services.Pvz.UpdatePVZ(currentActor(c), pvzID, req.City)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pvz)
+}
+
+func (h *Handler) DeactivatePVZ(c *gin.Context) {
+	pvzID, err := uuid.Parse(c.Param(pvzIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", pvzIdParam))
+		return
+	}
+
+	pvz, err := h.services.Pvz.DeactivatePVZ(currentActor(c), pvzID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pvz)
+}
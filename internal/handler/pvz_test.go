@@ -3,15 +3,18 @@ package handler_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"pvz-test/internal/handler"
 	"pvz-test/internal/models"
+	"pvz-test/internal/rbac"
 	"pvz-test/internal/service"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -20,29 +23,52 @@ type MockPvzService struct {
 	mock.Mock
 }
 
-func (m *MockPvzService) CreatePvz(city string) (models.PVZ, error) {
-	args := m.Called(city)
+func (m *MockPvzService) CreatePvz(actor models.Actor, city string) (models.PVZ, error) {
+	args := m.Called(actor, city)
 	return args.Get(0).(models.PVZ), args.Error(1)
 }
 
-func (m *MockPvzService) GetFilteredPVZ(start, end *time.Time, limit, offset int) ([]models.PVZResponse, error) {
-	args := m.Called(start, end, limit, offset)
+func (m *MockPvzService) GetFilteredPVZ(start, end *time.Time, includeEmpty bool, status models.PVZStatus, limit, offset int) ([]models.PVZResponse, error) {
+	args := m.Called(start, end, includeEmpty, status, limit, offset)
 	return args.Get(0).([]models.PVZResponse), args.Error(1)
 }
 
+func (m *MockPvzService) GetPVZList() ([]models.PVZ, error) {
+	args := m.Called()
+	return args.Get(0).([]models.PVZ), args.Error(1)
+}
+
+func (m *MockPvzService) GetPVZByID(pvzID uuid.UUID) (models.PVZ, error) {
+	args := m.Called(pvzID)
+	return args.Get(0).(models.PVZ), args.Error(1)
+}
+
+func (m *MockPvzService) UpdatePVZ(actor models.Actor, pvzID uuid.UUID, city string) (models.PVZ, error) {
+	args := m.Called(actor, pvzID, city)
+	return args.Get(0).(models.PVZ), args.Error(1)
+}
+
+func (m *MockPvzService) DeactivatePVZ(actor models.Actor, pvzID uuid.UUID) (models.PVZ, error) {
+	args := m.Called(actor, pvzID)
+	return args.Get(0).(models.PVZ), args.Error(1)
+}
+
 func TestHandler_CreatePVZ(t *testing.T) {
 	mockService := new(MockPvzService)
-	h := handler.NewHandler(&service.Service{Pvz: mockService})
+	h := handler.NewHandler(&service.Service{Pvz: mockService}, handler.Config{})
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
 
 	router.POST("/pvz", func(c *gin.Context) {
 		c.Set("role", models.RoleModerator)
-		h.CreatePVZ(c)
-	})
+		c.Set("userId", moderator.UserID)
+	}, h.RequirePermission(rbac.PVZCreate), h.CreatePVZ)
 
 	t.Run("Invalid city", func(t *testing.T) {
+		mockService.On("CreatePvz", moderator, "InvalidCity").Return(models.PVZ{}, fmt.Errorf("city InvalidCity %w", service.ErrCityNotSupported))
+
 		body, _ := json.Marshal(models.PVZRequest{City: "InvalidCity"})
 		req, _ := http.NewRequest(http.MethodPost, "/pvz", bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
@@ -53,16 +79,42 @@ func TestHandler_CreatePVZ(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 }
+
+func TestHandler_CreatePVZ_Good(t *testing.T) {
+	mockService := new(MockPvzService)
+	h := handler.NewHandler(&service.Service{Pvz: mockService}, handler.Config{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
+	router.POST("/pvz", func(c *gin.Context) {
+		c.Set("role", models.RoleModerator)
+		c.Set("userId", moderator.UserID)
+	}, h.RequirePermission(rbac.PVZCreate), h.CreatePVZ)
+
+	expected := models.PVZ{ID: uuid.New(), City: "Новосибирск"}
+	mockService.On("CreatePvz", moderator, "Новосибирск").Return(expected, nil)
+
+	body, _ := json.Marshal(models.PVZRequest{City: "Новосибирск"})
+	req, _ := http.NewRequest(http.MethodPost, "/pvz", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
 func TestHandler_GetPVZList(t *testing.T) {
 	mockService := new(MockPvzService)
-	h := handler.NewHandler(&service.Service{Pvz: mockService})
+	h := handler.NewHandler(&service.Service{Pvz: mockService}, handler.Config{})
 
 	t.Run("Unauthorized user", func(t *testing.T) {
 		gin.SetMode(gin.TestMode)
 		router := gin.New()
-		router.GET("/pvz", func(c *gin.Context) {
-			h.GetPVZList(c)
-		})
+		router.Use(handler.ErrorMiddleware())
+		router.GET("/pvz", h.RequirePermission(rbac.PVZRead), h.GetPVZList)
 
 		req, _ := http.NewRequest(http.MethodGet, "/pvz", nil)
 		w := httptest.NewRecorder()
@@ -75,10 +127,10 @@ func TestHandler_GetPVZList(t *testing.T) {
 	t.Run("Invalid query parameters", func(t *testing.T) {
 		gin.SetMode(gin.TestMode)
 		router := gin.New()
+		router.Use(handler.ErrorMiddleware())
 		router.GET("/pvz", func(c *gin.Context) {
 			c.Set("role", models.RoleEmployee)
-			h.GetPVZList(c)
-		})
+		}, h.RequirePermission(rbac.PVZRead), h.GetPVZList)
 
 		req, _ := http.NewRequest(http.MethodGet, "/pvz?startDate=invalid-date", nil)
 		req.Header.Set("Authorization", "Bearer dummy-token")
@@ -88,4 +140,173 @@ func TestHandler_GetPVZList(t *testing.T) {
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
+
+	t.Run("Defaults to active PVZ", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(handler.ErrorMiddleware())
+		router.GET("/pvz", func(c *gin.Context) {
+			c.Set("role", models.RoleEmployee)
+		}, h.RequirePermission(rbac.PVZRead), h.GetPVZList)
+		mockService.On("GetFilteredPVZ", (*time.Time)(nil), (*time.Time)(nil), false, models.PVZStatusActive, 10, 0).
+			Return([]models.PVZResponse{}, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/pvz", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid status", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(handler.ErrorMiddleware())
+		router.GET("/pvz", func(c *gin.Context) {
+			c.Set("role", models.RoleModerator)
+		}, h.RequirePermission(rbac.PVZRead), h.GetPVZList)
+
+		req, _ := http.NewRequest(http.MethodGet, "/pvz?status=archived", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_GetPVZ(t *testing.T) {
+	mockService := new(MockPvzService)
+	h := handler.NewHandler(&service.Service{Pvz: mockService}, handler.Config{})
+	routes := func(r gin.IRoutes) {
+		r.GET("/pvz/:pvzId", h.GetPVZ)
+	}
+
+	t.Run("Found", func(t *testing.T) {
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва"}
+		mockService.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/pvz/"+pvz.ID.String(), nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.PVZManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		pvzID := uuid.New()
+		mockService.On("GetPVZByID", pvzID).Return(models.PVZ{}, fmt.Errorf("%w: %s", service.ErrPvzNotFound, pvzID)).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/pvz/"+pvzID.String(), nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.PVZManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Employee forbidden", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/pvz/"+uuid.New().String(), nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, employee, rbac.PVZManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestHandler_UpdatePVZ(t *testing.T) {
+	mockService := new(MockPvzService)
+	h := handler.NewHandler(&service.Service{Pvz: mockService}, handler.Config{})
+	routes := func(r gin.IRoutes) {
+		r.PATCH("/pvz/:pvzId", h.UpdatePVZ)
+	}
+
+	t.Run("Updated", func(t *testing.T) {
+		pvz := models.PVZ{ID: uuid.New(), City: "Казань"}
+		mockService.On("UpdatePVZ", moderator, pvz.ID, "Казань").Return(pvz, nil).Once()
+
+		body, _ := json.Marshal(models.UpdatePVZRequest{City: "Казань"})
+		req, _ := http.NewRequest(http.MethodPatch, "/pvz/"+pvz.ID.String(), bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.PVZManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Unsupported city", func(t *testing.T) {
+		pvzID := uuid.New()
+		mockService.On("UpdatePVZ", moderator, pvzID, "Тверь").Return(models.PVZ{}, fmt.Errorf("city Тверь %w", service.ErrCityNotSupported)).Once()
+
+		body, _ := json.Marshal(models.UpdatePVZRequest{City: "Тверь"})
+		req, _ := http.NewRequest(http.MethodPatch, "/pvz/"+pvzID.String(), bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.PVZManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Deactivated", func(t *testing.T) {
+		pvzID := uuid.New()
+		mockService.On("UpdatePVZ", moderator, pvzID, "Казань").Return(models.PVZ{}, service.ErrPvzDeactivated).Once()
+
+		body, _ := json.Marshal(models.UpdatePVZRequest{City: "Казань"})
+		req, _ := http.NewRequest(http.MethodPatch, "/pvz/"+pvzID.String(), bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.PVZManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Empty city", func(t *testing.T) {
+		body, _ := json.Marshal(models.UpdatePVZRequest{})
+		req, _ := http.NewRequest(http.MethodPatch, "/pvz/"+uuid.New().String(), bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.PVZManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_DeactivatePVZ(t *testing.T) {
+	mockService := new(MockPvzService)
+	h := handler.NewHandler(&service.Service{Pvz: mockService}, handler.Config{})
+	routes := func(r gin.IRoutes) {
+		r.POST("/pvz/:pvzId/deactivate", h.DeactivatePVZ)
+	}
+
+	t.Run("Deactivated", func(t *testing.T) {
+		deactivatedAt := time.Now()
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва", DeactivatedAt: &deactivatedAt}
+		mockService.On("DeactivatePVZ", moderator, pvz.ID).Return(pvz, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/pvz/"+pvz.ID.String()+"/deactivate", nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.PVZManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "deactivatedAt")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Active reception", func(t *testing.T) {
+		pvzID := uuid.New()
+		mockService.On("DeactivatePVZ", moderator, pvzID).Return(models.PVZ{}, service.ErrPvzHasActiveReception).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/pvz/"+pvzID.String()+"/deactivate", nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.PVZManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Employee forbidden", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/pvz/"+uuid.New().String()+"/deactivate", nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, employee, rbac.PVZManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
 }
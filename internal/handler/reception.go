@@ -1,31 +1,28 @@
 package handler
 
 import (
-	"fmt"
 	"net/http"
+	"pvz-test/internal/apperr"
 	"pvz-test/internal/models"
+	"pvz-test/internal/rbac"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-func (h *Handler) CreateReception(c *gin.Context) {
-	role, ok := c.Get(roleCtx)
-	if !ok || role != models.RoleEmployee {
-		c.JSON(http.StatusForbidden, fmt.Errorf("role verification error"))
-		return
-	}
+const receptionIdParam = "receptionId"
 
+func (h *Handler) CreateReception(c *gin.Context) {
 	var recReq models.CreateReceptionRequest
 
-	if err := c.BindJSON(&recReq); err != nil {
-		newErrorResponse(c, http.StatusForbidden, `binding error`)
+	if err := c.ShouldBindJSON(&recReq); err != nil {
+		abortWithError(c, apperr.Validation("binding error"))
 		return
 	}
 
-	reception, err := h.services.CreateReception(recReq.PvzID)
+	reception, err := h.services.CreateReception(currentActor(c), recReq.PvzID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		abortWithError(c, err)
 		return
 	}
 
@@ -33,28 +30,71 @@ func (h *Handler) CreateReception(c *gin.Context) {
 }
 
 func (h *Handler) CloseReception(c *gin.Context) {
-	role, ok := c.Get(roleCtx)
-	if !ok || role != models.RoleEmployee {
-		c.JSON(http.StatusForbidden, fmt.Errorf("role verification error"))
+	pvzID, err := uuid.Parse(c.Param(pvzIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", pvzIdParam))
 		return
 	}
 
-	pvzIDStr := c.Param(pvzIdParam)
-	pvzID, err := uuid.Parse(pvzIDStr)
+	reception, err := h.services.Reception.CloseActiveReception(currentActor(c), pvzID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("%s parse error", pvzIdParam))
+		abortWithError(c, err)
 		return
 	}
 
-	reception, err := h.services.Reception.CloseActiveReception(pvzID)
+	c.JSON(http.StatusOK, reception)
+}
+
+func (h *Handler) GetReceptions(c *gin.Context) {
+	pvzID, err := uuid.Parse(c.Param(pvzIdParam))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, fmt.Errorf("reception close error: %s", err.Error()))
+		abortWithError(c, apperr.Validation("%s parse error", pvzIdParam))
+		return
+	}
+
+	var q models.GetReceptionsQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		abortWithError(c, apperr.Validation("invalid query parameters"))
 		return
 	}
-	if (reception == models.Reception{}) {
-		c.JSON(http.StatusBadRequest, fmt.Errorf("no active reception for pvz"))
+	if err := h.validate.Struct(&q); err != nil {
+		abortWithError(c, apperr.Validation("invalid query parameters"))
 		return
 	}
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 1 || q.Limit > h.cfg.MaxPageSize {
+		q.Limit = h.cfg.DefaultPageSize
+	}
 
-	c.JSON(http.StatusOK, reception)
+	receptions, err := h.services.Reception.GetReceptions(pvzID, h.can(c, rbac.PVZReadDeactivated), models.ReceptionFilter{
+		Status: q.Status,
+		Start:  q.StartDate,
+		End:    q.EndDate,
+		Limit:  q.Limit,
+		Offset: (q.Page - 1) * q.Limit,
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, receptions)
+}
+
+func (h *Handler) GetReception(c *gin.Context) {
+	receptionID, err := uuid.Parse(c.Param(receptionIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", receptionIdParam))
+		return
+	}
+
+	block, err := h.services.Reception.GetReception(receptionID, h.can(c, rbac.PVZReadDeactivated))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, block)
 }
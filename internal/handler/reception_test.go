@@ -3,10 +3,12 @@ package handler_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"pvz-test/internal/handler"
 	"pvz-test/internal/models"
+	"pvz-test/internal/rbac"
 	"pvz-test/internal/service"
 	"testing"
 
@@ -18,45 +20,77 @@ import (
 
 const roleCtx = "role" // Добавлено определение roleCtx
 
+var (
+	employeeID = uuid.New()
+	employee   = models.Actor{UserID: employeeID, Role: models.RoleEmployee}
+	moderator  = models.Actor{UserID: uuid.New(), Role: models.RoleModerator}
+)
+
 type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) CreateReception(pvzID uuid.UUID) (models.Reception, error) {
-	args := m.Called(pvzID)
+func (m *MockService) CreateReception(actor models.Actor, pvzID uuid.UUID) (models.Reception, error) {
+	args := m.Called(actor, pvzID)
 	return args.Get(0).(models.Reception), args.Error(1)
 }
 
-func (m *MockService) CloseActiveReception(pvzID uuid.UUID) (models.Reception, error) {
-	args := m.Called(pvzID)
+func (m *MockService) CloseActiveReception(actor models.Actor, pvzID uuid.UUID) (models.Reception, error) {
+	args := m.Called(actor, pvzID)
 	return args.Get(0).(models.Reception), args.Error(1)
 }
 
-func (m *MockService) AddItem(pvzID uuid.UUID, itemType string) (models.Item, error) {
-	args := m.Called(pvzID, itemType)
+func (m *MockService) AddItem(actor models.Actor, pvzID uuid.UUID, itemType string, details models.ItemDetails) (models.Item, error) {
+	args := m.Called(actor, pvzID, itemType, details)
 	return args.Get(0).(models.Item), args.Error(1)
 }
 
-func (m *MockService) DeleteItem(pvzID uuid.UUID) error {
-	args := m.Called(pvzID)
+func (m *MockService) AddItems(actor models.Actor, receptionID uuid.UUID, newItems []models.NewItem) (models.BatchAddResult, error) {
+	args := m.Called(actor, receptionID, newItems)
+	return args.Get(0).(models.BatchAddResult), args.Error(1)
+}
+
+func (m *MockService) FindProduct(barcode string, includeDeactivated bool) (models.ProductLocation, error) {
+	args := m.Called(barcode, includeDeactivated)
+	return args.Get(0).(models.ProductLocation), args.Error(1)
+}
+
+func (m *MockService) DeleteItemByID(actor models.Actor, receptionID, itemID uuid.UUID) error {
+	args := m.Called(actor, receptionID, itemID)
 	return args.Error(0)
 }
 
+func (m *MockService) DeleteItem(actor models.Actor, pvzID uuid.UUID) error {
+	args := m.Called(actor, pvzID)
+	return args.Error(0)
+}
+
+func (m *MockService) GetReceptions(pvzID uuid.UUID, includeDeactivated bool, filter models.ReceptionFilter) ([]models.ReceptionBlock, error) {
+	args := m.Called(pvzID, includeDeactivated, filter)
+	return args.Get(0).([]models.ReceptionBlock), args.Error(1)
+}
+
+func (m *MockService) GetReception(receptionID uuid.UUID, includeDeactivated bool) (models.ReceptionBlock, error) {
+	args := m.Called(receptionID, includeDeactivated)
+	return args.Get(0).(models.ReceptionBlock), args.Error(1)
+}
+
 func TestHandler_CreateReception(t *testing.T) {
 	mockService := new(MockService)
-	h := handler.NewHandler(&service.Service{Reception: mockService})
+	h := handler.NewHandler(&service.Service{Reception: mockService}, handler.Config{})
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
 	router.POST("/reception", func(c *gin.Context) {
 		c.Set(roleCtx, models.RoleEmployee)
-		h.CreateReception(c)
-	})
+		c.Set("userId", employeeID)
+	}, h.RequirePermission(rbac.ReceptionWrite), h.CreateReception)
 
 	t.Run("Successful creation", func(t *testing.T) {
 		pvzID := uuid.New()
 		expectedReception := models.Reception{ID: uuid.New(), PVZID: pvzID, Status: "created"}
-		mockService.On("CreateReception", pvzID).Return(expectedReception, nil)
+		mockService.On("CreateReception", employee, pvzID).Return(expectedReception, nil)
 
 		reqBody := models.CreateReceptionRequest{PvzID: pvzID}
 		body, _ := json.Marshal(reqBody)
@@ -80,23 +114,39 @@ func TestHandler_CreateReception(t *testing.T) {
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
+
+	t.Run("PVZ not assigned", func(t *testing.T) {
+		pvzID := uuid.New()
+		mockService.On("CreateReception", employee, pvzID).
+			Return(models.Reception{}, fmt.Errorf("%w: %s", service.ErrPvzNotAssigned, pvzID)).Once()
+
+		body, _ := json.Marshal(models.CreateReceptionRequest{PvzID: pvzID})
+		req, _ := http.NewRequest(http.MethodPost, "/reception", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
 }
 
 func TestHandler_CloseReception(t *testing.T) {
 	mockService := new(MockService)
-	h := handler.NewHandler(&service.Service{Reception: mockService})
+	h := handler.NewHandler(&service.Service{Reception: mockService}, handler.Config{})
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
 	router.POST("/pvz/:pvzId/close_last_reception", func(c *gin.Context) {
 		c.Set(roleCtx, models.RoleEmployee)
-		h.CloseReception(c)
-	})
+		c.Set("userId", employeeID)
+	}, h.RequirePermission(rbac.ReceptionWrite), h.CloseReception)
 
 	t.Run("Successful closure", func(t *testing.T) {
 		pvzID := uuid.New()
 		expectedReception := models.Reception{ID: uuid.New(), PVZID: pvzID, Status: "closed"}
-		mockService.On("CloseActiveReception", pvzID).Return(expectedReception, nil).Once()
+		mockService.On("CloseActiveReception", employee, pvzID).Return(expectedReception, nil).Once()
 
 		req, _ := http.NewRequest(http.MethodPost, "/pvz/"+pvzID.String()+"/close_last_reception", nil)
 		w := httptest.NewRecorder()
@@ -114,6 +164,117 @@ func TestHandler_CloseReception(t *testing.T) {
 
 		router.ServeHTTP(w, req)
 
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+	t.Run("Missing PVZ", func(t *testing.T) {
+		pvzID := uuid.New()
+		mockService.On("CloseActiveReception", employee, pvzID).
+			Return(models.Reception{}, fmt.Errorf("%w: %s", service.ErrPvzNotFound, pvzID)).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/pvz/"+pvzID.String()+"/close_last_reception", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.JSONEq(t, `{"message":"pvz not found: `+pvzID.String()+`"}`, w.Body.String())
+	})
+}
+
+func TestHandler_GetReceptions(t *testing.T) {
+	mockService := new(MockService)
+	h := handler.NewHandler(&service.Service{Reception: mockService}, handler.Config{DefaultPageSize: 10, MaxPageSize: 30})
+	routes := func(r gin.IRoutes) {
+		r.GET("/pvz/:pvzId/receptions", h.GetReceptions)
+	}
+
+	t.Run("Filters and pagination", func(t *testing.T) {
+		pvzID := uuid.New()
+		blocks := []models.ReceptionBlock{{Reception: models.Reception{ID: uuid.New(), PVZID: pvzID, Status: "closed"}}}
+		filter := models.ReceptionFilter{Status: "closed", Limit: 5, Offset: 10}
+		mockService.On("GetReceptions", pvzID, false, filter).Return(blocks, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/pvz/"+pvzID.String()+"/receptions?status=closed&page=3&limit=5", nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, employee, rbac.ReceptionRead, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid status", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/pvz/"+uuid.New().String()+"/receptions?status=open", nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.ReceptionRead, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("PVZ not visible", func(t *testing.T) {
+		pvzID := uuid.New()
+		filter := models.ReceptionFilter{Limit: 10}
+		mockService.On("GetReceptions", pvzID, false, filter).
+			Return([]models.ReceptionBlock(nil), fmt.Errorf("%w: %s", service.ErrPvzNotFound, pvzID)).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/pvz/"+pvzID.String()+"/receptions", nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, employee, rbac.ReceptionRead, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Client forbidden", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/pvz/"+uuid.New().String()+"/receptions", nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, models.Actor{Role: models.RoleClient}, rbac.ReceptionRead, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestHandler_GetReception(t *testing.T) {
+	mockService := new(MockService)
+	h := handler.NewHandler(&service.Service{Reception: mockService}, handler.Config{})
+	routes := func(r gin.IRoutes) {
+		r.GET("/receptions/:receptionId", h.GetReception)
+	}
+
+	t.Run("Found", func(t *testing.T) {
+		receptionID := uuid.New()
+		block := models.ReceptionBlock{
+			Reception: models.Reception{ID: receptionID, PVZID: uuid.New(), Status: "closed"},
+			Products:  []models.Item{{ID: uuid.New(), ReceptionID: receptionID, Type: models.ItemTypeElectronics}},
+		}
+		mockService.On("GetReception", receptionID, true).Return(block, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/receptions/"+receptionID.String(), nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.ReceptionRead, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.ReceptionBlock
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Products, 1)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		receptionID := uuid.New()
+		mockService.On("GetReception", receptionID, false).
+			Return(models.ReceptionBlock{}, fmt.Errorf("%w: %s", service.ErrReceptionNotFound, receptionID)).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/receptions/"+receptionID.String(), nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, employee, rbac.ReceptionRead, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/receptions/not-a-uuid", nil)
+		w := httptest.NewRecorder()
+		newTestRouter(h, employee, rbac.ReceptionRead, routes).ServeHTTP(w, req)
+
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 }
@@ -1,6 +1,10 @@
 package handler
 
 import (
+	"errors"
+	"net/http"
+	"pvz-test/internal/apperr"
+
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
@@ -9,7 +13,56 @@ type errorResponse struct {
 	Message string `json:"message"`
 }
 
-func newErrorResponse(c *gin.Context, statusCode int, message string) {
-	logrus.Errorf("%s", message)
-	c.AbortWithStatusJSON(statusCode, errorResponse{message})
+// ErrorMiddleware renders the last error added with c.Error as an
+// errorResponse. The status code follows the apperr kind, anything else is
+// logged and reported as an internal error.
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		renderError(c)
+	}
+}
+
+// renderError writes the last error of c unless a response was already
+// written.
+func renderError(c *gin.Context) {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+
+	err := c.Errors.Last().Err
+	status := errorStatus(err)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		logrus.Errorf("%s %s: %s", c.Request.Method, c.FullPath(), message)
+		message = http.StatusText(status)
+	}
+	c.JSON(status, errorResponse{message})
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, apperr.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, apperr.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, apperr.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, apperr.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, apperr.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, apperr.ErrUnprocessable):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// abortWithError stops the handler chain and leaves the response to
+// ErrorMiddleware.
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
 }
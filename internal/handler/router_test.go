@@ -0,0 +1,24 @@
+package handler_test
+
+import (
+	"pvz-test/internal/handler"
+	"pvz-test/internal/models"
+	"pvz-test/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter returns a router that serves requests as actor. Routes added
+// by register are guarded by perm.
+func newTestRouter(h *handler.Handler, actor models.Actor, perm rbac.Permission, register func(r gin.IRoutes)) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(handler.ErrorMiddleware())
+	router.Use(func(c *gin.Context) {
+		c.Set("role", actor.Role)
+		c.Set("userId", actor.UserID)
+	})
+	router.Use(h.RequirePermission(perm))
+	register(router)
+	return router
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"net/http"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	webhookIdParam  = "webhookId"
+	deliveryIdParam = "deliveryId"
+)
+
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	var req models.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, apperr.Validation("binding error"))
+		return
+	}
+	if err := h.validate.Struct(&req); err != nil {
+		abortWithError(c, apperr.Validation("url and at least one event type are required"))
+		return
+	}
+
+	webhook, err := h.services.Webhook.CreateWebhook(req)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+func (h *Handler) GetWebhooks(c *gin.Context) {
+	webhooks, err := h.services.Webhook.GetWebhooks()
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	webhookID, err := uuid.Parse(c.Param(webhookIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", webhookIdParam))
+		return
+	}
+
+	if err := h.services.Webhook.DeleteWebhook(webhookID); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) GetWebhookDeliveries(c *gin.Context) {
+	webhookID, err := uuid.Parse(c.Param(webhookIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", webhookIdParam))
+		return
+	}
+
+	var q models.GetDeliveriesQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		abortWithError(c, apperr.Validation("invalid query parameters"))
+		return
+	}
+	if err := h.validate.Struct(&q); err != nil {
+		abortWithError(c, apperr.Validation("invalid delivery status"))
+		return
+	}
+
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 1 || q.Limit > h.cfg.MaxPageSize {
+		q.Limit = h.cfg.DefaultPageSize
+	}
+
+	deliveries, err := h.services.Webhook.GetDeliveries(webhookID, models.DeliveryFilter{
+		Status: q.Status,
+		Limit:  q.Limit,
+		Offset: (q.Page - 1) * q.Limit,
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func (h *Handler) RetryWebhookDelivery(c *gin.Context) {
+	webhookID, err := uuid.Parse(c.Param(webhookIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", webhookIdParam))
+		return
+	}
+	deliveryID, err := uuid.Parse(c.Param(deliveryIdParam))
+	if err != nil {
+		abortWithError(c, apperr.Validation("%s parse error", deliveryIdParam))
+		return
+	}
+
+	delivery, err := h.services.Webhook.RetryDelivery(webhookID, deliveryID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
@@ -0,0 +1,161 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"pvz-test/internal/handler"
+	"pvz-test/internal/models"
+	"pvz-test/internal/rbac"
+	"pvz-test/internal/service"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) CreateWebhook(req models.WebhookRequest) (models.Webhook, error) {
+	args := m.Called(req)
+	return args.Get(0).(models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) GetWebhooks() ([]models.Webhook, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteWebhook(webhookID uuid.UUID) error {
+	args := m.Called(webhookID)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) GetDeliveries(webhookID uuid.UUID, filter models.DeliveryFilter) ([]models.WebhookDelivery, error) {
+	args := m.Called(webhookID, filter)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) RetryDelivery(webhookID, deliveryID uuid.UUID) (models.WebhookDelivery, error) {
+	args := m.Called(webhookID, deliveryID)
+	return args.Get(0).(models.WebhookDelivery), args.Error(1)
+}
+
+func TestHandler_CreateWebhook(t *testing.T) {
+	mockService := new(MockWebhookService)
+	h := handler.NewHandler(&service.Service{Webhook: mockService}, handler.Config{})
+	routes := func(r gin.IRoutes) {
+		r.POST("/webhooks", h.CreateWebhook)
+	}
+
+	t.Run("Employee is forbidden", func(t *testing.T) {
+		body, _ := json.Marshal(models.WebhookRequest{URL: "https://partner.example/hooks", EventTypes: []models.EventType{models.EventReceptionClosed}})
+		req, _ := http.NewRequest(http.MethodPost, "/webhooks", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		newTestRouter(h, employee, rbac.WebhookManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Created", func(t *testing.T) {
+		request := models.WebhookRequest{URL: "https://partner.example/hooks", EventTypes: []models.EventType{models.EventReceptionClosed}}
+		mockService.On("CreateWebhook", request).Return(models.Webhook{ID: uuid.New(), Secret: "secret"}, nil).Once()
+
+		body, _ := json.Marshal(request)
+		req, _ := http.NewRequest(http.MethodPost, "/webhooks", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.WebhookManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var webhook models.Webhook
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &webhook))
+		assert.Equal(t, "secret", webhook.Secret)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("No event types", func(t *testing.T) {
+		body, _ := json.Marshal(models.WebhookRequest{URL: "https://partner.example/hooks"})
+		req, _ := http.NewRequest(http.MethodPost, "/webhooks", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.WebhookManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Invalid url", func(t *testing.T) {
+		body, _ := json.Marshal(models.WebhookRequest{URL: "partner", EventTypes: []models.EventType{models.EventReceptionClosed}})
+		req, _ := http.NewRequest(http.MethodPost, "/webhooks", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		newTestRouter(h, moderator, rbac.WebhookManage, routes).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_GetWebhookDeliveries(t *testing.T) {
+	mockService := new(MockWebhookService)
+	h := handler.NewHandler(&service.Service{Webhook: mockService}, handler.Config{DefaultPageSize: 10, MaxPageSize: 30})
+	routes := func(r gin.IRoutes) {
+		r.GET("/webhooks/:webhookId/deliveries", h.GetWebhookDeliveries)
+		r.POST("/webhooks/:webhookId/deliveries/:deliveryId/retry", h.RetryWebhookDelivery)
+	}
+	router := newTestRouter(h, moderator, rbac.WebhookManage, routes)
+
+	t.Run("Dead deliveries", func(t *testing.T) {
+		webhookID := uuid.New()
+		filter := models.DeliveryFilter{Status: models.DeliveryStatusDead, Limit: 10, Offset: 10}
+		deliveries := []models.WebhookDelivery{{ID: uuid.New(), WebhookID: webhookID, Status: models.DeliveryStatusDead}}
+		mockService.On("GetDeliveries", webhookID, filter).Return(deliveries, nil).Once()
+
+		req, _ := http.NewRequest(http.MethodGet, "/webhooks/"+webhookID.String()+"/deliveries?status=dead&page=2", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid status", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/webhooks/"+uuid.New().String()+"/deliveries?status=failed", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Retry of a delivery that is not dead", func(t *testing.T) {
+		webhookID, deliveryID := uuid.New(), uuid.New()
+		mockService.On("RetryDelivery", webhookID, deliveryID).
+			Return(models.WebhookDelivery{}, fmt.Errorf("%w: %s", service.ErrDeadDeliveryNotFound, deliveryID)).Once()
+
+		req, _ := http.NewRequest(http.MethodPost, "/webhooks/"+webhookID.String()+"/deliveries/"+deliveryID.String()+"/retry", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestHandler_DeleteWebhook(t *testing.T) {
+	mockService := new(MockWebhookService)
+	h := handler.NewHandler(&service.Service{Webhook: mockService}, handler.Config{})
+	routes := func(r gin.IRoutes) {
+		r.DELETE("/webhooks/:webhookId", h.DeleteWebhook)
+	}
+
+	webhookID := uuid.New()
+	mockService.On("DeleteWebhook", webhookID).Return(nil).Once()
+
+	req, _ := http.NewRequest(http.MethodDelete, "/webhooks/"+webhookID.String(), nil)
+	w := httptest.NewRecorder()
+	newTestRouter(h, moderator, rbac.WebhookManage, routes).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
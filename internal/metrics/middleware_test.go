@@ -0,0 +1,43 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"pvz-test/internal/metrics"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGinMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(metrics.GinMiddleware())
+	router.GET("/pvz/:pvzId", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	t.Run("Known route", func(t *testing.T) {
+		before := testutil.ToFloat64(metrics.HTTPRequestsTotal.WithLabelValues(http.MethodGet, "/pvz/:pvzId", "200"))
+
+		req, _ := http.NewRequest(http.MethodGet, "/pvz/some-id", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		after := testutil.ToFloat64(metrics.HTTPRequestsTotal.WithLabelValues(http.MethodGet, "/pvz/:pvzId", "200"))
+		assert.Equal(t, before+1, after)
+	})
+
+	t.Run("Unknown route", func(t *testing.T) {
+		before := testutil.ToFloat64(metrics.HTTPRequestsTotal.WithLabelValues(http.MethodGet, "unknown", "404"))
+
+		req, _ := http.NewRequest(http.MethodGet, "/missing", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		after := testutil.ToFloat64(metrics.HTTPRequestsTotal.WithLabelValues(http.MethodGet, "unknown", "404"))
+		assert.Equal(t, before+1, after)
+	})
+}
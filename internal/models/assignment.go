@@ -0,0 +1,13 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EmployeeAssignment struct {
+	UserID     uuid.UUID `json:"userId" db:"user_id"`
+	PVZID      uuid.UUID `json:"pvzId" db:"pvz_id"`
+	AssignedAt time.Time `json:"assignedAt" db:"assigned_at"`
+}
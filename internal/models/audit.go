@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actor is the authenticated caller behind a mutation.
+type Actor struct {
+	UserID uuid.UUID
+	Role   Role
+}
+
+type AuditAction string
+
+const (
+	AuditPVZCreated       AuditAction = "pvz.created"
+	AuditPVZUpdated       AuditAction = "pvz.updated"
+	AuditPVZDeactivated   AuditAction = "pvz.deactivated"
+	AuditReceptionCreated AuditAction = "reception.created"
+	AuditReceptionClosed  AuditAction = "reception.closed"
+	AuditProductAdded     AuditAction = "product.added"
+	AuditProductDeleted   AuditAction = "product.deleted"
+)
+
+// EntityType is the part of the action before the dot.
+func (a AuditAction) EntityType() string {
+	entity, _, _ := strings.Cut(string(a), ".")
+	return entity
+}
+
+type AuditEvent struct {
+	ID         uuid.UUID        `json:"id" db:"id"`
+	ActorID    uuid.UUID        `json:"actorId" db:"actor_id"`
+	ActorRole  Role             `json:"actorRole" db:"actor_role"`
+	Action     AuditAction      `json:"action" db:"action"`
+	EntityType string           `json:"entityType" db:"entity_type"`
+	EntityID   uuid.UUID        `json:"entityId" db:"entity_id"`
+	PVZID      uuid.UUID        `json:"pvzId" db:"pvz_id"`
+	Before     *json.RawMessage `json:"before,omitempty" db:"before"`
+	After      *json.RawMessage `json:"after,omitempty" db:"after"`
+	CreatedAt  time.Time        `json:"createdAt" db:"created_at"`
+}
+
+type AuditFilter struct {
+	ActorID  *uuid.UUID
+	PVZID    *uuid.UUID
+	EntityID *uuid.UUID
+	Action   AuditAction
+	Start    *time.Time
+	End      *time.Time
+	Limit    int
+	Offset   int
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+const maxCode128Length = 48
+
+// ValidateBarcode accepts EAN-13 codes and Code 128 payloads. A code of 13
+// digits is read as EAN-13 and must have a valid check digit, anything else
+// must be printable ASCII short enough for a Code 128 label.
+func ValidateBarcode(code string) error {
+	if code == "" {
+		return errors.New("must not be empty")
+	}
+	if len(code) == 13 && isDigits(code) {
+		if !validEAN13(code) {
+			return errors.New("invalid EAN-13 check digit")
+		}
+		return nil
+	}
+	if len(code) > maxCode128Length {
+		return fmt.Errorf("must be at most %d characters", maxCode128Length)
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < ' ' || code[i] > '~' {
+			return errors.New("must be an EAN-13 code or printable ASCII")
+		}
+	}
+	return nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// validEAN13 checks the last digit against the others weighted 1 and 3
+// from the left.
+func validEAN13(code string) bool {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		digit := int(code[i] - '0')
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return (10-sum%10)%10 == int(code[12]-'0')
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	EventPVZCreated      EventType = "PVZCreated"
+	EventReceptionOpened EventType = "ReceptionOpened"
+	EventReceptionClosed EventType = "ReceptionClosed"
+	EventProductAdded    EventType = "ProductAdded"
+	EventProductRemoved  EventType = "ProductRemoved"
+)
+
+// Event is a domain event taken from the outbox. AggregateID is the PVZ,
+// reception or product the event is about, Payload is its state after the
+// change (before it for ProductRemoved).
+type Event struct {
+	ID          uuid.UUID       `json:"id" db:"id"`
+	Type        EventType       `json:"type" db:"event_type"`
+	AggregateID uuid.UUID       `json:"aggregateId" db:"aggregate_id"`
+	PVZID       uuid.UUID       `json:"pvzId" db:"pvz_id"`
+	Payload     json.RawMessage `json:"payload" db:"payload"`
+	OccurredAt  time.Time       `json:"occurredAt" db:"created_at"`
+	Attempts    int             `json:"-" db:"attempts"`
+}
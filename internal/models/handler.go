@@ -23,24 +23,86 @@ type UserResponse struct {
 	Role  string    `json:"role"`
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type CityRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
 type DummyLoginRequest struct {
-	Role Role `json:"role" validate:"required,oneof=employee moderator client"`
+	Role Role `json:"role" validate:"required"`
 }
 
 type PVZRequest struct {
-	City string `json:"city" validate:"required,oneof=Москва Санкт-Петербург Казань"`
+	City string `json:"city" validate:"required"`
 }
 
 type AddProductRequest struct {
-	Type  string    `json:"type" binding:"required"`
-	PvzID uuid.UUID `json:"pvzId" binding:"required"`
+	Type        string    `json:"type" binding:"required"`
+	PvzID       uuid.UUID `json:"pvzId" binding:"required"`
+	Barcode     *string   `json:"barcode"`
+	SKU         *string   `json:"sku" binding:"omitempty,max=64"`
+	Description *string   `json:"description" binding:"omitempty,max=1000"`
+}
+
+// BatchProduct fields are validated per product by the service, so that one
+// bad product does not reject the whole batch.
+type BatchProduct struct {
+	Type        string  `json:"type"`
+	Barcode     *string `json:"barcode"`
+	SKU         *string `json:"sku"`
+	Description *string `json:"description"`
+}
+
+type AddProductsBatchRequest struct {
+	Products []BatchProduct `json:"products" binding:"required,min=1"`
+}
+
+type UpdatePVZRequest struct {
+	City string `json:"city" validate:"required"`
 }
 
 type GetPVZListQuery struct {
-	StartDate *time.Time `form:"startDate"`
-	EndDate   *time.Time `form:"endDate"`
-	Page      int        `form:"page"`
-	Limit     int        `form:"limit"`
+	StartDate    *time.Time `form:"startDate"`
+	EndDate      *time.Time `form:"endDate"`
+	IncludeEmpty bool       `form:"includeEmpty"`
+	Status       PVZStatus  `form:"status" validate:"omitempty,oneof=active deactivated all"`
+	Page         int        `form:"page"`
+	Limit        int        `form:"limit"`
+}
+
+type GetReceptionsQuery struct {
+	StartDate *time.Time      `form:"startDate"`
+	EndDate   *time.Time      `form:"endDate"`
+	Status    ReceptionStatus `form:"status" validate:"omitempty,oneof=in_progress closed"`
+	Page      int             `form:"page"`
+	Limit     int             `form:"limit"`
+}
+
+type GetAuditQuery struct {
+	ActorID   string      `form:"actorId"`
+	PVZID     string      `form:"pvzId"`
+	EntityID  string      `form:"entityId"`
+	Action    AuditAction `form:"action"`
+	StartDate *time.Time  `form:"startDate"`
+	EndDate   *time.Time  `form:"endDate"`
+	Page      int         `form:"page"`
+	Limit     int         `form:"limit"`
+}
+
+type GetDeliveriesQuery struct {
+	Status DeliveryStatus `form:"status" validate:"omitempty,oneof=pending delivered dead"`
+	Page   int            `form:"page"`
+	Limit  int            `form:"limit"`
+}
+
+type WebhookRequest struct {
+	URL        string      `json:"url" validate:"required,url"`
+	EventTypes []EventType `json:"eventTypes" validate:"required,min=1"`
+	PVZID      *uuid.UUID  `json:"pvzId"`
+	City       *string     `json:"city"`
 }
 
 type PVZResponse struct {
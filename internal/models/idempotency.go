@@ -0,0 +1,24 @@
+package models
+
+import "github.com/google/uuid"
+
+// IdempotencyKey identifies a client retry: the same key sent by the same
+// user to the same route.
+type IdempotencyKey struct {
+	UserID uuid.UUID
+	Route  string
+	Key    string
+}
+
+type StoredResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// IdempotencyRecord is the stored state of a key. Response is nil while the
+// first request is in flight.
+type IdempotencyRecord struct {
+	RequestHash string
+	Response    *StoredResponse
+}
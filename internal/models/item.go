@@ -19,4 +19,48 @@ type Item struct {
 	ReceptionID uuid.UUID `db:"reception_id" json:"receptionId"`
 	Type        ItemType  `db:"type" json:"type"`
 	AddedAt     time.Time `db:"added_at" json:"dateTime"`
+	ItemDetails
+}
+
+// ItemDetails identifies the physical parcel behind an item. All fields are
+// optional.
+type ItemDetails struct {
+	Barcode     *string `db:"barcode" json:"barcode,omitempty"`
+	SKU         *string `db:"sku" json:"sku,omitempty"`
+	Description *string `db:"description" json:"description,omitempty"`
+}
+
+// NewItem is an item to be added to a reception.
+type NewItem struct {
+	Type ItemType
+	ItemDetails
+}
+
+func (t ItemType) Valid() bool {
+	switch t {
+	case ItemTypeElectronics, ItemTypeClothing, ItemTypeShoes:
+		return true
+	}
+	return false
+}
+
+// BatchItemResult is the outcome of one product of a batch. Index points at
+// the product in the request.
+type BatchItemResult struct {
+	Index   int    `json:"index"`
+	Product *Item  `json:"product,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type BatchAddResult struct {
+	Added   int               `json:"added"`
+	Failed  int               `json:"failed"`
+	Results []BatchItemResult `json:"results"`
+}
+
+// ProductLocation tells where a parcel was received last.
+type ProductLocation struct {
+	Product   Item      `json:"product"`
+	Reception Reception `json:"reception"`
+	PVZ       PVZ       `json:"pvz"`
 }
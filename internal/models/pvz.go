@@ -7,7 +7,20 @@ import (
 )
 
 type PVZ struct {
-	ID               uuid.UUID `json:"id" db:"id"`
-	RegistrationDate time.Time `json:"registrationDate" db:"registration_date"`
-	City             string    `json:"city" db:"city"`
+	ID               uuid.UUID  `json:"id" db:"id"`
+	RegistrationDate time.Time  `json:"registrationDate" db:"registration_date"`
+	City             string     `json:"city" db:"city"`
+	DeactivatedAt    *time.Time `json:"deactivatedAt,omitempty" db:"deactivated_at"`
+}
+
+type PVZStatus string
+
+const (
+	PVZStatusActive      PVZStatus = "active"
+	PVZStatusDeactivated PVZStatus = "deactivated"
+	PVZStatusAll         PVZStatus = "all"
+)
+
+func (p PVZ) Active() bool {
+	return p.DeactivatedAt == nil
 }
@@ -6,14 +6,29 @@ import (
 	"github.com/google/uuid"
 )
 
+type ReceptionStatus string
+
+const (
+	ReceptionStatusInProgress ReceptionStatus = "in_progress"
+	ReceptionStatusClosed     ReceptionStatus = "closed"
+)
+
 type Reception struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	PVZID     uuid.UUID `json:"pvzId" db:"pvz_id"`
-	Status    string    `json:"status" db:"status"`
-	CreatedAt time.Time `json:"dateTime" db:"created_at"`
+	ID        uuid.UUID       `json:"id" db:"id"`
+	PVZID     uuid.UUID       `json:"pvzId" db:"pvz_id"`
+	Status    ReceptionStatus `json:"status" db:"status"`
+	CreatedAt time.Time       `json:"dateTime" db:"created_at"`
 }
 
 type ReceptionBlock struct {
 	Reception Reception `json:"reception"`
 	Products  []Item    `json:"products"`
 }
+
+type ReceptionFilter struct {
+	Status ReceptionStatus
+	Start  *time.Time
+	End    *time.Time
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook is a partner subscription to domain events. Secret signs the
+// deliveries and is only returned when the subscription is created.
+type Webhook struct {
+	ID         uuid.UUID   `json:"id"`
+	URL        string      `json:"url"`
+	Secret     string      `json:"secret,omitempty"`
+	EventTypes []EventType `json:"eventTypes"`
+	PVZID      *uuid.UUID  `json:"pvzId,omitempty"`
+	City       *string     `json:"city,omitempty"`
+	CreatedAt  time.Time   `json:"createdAt"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	DeliveryStatusDead      DeliveryStatus = "dead"
+)
+
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	WebhookID      uuid.UUID       `json:"webhookId" db:"webhook_id"`
+	EventID        uuid.UUID       `json:"eventId" db:"event_id"`
+	EventType      EventType       `json:"eventType" db:"event_type"`
+	Status         DeliveryStatus  `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	ResponseStatus *int            `json:"responseStatus,omitempty" db:"response_status"`
+	LastError      *string         `json:"lastError,omitempty" db:"last_error"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt" db:"next_attempt_at"`
+	CreatedAt      time.Time       `json:"createdAt" db:"created_at"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty" db:"delivered_at"`
+	Payload        json.RawMessage `json:"-" db:"payload"`
+}
+
+// WebhookAttempt is a claimed delivery with the subscription it goes to.
+type WebhookAttempt struct {
+	WebhookDelivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+type DeliveryFilter struct {
+	Status DeliveryStatus
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,127 @@
+// Package rbac maps roles to the permissions that routes require.
+package rbac
+
+import (
+	"fmt"
+	"pvz-test/internal/models"
+	"sort"
+)
+
+type Permission string
+
+const (
+	PVZCreate          Permission = "pvz:create"
+	PVZRead            Permission = "pvz:read"
+	PVZManage          Permission = "pvz:manage"
+	PVZReadDeactivated Permission = "pvz:read_deactivated"
+	ReceptionRead      Permission = "reception:read"
+	ReceptionWrite     Permission = "reception:write"
+	ProductWrite       Permission = "product:write"
+	CityManage         Permission = "city:manage"
+	StaffManage        Permission = "staff:manage"
+	AuditRead          Permission = "audit:read"
+	WebhookManage      Permission = "webhook:manage"
+)
+
+var knownPermissions = map[Permission]struct{}{
+	PVZCreate:          {},
+	PVZRead:            {},
+	PVZManage:          {},
+	PVZReadDeactivated: {},
+	ReceptionRead:      {},
+	ReceptionWrite:     {},
+	ProductWrite:       {},
+	CityManage:         {},
+	StaffManage:        {},
+	AuditRead:          {},
+	WebhookManage:      {},
+}
+
+// DefaultRoles is the built-in role table. Roles from the configuration are
+// applied on top of it.
+func DefaultRoles() map[models.Role][]Permission {
+	return map[models.Role][]Permission{
+		models.RoleEmployee: {
+			PVZRead,
+			ReceptionRead,
+			ReceptionWrite,
+			ProductWrite,
+		},
+		models.RoleModerator: {
+			PVZCreate,
+			PVZRead,
+			PVZManage,
+			PVZReadDeactivated,
+			ReceptionRead,
+			CityManage,
+			StaffManage,
+			AuditRead,
+			WebhookManage,
+		},
+		models.RoleClient: {},
+	}
+}
+
+type Policy struct {
+	roles map[models.Role]map[Permission]struct{}
+}
+
+// NewPolicy builds a policy from a role table. Unknown permissions are
+// rejected so that a typo in the configuration does not silently lock a role
+// out.
+func NewPolicy(roles map[models.Role][]Permission) (*Policy, error) {
+	p := &Policy{roles: make(map[models.Role]map[Permission]struct{}, len(roles))}
+	for role, perms := range roles {
+		set := make(map[Permission]struct{}, len(perms))
+		for _, perm := range perms {
+			if _, ok := knownPermissions[perm]; !ok {
+				return nil, fmt.Errorf("role %s: unknown permission %q", role, perm)
+			}
+			set[perm] = struct{}{}
+		}
+		p.roles[role] = set
+	}
+	return p, nil
+}
+
+func DefaultPolicy() *Policy {
+	p, err := NewPolicy(DefaultRoles())
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// FromConfig builds a policy from DefaultRoles with the configured roles
+// replacing or adding entries.
+func FromConfig(roles map[string][]string) (*Policy, error) {
+	table := DefaultRoles()
+	for role, perms := range roles {
+		converted := make([]Permission, 0, len(perms))
+		for _, perm := range perms {
+			converted = append(converted, Permission(perm))
+		}
+		table[models.Role(role)] = converted
+	}
+	return NewPolicy(table)
+}
+
+func (p *Policy) Allows(role models.Role, perm Permission) bool {
+	_, ok := p.roles[role][perm]
+	return ok
+}
+
+// HasRole reports whether the role is known to the policy.
+func (p *Policy) HasRole(role models.Role) bool {
+	_, ok := p.roles[role]
+	return ok
+}
+
+func (p *Policy) Permissions(role models.Role) []Permission {
+	perms := make([]Permission, 0, len(p.roles[role]))
+	for perm := range p.roles[role] {
+		perms = append(perms, perm)
+	}
+	sort.Slice(perms, func(i, j int) bool { return perms[i] < perms[j] })
+	return perms
+}
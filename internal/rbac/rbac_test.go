@@ -0,0 +1,51 @@
+package rbac_test
+
+import (
+	"pvz-test/internal/models"
+	"pvz-test/internal/rbac"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	policy := rbac.DefaultPolicy()
+
+	assert.True(t, policy.Allows(models.RoleModerator, rbac.PVZCreate))
+	assert.False(t, policy.Allows(models.RoleEmployee, rbac.PVZCreate))
+	assert.True(t, policy.Allows(models.RoleEmployee, rbac.ReceptionWrite))
+	assert.False(t, policy.Allows(models.RoleModerator, rbac.ReceptionWrite))
+	assert.False(t, policy.Allows(models.RoleClient, rbac.PVZRead))
+	assert.False(t, policy.Allows("unknown", rbac.PVZRead))
+}
+
+func TestFromConfig(t *testing.T) {
+	t.Run("New role", func(t *testing.T) {
+		policy, err := rbac.FromConfig(map[string][]string{
+			"auditor": {"pvz:read", "reception:read", "pvz:read_deactivated"},
+		})
+		require.NoError(t, err)
+
+		assert.True(t, policy.HasRole("auditor"))
+		assert.True(t, policy.Allows("auditor", rbac.ReceptionRead))
+		assert.False(t, policy.Allows("auditor", rbac.ReceptionWrite))
+		assert.True(t, policy.Allows(models.RoleModerator, rbac.PVZCreate))
+	})
+
+	t.Run("Override built-in role", func(t *testing.T) {
+		policy, err := rbac.FromConfig(map[string][]string{
+			"employee": {"pvz:read"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []rbac.Permission{rbac.PVZRead}, policy.Permissions(models.RoleEmployee))
+	})
+
+	t.Run("Unknown permission", func(t *testing.T) {
+		_, err := rbac.FromConfig(map[string][]string{
+			"auditor": {"pvz:delete"},
+		})
+		assert.ErrorContains(t, err, `unknown permission "pvz:delete"`)
+	})
+}
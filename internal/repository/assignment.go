@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"fmt"
+	"pvz-test/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type AssignmentPostgres struct {
+	db *sqlx.DB
+}
+
+func NewAssignmentPostgres(db *sqlx.DB) *AssignmentPostgres {
+	return &AssignmentPostgres{db: db}
+}
+
+// AssignEmployee is idempotent: assigning an employee twice keeps the original
+// assigned_at.
+func (r *AssignmentPostgres) AssignEmployee(userID, pvzID uuid.UUID) (models.EmployeeAssignment, error) {
+	var assignment models.EmployeeAssignment
+	err := r.db.Get(&assignment, `
+		INSERT INTO employee_pvz (user_id, pvz_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, pvz_id) DO UPDATE SET user_id = EXCLUDED.user_id
+		RETURNING user_id, pvz_id, assigned_at
+	`, userID, pvzID)
+	if err != nil {
+		return models.EmployeeAssignment{}, fmt.Errorf("failed to assign user %s to pvz %s: %w", userID.String(), pvzID.String(), err)
+	}
+	return assignment, nil
+}
+
+func (r *AssignmentPostgres) UnassignEmployee(userID, pvzID uuid.UUID) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM employee_pvz WHERE user_id = $1 AND pvz_id = $2`, userID, pvzID)
+	if err != nil {
+		return false, fmt.Errorf("failed to unassign user %s from pvz %s: %w", userID.String(), pvzID.String(), err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *AssignmentPostgres) IsAssigned(userID, pvzID uuid.UUID) (bool, error) {
+	var assigned bool
+	err := r.db.Get(&assigned, `
+		SELECT EXISTS(SELECT 1 FROM employee_pvz WHERE user_id = $1 AND pvz_id = $2)
+	`, userID, pvzID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check assignment of user %s to pvz %s: %w", userID.String(), pvzID.String(), err)
+	}
+	return assigned, nil
+}
+
+func (r *AssignmentPostgres) GetPVZEmployees(pvzID uuid.UUID) ([]models.EmployeeAssignment, error) {
+	assignments := []models.EmployeeAssignment{}
+	err := r.db.Select(&assignments, `
+		SELECT user_id, pvz_id, assigned_at
+		FROM employee_pvz
+		WHERE pvz_id = $1
+		ORDER BY assigned_at
+	`, pvzID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get employees of pvz %s: %w", pvzID.String(), err)
+	}
+	return assignments, nil
+}
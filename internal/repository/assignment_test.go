@@ -0,0 +1,81 @@
+package repository_test
+
+import (
+	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignmentPostgres_AssignEmployee(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAssignmentPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	expected := models.EmployeeAssignment{UserID: uuid.New(), PVZID: uuid.New(), AssignedAt: time.Now()}
+	mock.ExpectQuery(`INSERT INTO employee_pvz \(user_id, pvz_id\) VALUES \(\$1, \$2\) ON CONFLICT \(user_id, pvz_id\) DO UPDATE`).
+		WithArgs(expected.UserID, expected.PVZID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "pvz_id", "assigned_at"}).
+			AddRow(expected.UserID, expected.PVZID, expected.AssignedAt))
+
+	assignment, err := repo.AssignEmployee(expected.UserID, expected.PVZID)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, assignment)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAssignmentPostgres_UnassignEmployee(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAssignmentPostgres(sqlx.NewDb(db, "sqlmock"))
+	userID, pvzID := uuid.New(), uuid.New()
+
+	t.Run("Assignment removed", func(t *testing.T) {
+		mock.ExpectExec(`DELETE FROM employee_pvz WHERE user_id = \$1 AND pvz_id = \$2`).
+			WithArgs(userID, pvzID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		removed, err := repo.UnassignEmployee(userID, pvzID)
+		assert.NoError(t, err)
+		assert.True(t, removed)
+	})
+
+	t.Run("Not assigned", func(t *testing.T) {
+		mock.ExpectExec(`DELETE FROM employee_pvz`).
+			WithArgs(userID, pvzID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		removed, err := repo.UnassignEmployee(userID, pvzID)
+		assert.NoError(t, err)
+		assert.False(t, removed)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAssignmentPostgres_IsAssigned(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAssignmentPostgres(sqlx.NewDb(db, "sqlmock"))
+	userID, pvzID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM employee_pvz WHERE user_id = \$1 AND pvz_id = \$2\)`).
+		WithArgs(userID, pvzID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	assigned, err := repo.IsAssigned(userID, pvzID)
+	assert.NoError(t, err)
+	assert.True(t, assigned)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
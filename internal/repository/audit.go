@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"pvz-test/internal/models"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type AuditPostgres struct {
+	db *sqlx.DB
+}
+
+func NewAuditPostgres(db *sqlx.DB) *AuditPostgres {
+	return &AuditPostgres{db: db}
+}
+
+func (r *AuditPostgres) GetAuditEvents(filter models.AuditFilter) ([]models.AuditEvent, error) {
+	query := sq.
+		Select("id", "actor_id", "actor_role", "action", "entity_type", "entity_id", "pvz_id", "before", "after", "created_at").
+		From("audit_events").
+		OrderBy("created_at DESC")
+
+	if filter.ActorID != nil {
+		query = query.Where(sq.Eq{"actor_id": *filter.ActorID})
+	}
+	if filter.PVZID != nil {
+		query = query.Where(sq.Eq{"pvz_id": *filter.PVZID})
+	}
+	if filter.EntityID != nil {
+		query = query.Where(sq.Eq{"entity_id": *filter.EntityID})
+	}
+	if filter.Action != "" {
+		query = query.Where(sq.Eq{"action": filter.Action})
+	}
+	if filter.Start != nil {
+		query = query.Where(sq.GtOrEq{"created_at": *filter.Start})
+	}
+	if filter.End != nil {
+		query = query.Where(sq.LtOrEq{"created_at": *filter.End})
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(uint64(filter.Limit)).Offset(uint64(filter.Offset))
+	}
+
+	sqlQuery, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	events := []models.AuditEvent{}
+	if err := r.db.Select(&events, sqlQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to get audit events: %w", err)
+	}
+	return events, nil
+}
+
+// insertAuditEvent records a mutation inside the transaction that performs
+// it, so the event and the change are committed or rolled back together.
+// before and after are stored as JSON, nil values as NULL.
+func insertAuditEvent(tx *sqlx.Tx, actor models.Actor, action models.AuditAction, entityID, pvzID uuid.UUID, before, after interface{}) error {
+	beforeJSON, err := auditState(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := auditState(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO audit_events (actor_id, actor_role, action, entity_type, entity_id, pvz_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, actor.UserID, actor.Role, action, action.EntityType(), entityID, pvzID, beforeJSON, afterJSON)
+	if err != nil {
+		return fmt.Errorf("failed to write audit event %s: %w", action, err)
+	}
+	return nil
+}
+
+func auditState(state interface{}) (interface{}, error) {
+	if state == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit state: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,80 @@
+package repository_test
+
+import (
+	"encoding/json"
+	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var testActor = models.Actor{UserID: uuid.New(), Role: models.RoleEmployee}
+
+// expectAudit expects the audit event that a mutation writes before commit.
+func expectAudit(mock sqlmock.Sqlmock, action models.AuditAction, entityID, pvzID interface{}) *sqlmock.ExpectedExec {
+	return mock.ExpectExec(`INSERT INTO audit_events \(actor_id, actor_role, action, entity_type, entity_id, pvz_id, before, after\)`).
+		WithArgs(testActor.UserID, testActor.Role, action, action.EntityType(), entityID, pvzID, sqlmock.AnyArg(), sqlmock.AnyArg())
+}
+
+func TestAuditPostgres_GetAuditEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAuditPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	columns := []string{"id", "actor_id", "actor_role", "action", "entity_type", "entity_id", "pvz_id", "before", "after", "created_at"}
+
+	t.Run("All filters", func(t *testing.T) {
+		actorID, pvzID := uuid.New(), uuid.New()
+		start := time.Now().Add(-time.Hour)
+		itemID := uuid.New()
+		before := json.RawMessage(`{"id":"` + itemID.String() + `"}`)
+		expected := models.AuditEvent{
+			ID:         uuid.New(),
+			ActorID:    actorID,
+			ActorRole:  models.RoleEmployee,
+			Action:     models.AuditProductDeleted,
+			EntityType: "product",
+			EntityID:   itemID,
+			PVZID:      pvzID,
+			Before:     &before,
+			CreatedAt:  time.Now(),
+		}
+
+		mock.ExpectQuery(`SELECT id, actor_id, actor_role, action, entity_type, entity_id, pvz_id, before, after, created_at FROM audit_events `+
+			`WHERE actor_id = \$1 AND pvz_id = \$2 AND action = \$3 AND created_at >= \$4 ORDER BY created_at DESC LIMIT 10 OFFSET 20`).
+			WithArgs(actorID, pvzID, models.AuditProductDeleted, start).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(
+				expected.ID, expected.ActorID, expected.ActorRole, expected.Action, expected.EntityType,
+				expected.EntityID, expected.PVZID, []byte(before), nil, expected.CreatedAt))
+
+		events, err := repo.GetAuditEvents(models.AuditFilter{
+			ActorID: &actorID,
+			PVZID:   &pvzID,
+			Action:  models.AuditProductDeleted,
+			Start:   &start,
+			Limit:   10,
+			Offset:  20,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.AuditEvent{expected}, events)
+	})
+
+	t.Run("No events", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT .* FROM audit_events ORDER BY created_at DESC`).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		events, err := repo.GetAuditEvents(models.AuditFilter{})
+		assert.NoError(t, err)
+		assert.Empty(t, events)
+		assert.NotNil(t, events)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
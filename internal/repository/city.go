@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrCityExists = apperr.New(apperr.ErrConflict, "city already exists")
+	ErrCityInUse  = apperr.New(apperr.ErrConflict, "city has pvz or webhooks")
+	// ErrCityNotSupported is returned when a row references a city missing
+	// from the catalog.
+	ErrCityNotSupported = apperr.New(apperr.ErrValidation, "city is not supported")
+)
+
+const (
+	pqUniqueViolation     = "23505"
+	pqForeignKeyViolation = "23503"
+)
+
+type CityPostgres struct {
+	db *sqlx.DB
+}
+
+func NewCityPostgres(db *sqlx.DB) *CityPostgres {
+	return &CityPostgres{db: db}
+}
+
+func (r *CityPostgres) CreateCity(name string) (models.City, error) {
+	var city models.City
+	err := r.db.Get(&city, `
+		INSERT INTO cities (name)
+		VALUES ($1)
+		RETURNING id, name, created_at
+	`, name)
+	if err != nil {
+		if isPqError(err, pqUniqueViolation) {
+			return models.City{}, ErrCityExists
+		}
+		return models.City{}, fmt.Errorf("failed to create city: %w", err)
+	}
+	return city, nil
+}
+
+func (r *CityPostgres) GetCities() ([]models.City, error) {
+	var cities []models.City
+	err := r.db.Select(&cities, `
+		SELECT id, name, created_at
+		FROM cities
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cities: %w", err)
+	}
+	return cities, nil
+}
+
+func (r *CityPostgres) GetCityByID(cityID uuid.UUID) (models.City, error) {
+	var city models.City
+	err := r.db.Get(&city, `
+		SELECT id, name, created_at
+		FROM cities
+		WHERE id = $1
+	`, cityID)
+	if err == sql.ErrNoRows {
+		return models.City{}, nil
+	}
+	if err != nil {
+		return models.City{}, fmt.Errorf("failed to get city %s: %w", cityID.String(), err)
+	}
+	return city, nil
+}
+
+// UpdateCity renames a city. PVZs follow the new name through ON UPDATE CASCADE.
+func (r *CityPostgres) UpdateCity(cityID uuid.UUID, name string) (models.City, error) {
+	var city models.City
+	err := r.db.Get(&city, `
+		UPDATE cities
+		SET name = $2
+		WHERE id = $1
+		RETURNING id, name, created_at
+	`, cityID, name)
+	if err == sql.ErrNoRows {
+		return models.City{}, nil
+	}
+	if err != nil {
+		if isPqError(err, pqUniqueViolation) {
+			return models.City{}, ErrCityExists
+		}
+		return models.City{}, fmt.Errorf("failed to update city %s: %w", cityID.String(), err)
+	}
+	return city, nil
+}
+
+func (r *CityPostgres) DeleteCity(cityID uuid.UUID) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM cities WHERE id = $1`, cityID)
+	if err != nil {
+		if isPqError(err, pqForeignKeyViolation) {
+			return false, ErrCityInUse
+		}
+		return false, fmt.Errorf("failed to delete city %s: %w", cityID.String(), err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func isPqError(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
+
+func isPqConstraint(err error, code pq.ErrorCode, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code && pqErr.Constraint == constraint
+}
@@ -0,0 +1,79 @@
+package repository_test
+
+import (
+	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCityPostgres_CreateCity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := repository.NewCityPostgres(sqlxDB)
+
+	t.Run("City created", func(t *testing.T) {
+		expected := models.City{ID: uuid.New(), Name: "Новосибирск", CreatedAt: time.Now()}
+
+		mock.ExpectQuery(`INSERT INTO cities \(name\) VALUES \(\$1\) RETURNING id, name, created_at`).
+			WithArgs("Новосибирск").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at"}).
+				AddRow(expected.ID, expected.Name, expected.CreatedAt))
+
+		city, err := repo.CreateCity("Новосибирск")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, city)
+	})
+
+	t.Run("Duplicate city", func(t *testing.T) {
+		mock.ExpectQuery(`INSERT INTO cities`).
+			WithArgs("Москва").
+			WillReturnError(&pq.Error{Code: "23505"})
+
+		_, err := repo.CreateCity("Москва")
+		assert.ErrorIs(t, err, repository.ErrCityExists)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCityPostgres_DeleteCity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := repository.NewCityPostgres(sqlxDB)
+
+	t.Run("City deleted", func(t *testing.T) {
+		cityID := uuid.New()
+		mock.ExpectExec(`DELETE FROM cities WHERE id = \$1`).
+			WithArgs(cityID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		deleted, err := repo.DeleteCity(cityID)
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+	})
+
+	t.Run("City referenced by pvz", func(t *testing.T) {
+		cityID := uuid.New()
+		mock.ExpectExec(`DELETE FROM cities WHERE id = \$1`).
+			WithArgs(cityID).
+			WillReturnError(&pq.Error{Code: "23503"})
+
+		_, err := repo.DeleteCity(cityID)
+		assert.ErrorIs(t, err, repository.ErrCityInUse)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type HealthPostgres struct {
+	db *sqlx.DB
+}
+
+func NewHealthPostgres(db *sqlx.DB) *HealthPostgres {
+	return &HealthPostgres{db: db}
+}
+
+func (r *HealthPostgres) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// MigrationVersion reads the version recorded by golang-migrate.
+func (r *HealthPostgres) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	var migration struct {
+		Version uint `db:"version"`
+		Dirty   bool `db:"dirty"`
+	}
+	err := r.db.GetContext(ctx, &migration, `SELECT version, dirty FROM schema_migrations LIMIT 1`)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get migration version: %w", err)
+	}
+	return migration.Version, migration.Dirty, nil
+}
+
+func (r *HealthPostgres) PoolStats() sql.DBStats {
+	return r.db.Stats()
+}
@@ -0,0 +1,29 @@
+package repository_test
+
+import (
+	"context"
+	"pvz-test/internal/repository"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthPostgres_MigrationVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := repository.NewHealthPostgres(sqlxDB)
+
+	mock.ExpectQuery(`SELECT version, dirty FROM schema_migrations LIMIT 1`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(5, false))
+
+	version, dirty, err := repo.MigrationVersion(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), version)
+	assert.False(t, dirty)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"pvz-test/internal/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IdempotencyPostgres struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyPostgres(db *sqlx.DB) *IdempotencyPostgres {
+	return &IdempotencyPostgres{db: db}
+}
+
+// ReserveKey claims key for a new request with requestHash. An expired key
+// is claimed again. When the key is taken it returns false and the stored
+// record.
+func (r *IdempotencyPostgres) ReserveKey(key models.IdempotencyKey, requestHash string, ttl time.Duration) (models.IdempotencyRecord, bool, error) {
+	res, err := r.db.Exec(`
+		INSERT INTO idempotency_keys (user_id, route, key, request_hash, expires_at)
+		VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5))
+		ON CONFLICT (user_id, route, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+			status_code = NULL,
+			content_type = NULL,
+			response_body = NULL,
+			created_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+	`, key.UserID, key.Route, key.Key, requestHash, ttl.Seconds())
+	if err != nil {
+		return models.IdempotencyRecord{}, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	reserved, err := res.RowsAffected()
+	if err != nil {
+		return models.IdempotencyRecord{}, false, err
+	}
+	if reserved > 0 {
+		return models.IdempotencyRecord{}, true, nil
+	}
+
+	var row struct {
+		RequestHash  string         `db:"request_hash"`
+		StatusCode   sql.NullInt64  `db:"status_code"`
+		ContentType  sql.NullString `db:"content_type"`
+		ResponseBody []byte         `db:"response_body"`
+	}
+	err = r.db.Get(&row, `
+		SELECT request_hash, status_code, content_type, response_body
+		FROM idempotency_keys
+		WHERE user_id = $1 AND route = $2 AND key = $3
+	`, key.UserID, key.Route, key.Key)
+	if err != nil {
+		return models.IdempotencyRecord{}, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	record := models.IdempotencyRecord{RequestHash: row.RequestHash}
+	if row.StatusCode.Valid {
+		record.Response = &models.StoredResponse{
+			StatusCode:  int(row.StatusCode.Int64),
+			ContentType: row.ContentType.String,
+			Body:        row.ResponseBody,
+		}
+	}
+	return record, false, nil
+}
+
+func (r *IdempotencyPostgres) SaveResponse(key models.IdempotencyKey, response models.StoredResponse) error {
+	_, err := r.db.Exec(`
+		UPDATE idempotency_keys
+		SET status_code = $4, content_type = $5, response_body = $6
+		WHERE user_id = $1 AND route = $2 AND key = $3
+	`, key.UserID, key.Route, key.Key, response.StatusCode, response.ContentType, response.Body)
+	if err != nil {
+		return fmt.Errorf("failed to save idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (r *IdempotencyPostgres) DeleteKey(key models.IdempotencyKey) error {
+	_, err := r.db.Exec(`
+		DELETE FROM idempotency_keys WHERE user_id = $1 AND route = $2 AND key = $3
+	`, key.UserID, key.Route, key.Key)
+	if err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *IdempotencyPostgres) DeleteExpiredKeys() (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+	return res.RowsAffected()
+}
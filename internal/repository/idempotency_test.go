@@ -0,0 +1,103 @@
+package repository_test
+
+import (
+	"errors"
+	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyPostgres_ReserveKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewIdempotencyPostgres(sqlx.NewDb(db, "sqlmock"))
+	key := models.IdempotencyKey{UserID: uuid.New(), Route: "POST /api/pvz", Key: "key-1"}
+
+	t.Run("New key is reserved", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO idempotency_keys`).
+			WithArgs(key.UserID, key.Route, key.Key, "hash", float64(3600)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, reserved, err := repo.ReserveKey(key, "hash", time.Hour)
+		assert.NoError(t, err)
+		assert.True(t, reserved)
+	})
+
+	t.Run("Completed key returns the stored response", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO idempotency_keys`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT request_hash, status_code, content_type, response_body FROM idempotency_keys`).
+			WithArgs(key.UserID, key.Route, key.Key).
+			WillReturnRows(sqlmock.NewRows([]string{"request_hash", "status_code", "content_type", "response_body"}).
+				AddRow("hash", 201, "application/json", []byte(`{"id":"1"}`)))
+
+		record, reserved, err := repo.ReserveKey(key, "hash", time.Hour)
+		assert.NoError(t, err)
+		assert.False(t, reserved)
+		assert.Equal(t, models.IdempotencyRecord{
+			RequestHash: "hash",
+			Response:    &models.StoredResponse{StatusCode: 201, ContentType: "application/json", Body: []byte(`{"id":"1"}`)},
+		}, record)
+	})
+
+	t.Run("Key in flight has no response", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO idempotency_keys`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT request_hash`).
+			WillReturnRows(sqlmock.NewRows([]string{"request_hash", "status_code", "content_type", "response_body"}).
+				AddRow("other", nil, nil, nil))
+
+		record, reserved, err := repo.ReserveKey(key, "hash", time.Hour)
+		assert.NoError(t, err)
+		assert.False(t, reserved)
+		assert.Equal(t, models.IdempotencyRecord{RequestHash: "other"}, record)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO idempotency_keys`).WillReturnError(errors.New("db error"))
+
+		_, _, err := repo.ReserveKey(key, "hash", time.Hour)
+		assert.Error(t, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyPostgres_SaveResponse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewIdempotencyPostgres(sqlx.NewDb(db, "sqlmock"))
+	key := models.IdempotencyKey{UserID: uuid.New(), Route: "POST /api/pvz", Key: "key-1"}
+	response := models.StoredResponse{StatusCode: 201, ContentType: "application/json", Body: []byte(`{}`)}
+
+	mock.ExpectExec(`UPDATE idempotency_keys SET status_code = \$4, content_type = \$5, response_body = \$6`).
+		WithArgs(key.UserID, key.Route, key.Key, 201, "application/json", []byte(`{}`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.SaveResponse(key, response))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyPostgres_DeleteExpiredKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewIdempotencyPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(`DELETE FROM idempotency_keys WHERE expires_at <= NOW\(\)`).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := repo.DeleteExpiredKeys()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"pvz-test/internal/models"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type OutboxPostgres struct {
+	db *sqlx.DB
+}
+
+func NewOutboxPostgres(db *sqlx.DB) *OutboxPostgres {
+	return &OutboxPostgres{db: db}
+}
+
+// ClaimEvents takes up to limit undelivered events that are due and hides
+// them from other dispatchers for lease. An event whose dispatcher dies
+// before marking it becomes due again once the lease expires.
+func (r *OutboxPostgres) ClaimEvents(limit int, lease time.Duration) ([]models.Event, error) {
+	events := []models.Event{}
+	err := r.db.Select(&events, `
+		UPDATE outbox
+		SET attempts = attempts + 1,
+			next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE delivered_at IS NULL AND next_attempt_at <= NOW()
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, event_type, aggregate_id, pvz_id, payload, created_at, attempts
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].OccurredAt.Before(events[j].OccurredAt)
+	})
+	return events, nil
+}
+
+func (r *OutboxPostgres) MarkDelivered(eventID uuid.UUID) error {
+	_, err := r.db.Exec(`
+		UPDATE outbox SET delivered_at = NOW(), last_error = NULL WHERE id = $1
+	`, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event %s delivered: %w", eventID, err)
+	}
+	return nil
+}
+
+func (r *OutboxPostgres) MarkFailed(eventID uuid.UUID, reason string, retryAt time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE outbox SET last_error = $2, next_attempt_at = $3 WHERE id = $1
+	`, eventID, reason, retryAt)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule outbox event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+// insertOutboxEvent stores a domain event inside the transaction that
+// performs the change, so it is published if and only if the change commits.
+func insertOutboxEvent(tx *sqlx.Tx, eventType models.EventType, aggregateID, pvzID uuid.UUID, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO outbox (event_type, aggregate_id, pvz_id, payload)
+		VALUES ($1, $2, $3, $4)
+	`, eventType, aggregateID, pvzID, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to write %s event: %w", eventType, err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package repository_test
+
+import (
+	"errors"
+	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// expectOutbox expects the domain event that a mutation writes before commit.
+func expectOutbox(mock sqlmock.Sqlmock, eventType models.EventType, aggregateID, pvzID interface{}) *sqlmock.ExpectedExec {
+	return mock.ExpectExec(`INSERT INTO outbox \(event_type, aggregate_id, pvz_id, payload\)`).
+		WithArgs(eventType, aggregateID, pvzID, sqlmock.AnyArg())
+}
+
+func TestOutboxPostgres_ClaimEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOutboxPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	columns := []string{"id", "event_type", "aggregate_id", "pvz_id", "payload", "created_at", "attempts"}
+
+	t.Run("Claimed events come oldest first", func(t *testing.T) {
+		pvzID, receptionID := uuid.New(), uuid.New()
+		opened := models.Event{
+			ID:          uuid.New(),
+			Type:        models.EventReceptionOpened,
+			AggregateID: receptionID,
+			PVZID:       pvzID,
+			Payload:     []byte(`{"id":"` + receptionID.String() + `"}`),
+			OccurredAt:  time.Now().Add(-time.Minute),
+			Attempts:    1,
+		}
+		closed := opened
+		closed.ID = uuid.New()
+		closed.Type = models.EventReceptionClosed
+		closed.OccurredAt = time.Now()
+
+		mock.ExpectQuery(`UPDATE outbox SET attempts = attempts \+ 1, next_attempt_at = NOW\(\) \+ make_interval\(secs => \$2\) `+
+			`WHERE id IN \( SELECT id FROM outbox WHERE delivered_at IS NULL AND next_attempt_at <= NOW\(\) ORDER BY created_at LIMIT \$1 FOR UPDATE SKIP LOCKED \)`).
+			WithArgs(50, float64(30)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(closed.ID, closed.Type, closed.AggregateID, closed.PVZID, []byte(closed.Payload), closed.OccurredAt, 1).
+				AddRow(opened.ID, opened.Type, opened.AggregateID, opened.PVZID, []byte(opened.Payload), opened.OccurredAt, 1))
+
+		events, err := repo.ClaimEvents(50, 30*time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Event{opened, closed}, events)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE outbox`).
+			WillReturnError(errors.New("database error"))
+
+		_, err := repo.ClaimEvents(50, 30*time.Second)
+		assert.ErrorContains(t, err, "failed to claim outbox events")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxPostgres_MarkFailed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewOutboxPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	eventID := uuid.New()
+	retryAt := time.Now().Add(time.Minute)
+	mock.ExpectExec(`UPDATE outbox SET last_error = \$2, next_attempt_at = \$3 WHERE id = \$1`).
+		WithArgs(eventID, "sink unavailable", retryAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE outbox SET delivered_at = NOW\(\), last_error = NULL WHERE id = \$1`).
+		WithArgs(eventID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.MarkFailed(eventID, "sink unavailable", retryAt))
+	assert.NoError(t, repo.MarkDelivered(eventID))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,14 +1,20 @@
 package repository
 
 import (
+	"database/sql"
 	"fmt"
+	"pvz-test/internal/apperr"
 	"pvz-test/internal/models"
+	"time"
 
+	sq "github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/sirupsen/logrus"
 )
 
+var ErrPvzHasActiveReception = apperr.New(apperr.ErrConflict, "pvz has an active reception")
+
 type PvzPostgres struct {
 	db *sqlx.DB
 }
@@ -17,18 +23,31 @@ func NewPvzPostgres(db *sqlx.DB) *PvzPostgres {
 	return &PvzPostgres{db: db}
 }
 
-func (r *PvzPostgres) CreatePvz(city string) (models.PVZ, error) {
+func (r *PvzPostgres) CreatePvz(actor models.Actor, city string) (models.PVZ, error) {
 	var pvz models.PVZ
 	logrus.Infof("Inserting new PVZ with city: %s", city)
-	err := r.db.Get(&pvz, `
+	tx := r.db.MustBegin()
+	err := tx.Get(&pvz, `
         INSERT INTO pvz (city)
         VALUES ($1)
         RETURNING id, city, registration_date
     `, city)
 	if err != nil {
+		tx.Rollback()
 		logrus.Errorf("Error inserting PVZ: %v", err)
 		return models.PVZ{}, err
 	}
+	if err := insertAuditEvent(tx, actor, models.AuditPVZCreated, pvz.ID, pvz.ID, nil, pvz); err != nil {
+		tx.Rollback()
+		return models.PVZ{}, err
+	}
+	if err := insertOutboxEvent(tx, models.EventPVZCreated, pvz.ID, pvz.ID, pvz); err != nil {
+		tx.Rollback()
+		return models.PVZ{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.PVZ{}, fmt.Errorf("failed to commit PVZ: %w", err)
+	}
 	logrus.Infof("Inserted new PVZ: %v", pvz)
 	return pvz, nil
 }
@@ -47,13 +66,197 @@ func (r *PvzPostgres) Exists(pvzID uuid.UUID) (bool, error) {
 	return exists, nil
 }
 
-func (r *PvzPostgres) GetPVZList(limit, offset int) ([]models.PVZ, error) {
+func (r *PvzPostgres) GetPVZList(status models.PVZStatus, limit, offset int) ([]models.PVZ, error) {
+	query := withPVZStatus(sq.
+		Select("p.id", "p.registration_date", "p.city", "p.deactivated_at").
+		From("pvz p"), status).
+		OrderBy("p.registration_date DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+
+	sqlQuery, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var pvzs []models.PVZ
+	err = r.db.Select(&pvzs, sqlQuery, args...)
+	return pvzs, err
+}
+
+func (r *PvzPostgres) GetPVZListWithReceptions(start, end *time.Time, status models.PVZStatus, limit, offset int) ([]models.PVZ, error) {
+	receptions := sq.
+		Select("1").
+		From("receptions r").
+		Where("r.pvz_id = p.id")
+
+	if start != nil {
+		receptions = receptions.Where(sq.GtOrEq{"r.created_at": *start})
+	}
+	if end != nil {
+		receptions = receptions.Where(sq.LtOrEq{"r.created_at": *end})
+	}
+
+	receptionsQuery, receptionsArgs, err := receptions.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	query := withPVZStatus(sq.
+		Select("p.id", "p.registration_date", "p.city", "p.deactivated_at").
+		From("pvz p").
+		Where(sq.Expr("EXISTS ("+receptionsQuery+")", receptionsArgs...)), status).
+		OrderBy("p.registration_date DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+
+	sqlQuery, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var pvzs []models.PVZ
+	err = r.db.Select(&pvzs, sqlQuery, args...)
+	return pvzs, err
+}
+
+func withPVZStatus(query sq.SelectBuilder, status models.PVZStatus) sq.SelectBuilder {
+	switch status {
+	case models.PVZStatusAll:
+		return query
+	case models.PVZStatusDeactivated:
+		return query.Where(sq.NotEq{"p.deactivated_at": nil})
+	default:
+		return query.Where(sq.Eq{"p.deactivated_at": nil})
+	}
+}
+
+// GetAllPVZ returns every active PVZ.
+func (r *PvzPostgres) GetAllPVZ() ([]models.PVZ, error) {
 	var pvzs []models.PVZ
 	err := r.db.Select(&pvzs, `
-		SELECT id, registration_date, city
+		SELECT id, registration_date, city, deactivated_at
 		FROM pvz
+		WHERE deactivated_at IS NULL
 		ORDER BY registration_date DESC
-		LIMIT $1 OFFSET $2
-	`, limit, offset)
+	`)
 	return pvzs, err
 }
+
+func (r *PvzPostgres) GetPVZByID(pvzID uuid.UUID) (models.PVZ, error) {
+	var pvz models.PVZ
+	err := r.db.Get(&pvz, `
+		SELECT id, registration_date, city, deactivated_at
+		FROM pvz
+		WHERE id = $1
+	`, pvzID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.PVZ{}, nil
+		}
+		return models.PVZ{}, fmt.Errorf("failed to get PVZ %s: %w", pvzID.String(), err)
+	}
+	return pvz, nil
+}
+
+func (r *PvzPostgres) UpdatePVZ(actor models.Actor, pvzID uuid.UUID, city string) (models.PVZ, error) {
+	tx := r.db.MustBegin()
+
+	before, err := lockPVZ(tx, pvzID)
+	if err != nil || (before == models.PVZ{}) {
+		tx.Rollback()
+		return models.PVZ{}, err
+	}
+
+	var pvz models.PVZ
+	err = tx.Get(&pvz, `
+		UPDATE pvz
+		SET city = $2
+		WHERE id = $1
+		RETURNING id, registration_date, city, deactivated_at
+	`, pvzID, city)
+	if err != nil {
+		tx.Rollback()
+		return models.PVZ{}, fmt.Errorf("failed to update PVZ %s: %w", pvzID.String(), err)
+	}
+	if err := insertAuditEvent(tx, actor, models.AuditPVZUpdated, pvzID, pvzID, before, pvz); err != nil {
+		tx.Rollback()
+		return models.PVZ{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.PVZ{}, fmt.Errorf("failed to commit PVZ %s: %w", pvzID.String(), err)
+	}
+	return pvz, nil
+}
+
+// DeactivatePVZ soft deletes the PVZ. Deactivating an already deactivated PVZ
+// keeps the original timestamp and writes no audit event. A PVZ with a
+// reception in progress is not deactivated.
+func (r *PvzPostgres) DeactivatePVZ(actor models.Actor, pvzID uuid.UUID) (models.PVZ, error) {
+	tx := r.db.MustBegin()
+
+	before, err := lockPVZ(tx, pvzID)
+	if err != nil || (before == models.PVZ{}) || !before.Active() {
+		tx.Rollback()
+		return before, err
+	}
+
+	// The PVZ lock waits for receptions being opened under FOR SHARE, so a
+	// reception committed before it is seen here.
+	var hasReception bool
+	err = tx.Get(&hasReception, `
+		SELECT EXISTS (
+			SELECT 1 FROM receptions
+			WHERE pvz_id = $1 AND status = 'in_progress'
+		)
+	`, pvzID)
+	if err != nil {
+		tx.Rollback()
+		return models.PVZ{}, fmt.Errorf("failed to check receptions of PVZ %s: %w", pvzID.String(), err)
+	}
+	if hasReception {
+		tx.Rollback()
+		return models.PVZ{}, fmt.Errorf("%w: %s", ErrPvzHasActiveReception, pvzID.String())
+	}
+
+	var pvz models.PVZ
+	err = tx.Get(&pvz, `
+		UPDATE pvz
+		SET deactivated_at = NOW()
+		WHERE id = $1
+		RETURNING id, registration_date, city, deactivated_at
+	`, pvzID)
+	if err != nil {
+		tx.Rollback()
+		return models.PVZ{}, fmt.Errorf("failed to deactivate PVZ %s: %w", pvzID.String(), err)
+	}
+	if err := insertAuditEvent(tx, actor, models.AuditPVZDeactivated, pvzID, pvzID, before, pvz); err != nil {
+		tx.Rollback()
+		return models.PVZ{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.PVZ{}, fmt.Errorf("failed to commit PVZ %s: %w", pvzID.String(), err)
+	}
+	return pvz, nil
+}
+
+// lockPVZ reads the PVZ for an update in tx. A missing PVZ is returned as the
+// zero value.
+func lockPVZ(tx *sqlx.Tx, pvzID uuid.UUID) (models.PVZ, error) {
+	var pvz models.PVZ
+	err := tx.Get(&pvz, `
+		SELECT id, registration_date, city, deactivated_at
+		FROM pvz
+		WHERE id = $1
+		FOR UPDATE
+	`, pvzID)
+	if err == sql.ErrNoRows {
+		return models.PVZ{}, nil
+	}
+	if err != nil {
+		return models.PVZ{}, fmt.Errorf("failed to lock PVZ %s: %w", pvzID.String(), err)
+	}
+	return pvz, nil
+}
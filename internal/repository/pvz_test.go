@@ -1,6 +1,7 @@
 package repository_test
 
 import (
+	"database/sql"
 	"errors"
 	"pvz-test/internal/models"
 	"pvz-test/internal/repository"
@@ -29,12 +30,18 @@ func TestPvzPostgres_CreatePvz(t *testing.T) {
 			City:             city,
 		}
 
+		mock.ExpectBegin()
 		mock.ExpectQuery(`INSERT INTO pvz \(city\) VALUES \(\$1\) RETURNING id, city, registration_date`).
 			WithArgs(city).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "city", "registration_date"}).
 				AddRow(expectedPvz.ID, expectedPvz.City, expectedPvz.RegistrationDate))
+		expectAudit(mock, models.AuditPVZCreated, expectedPvz.ID, expectedPvz.ID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectOutbox(mock, models.EventPVZCreated, expectedPvz.ID, expectedPvz.ID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
 
-		pvz, err := repo.CreatePvz(city)
+		pvz, err := repo.CreatePvz(testActor, city)
 		assert.NoError(t, err)
 		assert.Equal(t, expectedPvz, pvz)
 	})
@@ -42,13 +49,32 @@ func TestPvzPostgres_CreatePvz(t *testing.T) {
 	t.Run("Database error", func(t *testing.T) {
 		city := "Казань"
 
+		mock.ExpectBegin()
 		mock.ExpectQuery(`INSERT INTO pvz \(city\) VALUES \(\$1\) RETURNING id, city, registration_date`).
 			WithArgs(city).
 			WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
 
-		_, err := repo.CreatePvz(city)
+		_, err := repo.CreatePvz(testActor, city)
 		assert.EqualError(t, err, "database error")
 	})
+
+	t.Run("Audit failure rolls back", func(t *testing.T) {
+		pvzID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO pvz`).
+			WithArgs("Москва").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "city", "registration_date"}).
+				AddRow(pvzID, "Москва", time.Now()))
+		expectAudit(mock, models.AuditPVZCreated, pvzID, pvzID).
+			WillReturnError(errors.New("audit error"))
+		mock.ExpectRollback()
+
+		_, err := repo.CreatePvz(testActor, "Москва")
+		assert.ErrorContains(t, err, "audit error")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestPvzPostgres_Exists(t *testing.T) {
@@ -110,25 +136,273 @@ func TestPvzPostgres_GetPVZList(t *testing.T) {
 			{ID: uuid.New(), RegistrationDate: time.Now(), City: "Казань"},
 		}
 
-		mock.ExpectQuery(`SELECT id, registration_date, city FROM pvz ORDER BY registration_date DESC LIMIT \$1 OFFSET \$2`).
-			WithArgs(limit, offset).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "registration_date", "city"}).
-				AddRow(expectedPVZs[0].ID, expectedPVZs[0].RegistrationDate, expectedPVZs[0].City).
-				AddRow(expectedPVZs[1].ID, expectedPVZs[1].RegistrationDate, expectedPVZs[1].City))
+		mock.ExpectQuery(`SELECT p.id, p.registration_date, p.city, p.deactivated_at FROM pvz p WHERE p.deactivated_at IS NULL ORDER BY p.registration_date DESC LIMIT 10 OFFSET 0`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "registration_date", "city", "deactivated_at"}).
+				AddRow(expectedPVZs[0].ID, expectedPVZs[0].RegistrationDate, expectedPVZs[0].City, nil).
+				AddRow(expectedPVZs[1].ID, expectedPVZs[1].RegistrationDate, expectedPVZs[1].City, nil))
 
-		pvzs, err := repo.GetPVZList(limit, offset)
+		pvzs, err := repo.GetPVZList(models.PVZStatusActive, limit, offset)
 		assert.NoError(t, err)
 		assert.Equal(t, expectedPVZs, pvzs)
 	})
 
+	t.Run("Deactivated only", func(t *testing.T) {
+		deactivatedAt := time.Now()
+		expectedPvz := models.PVZ{ID: uuid.New(), RegistrationDate: time.Now(), City: "Москва", DeactivatedAt: &deactivatedAt}
+
+		mock.ExpectQuery(`SELECT p.id, p.registration_date, p.city, p.deactivated_at FROM pvz p WHERE p.deactivated_at IS NOT NULL ORDER BY p.registration_date DESC LIMIT 10 OFFSET 10`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "registration_date", "city", "deactivated_at"}).
+				AddRow(expectedPvz.ID, expectedPvz.RegistrationDate, expectedPvz.City, deactivatedAt))
+
+		pvzs, err := repo.GetPVZList(models.PVZStatusDeactivated, 10, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.PVZ{expectedPvz}, pvzs)
+	})
+
+	t.Run("All", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT p.id, p.registration_date, p.city, p.deactivated_at FROM pvz p ORDER BY p.registration_date DESC LIMIT 10 OFFSET 0`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "registration_date", "city", "deactivated_at"}))
+
+		pvzs, err := repo.GetPVZList(models.PVZStatusAll, 10, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, pvzs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("Database error", func(t *testing.T) {
 		limit, offset := 10, 0
 
-		mock.ExpectQuery(`SELECT id, registration_date, city FROM pvz ORDER BY registration_date DESC LIMIT \$1 OFFSET \$2`).
-			WithArgs(limit, offset).
+		mock.ExpectQuery(`SELECT p.id, p.registration_date, p.city, p.deactivated_at FROM pvz p WHERE p.deactivated_at IS NULL ORDER BY p.registration_date DESC LIMIT 10 OFFSET 0`).
 			WillReturnError(errors.New("database error"))
 
-		_, err := repo.GetPVZList(limit, offset)
+		_, err := repo.GetPVZList(models.PVZStatusActive, limit, offset)
 		assert.EqualError(t, err, "database error")
 	})
 }
+
+func TestPvzPostgres_GetAllPVZ(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := repository.NewPvzPostgres(sqlxDB)
+
+	expectedPvz := models.PVZ{ID: uuid.New(), RegistrationDate: time.Now(), City: "Москва"}
+
+	mock.ExpectQuery(`SELECT id, registration_date, city, deactivated_at FROM pvz WHERE deactivated_at IS NULL ORDER BY registration_date DESC`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "registration_date", "city"}).
+			AddRow(expectedPvz.ID, expectedPvz.RegistrationDate, expectedPvz.City))
+
+	pvzs, err := repo.GetAllPVZ()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.PVZ{expectedPvz}, pvzs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPvzPostgres_GetPVZByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := repository.NewPvzPostgres(sqlxDB)
+
+	t.Run("PVZ found", func(t *testing.T) {
+		expectedPvz := models.PVZ{ID: uuid.New(), RegistrationDate: time.Now(), City: "Москва"}
+
+		mock.ExpectQuery(`SELECT id, registration_date, city, deactivated_at FROM pvz WHERE id = \$1`).
+			WithArgs(expectedPvz.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "registration_date", "city"}).
+				AddRow(expectedPvz.ID, expectedPvz.RegistrationDate, expectedPvz.City))
+
+		pvz, err := repo.GetPVZByID(expectedPvz.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedPvz, pvz)
+	})
+
+	t.Run("PVZ not found", func(t *testing.T) {
+		pvzID := uuid.New()
+
+		mock.ExpectQuery(`SELECT id, registration_date, city, deactivated_at FROM pvz WHERE id = \$1`).
+			WithArgs(pvzID).
+			WillReturnError(sql.ErrNoRows)
+
+		pvz, err := repo.GetPVZByID(pvzID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.PVZ{}, pvz)
+	})
+}
+
+func TestPvzPostgres_GetPVZListWithReceptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := repository.NewPvzPostgres(sqlxDB)
+
+	t.Run("Both bounds", func(t *testing.T) {
+		start, end := time.Now().Add(-time.Hour), time.Now()
+		expectedPvz := models.PVZ{ID: uuid.New(), RegistrationDate: time.Now(), City: "Москва"}
+
+		mock.ExpectQuery(`SELECT p.id, p.registration_date, p.city, p.deactivated_at FROM pvz p WHERE EXISTS \(SELECT 1 FROM receptions r WHERE r.pvz_id = p.id AND r.created_at >= \$1 AND r.created_at <= \$2\) AND p.deactivated_at IS NULL ORDER BY p.registration_date DESC LIMIT 10 OFFSET 20`).
+			WithArgs(start, end).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "registration_date", "city"}).
+				AddRow(expectedPvz.ID, expectedPvz.RegistrationDate, expectedPvz.City))
+
+		pvzs, err := repo.GetPVZListWithReceptions(&start, &end, models.PVZStatusActive, 10, 20)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.PVZ{expectedPvz}, pvzs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Only end bound", func(t *testing.T) {
+		end := time.Now()
+
+		mock.ExpectQuery(`SELECT p.id, p.registration_date, p.city, p.deactivated_at FROM pvz p WHERE EXISTS \(SELECT 1 FROM receptions r WHERE r.pvz_id = p.id AND r.created_at <= \$1\) AND p.deactivated_at IS NULL ORDER BY p.registration_date DESC LIMIT 10 OFFSET 0`).
+			WithArgs(end).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "registration_date", "city"}))
+
+		pvzs, err := repo.GetPVZListWithReceptions(nil, &end, models.PVZStatusActive, 10, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, pvzs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPvzPostgres_UpdatePVZ(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := repository.NewPvzPostgres(sqlxDB)
+
+	pvzColumns := []string{"id", "registration_date", "city", "deactivated_at"}
+
+	t.Run("Updated", func(t *testing.T) {
+		expectedPvz := models.PVZ{ID: uuid.New(), RegistrationDate: time.Now(), City: "Казань"}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, registration_date, city, deactivated_at FROM pvz WHERE id = \$1 FOR UPDATE`).
+			WithArgs(expectedPvz.ID).
+			WillReturnRows(sqlmock.NewRows(pvzColumns).
+				AddRow(expectedPvz.ID, expectedPvz.RegistrationDate, "Москва", nil))
+		mock.ExpectQuery(`UPDATE pvz SET city = \$2 WHERE id = \$1 RETURNING id, registration_date, city, deactivated_at`).
+			WithArgs(expectedPvz.ID, "Казань").
+			WillReturnRows(sqlmock.NewRows(pvzColumns).
+				AddRow(expectedPvz.ID, expectedPvz.RegistrationDate, expectedPvz.City, nil))
+		expectAudit(mock, models.AuditPVZUpdated, expectedPvz.ID, expectedPvz.ID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		pvz, err := repo.UpdatePVZ(testActor, expectedPvz.ID, "Казань")
+		assert.NoError(t, err)
+		assert.Equal(t, expectedPvz, pvz)
+	})
+
+	t.Run("PVZ not found", func(t *testing.T) {
+		pvzID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, registration_date, city, deactivated_at FROM pvz WHERE id = \$1 FOR UPDATE`).
+			WithArgs(pvzID).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		pvz, err := repo.UpdatePVZ(testActor, pvzID, "Казань")
+		assert.NoError(t, err)
+		assert.Equal(t, models.PVZ{}, pvz)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPvzPostgres_DeactivatePVZ(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := repository.NewPvzPostgres(sqlxDB)
+
+	pvzColumns := []string{"id", "registration_date", "city", "deactivated_at"}
+
+	t.Run("Deactivated", func(t *testing.T) {
+		deactivatedAt := time.Now()
+		expectedPvz := models.PVZ{ID: uuid.New(), RegistrationDate: time.Now(), City: "Москва", DeactivatedAt: &deactivatedAt}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, registration_date, city, deactivated_at FROM pvz WHERE id = \$1 FOR UPDATE`).
+			WithArgs(expectedPvz.ID).
+			WillReturnRows(sqlmock.NewRows(pvzColumns).
+				AddRow(expectedPvz.ID, expectedPvz.RegistrationDate, expectedPvz.City, nil))
+		mock.ExpectQuery(`SELECT EXISTS \( SELECT 1 FROM receptions WHERE pvz_id = \$1 AND status = 'in_progress' \)`).
+			WithArgs(expectedPvz.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery(`UPDATE pvz SET deactivated_at = NOW\(\) WHERE id = \$1 RETURNING id, registration_date, city, deactivated_at`).
+			WithArgs(expectedPvz.ID).
+			WillReturnRows(sqlmock.NewRows(pvzColumns).
+				AddRow(expectedPvz.ID, expectedPvz.RegistrationDate, expectedPvz.City, deactivatedAt))
+		expectAudit(mock, models.AuditPVZDeactivated, expectedPvz.ID, expectedPvz.ID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		pvz, err := repo.DeactivatePVZ(testActor, expectedPvz.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedPvz, pvz)
+	})
+
+	t.Run("Already deactivated", func(t *testing.T) {
+		deactivatedAt := time.Now().Add(-time.Hour)
+		expectedPvz := models.PVZ{ID: uuid.New(), RegistrationDate: time.Now(), City: "Москва", DeactivatedAt: &deactivatedAt}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, registration_date, city, deactivated_at FROM pvz WHERE id = \$1 FOR UPDATE`).
+			WithArgs(expectedPvz.ID).
+			WillReturnRows(sqlmock.NewRows(pvzColumns).
+				AddRow(expectedPvz.ID, expectedPvz.RegistrationDate, expectedPvz.City, deactivatedAt))
+		mock.ExpectRollback()
+
+		pvz, err := repo.DeactivatePVZ(testActor, expectedPvz.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedPvz, pvz)
+	})
+
+	t.Run("Active reception", func(t *testing.T) {
+		pvzID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, registration_date, city, deactivated_at FROM pvz WHERE id = \$1 FOR UPDATE`).
+			WithArgs(pvzID).
+			WillReturnRows(sqlmock.NewRows(pvzColumns).AddRow(pvzID, time.Now(), "Москва", nil))
+		mock.ExpectQuery(`SELECT EXISTS \( SELECT 1 FROM receptions WHERE pvz_id = \$1 AND status = 'in_progress' \)`).
+			WithArgs(pvzID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+
+		_, err := repo.DeactivatePVZ(testActor, pvzID)
+		assert.ErrorIs(t, err, repository.ErrPvzHasActiveReception)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		pvzID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, registration_date, city, deactivated_at FROM pvz`).
+			WithArgs(pvzID).
+			WillReturnRows(sqlmock.NewRows(pvzColumns).AddRow(pvzID, time.Now(), "Москва", nil))
+		mock.ExpectQuery(`SELECT EXISTS \( SELECT 1 FROM receptions WHERE pvz_id = \$1 AND status = 'in_progress' \)`).
+			WithArgs(pvzID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery(`UPDATE pvz SET deactivated_at`).
+			WithArgs(pvzID).
+			WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		_, err := repo.DeactivatePVZ(testActor, pvzID)
+		assert.ErrorContains(t, err, "database error")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
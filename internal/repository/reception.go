@@ -3,12 +3,23 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"pvz-test/internal/apperr"
 	"pvz-test/internal/models"
+	"sort"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrReceptionInProgress = apperr.New(apperr.ErrConflict, "pvz has a reception in progress")
+	ErrNoActiveReception   = apperr.New(apperr.ErrValidation, "no active reception")
+	ErrNoItems             = apperr.New(apperr.ErrValidation, "no products in reception")
+	ErrBarcodeReceived     = apperr.New(apperr.ErrConflict, "barcode is already in an open reception")
+	ErrPvzDeactivated      = apperr.New(apperr.ErrConflict, "pvz is deactivated")
 )
 
 type ReceptionPostgres struct {
@@ -19,11 +30,26 @@ func NewReceptionPostgres(db *sqlx.DB) *ReceptionPostgres {
 	return &ReceptionPostgres{db: db}
 }
 
-func (r *ReceptionPostgres) CreateReception(pvzID uuid.UUID) (models.Reception, error) {
+func (r *ReceptionPostgres) CreateReception(actor models.Actor, pvzID uuid.UUID) (models.Reception, error) {
 	tx := r.db.MustBegin()
 
+	// The share lock keeps the PVZ from being deactivated until the reception
+	// is committed.
+	var deactivatedAt *time.Time
+	err := tx.Get(&deactivatedAt, `
+		SELECT deactivated_at FROM pvz WHERE id = $1 FOR SHARE
+	`, pvzID)
+	if err != nil {
+		tx.Rollback()
+		return models.Reception{}, fmt.Errorf("failed to lock PVZ %s: %w", pvzID.String(), err)
+	}
+	if deactivatedAt != nil {
+		tx.Rollback()
+		return models.Reception{}, fmt.Errorf("%w: %s", ErrPvzDeactivated, pvzID.String())
+	}
+
 	var exists bool
-	err := tx.Get(&exists, `
+	err = tx.Get(&exists, `
 		SELECT EXISTS (
 			SELECT 1 FROM receptions
 			WHERE pvz_id = $1 AND status = 'in_progress'
@@ -35,9 +61,11 @@ func (r *ReceptionPostgres) CreateReception(pvzID uuid.UUID) (models.Reception,
 	}
 	if exists {
 		tx.Rollback()
-		return models.Reception{}, fmt.Errorf("pvz: %s have active reception", pvzID.String())
+		return models.Reception{}, fmt.Errorf("%w: %s", ErrReceptionInProgress, pvzID.String())
 	}
 
+	// A concurrent insert that passed the check above is rejected by the
+	// unique_in_progress_reception_per_pvz index.
 	var reception models.Reception
 	err = tx.Get(&reception, `
 		INSERT INTO receptions (pvz_id, status)
@@ -45,6 +73,17 @@ func (r *ReceptionPostgres) CreateReception(pvzID uuid.UUID) (models.Reception,
 		RETURNING id, pvz_id, status, created_at
 	`, pvzID)
 	if err != nil {
+		tx.Rollback()
+		if isPqError(err, pqUniqueViolation) {
+			return models.Reception{}, fmt.Errorf("%w: %s", ErrReceptionInProgress, pvzID.String())
+		}
+		return models.Reception{}, err
+	}
+	if err := insertAuditEvent(tx, actor, models.AuditReceptionCreated, reception.ID, pvzID, nil, reception); err != nil {
+		tx.Rollback()
+		return models.Reception{}, err
+	}
+	if err := insertOutboxEvent(tx, models.EventReceptionOpened, reception.ID, pvzID, reception); err != nil {
 		tx.Rollback()
 		return models.Reception{}, err
 	}
@@ -56,9 +95,10 @@ func (r *ReceptionPostgres) CreateReception(pvzID uuid.UUID) (models.Reception,
 	return reception, nil
 }
 
-func (r *ReceptionPostgres) AddItem(pvzID uuid.UUID, itemType string) (models.Item, error) {
+func (r *ReceptionPostgres) AddItem(actor models.Actor, pvzID uuid.UUID, itemType string, details models.ItemDetails) (models.Item, error) {
 	tx := r.db.MustBegin()
 
+	// The lock keeps the reception from being closed while the item is added.
 	var receptionID uuid.UUID
 	err := tx.Get(&receptionID, `
 		SELECT id
@@ -66,29 +106,147 @@ func (r *ReceptionPostgres) AddItem(pvzID uuid.UUID, itemType string) (models.It
 		WHERE pvz_id = $1 AND status = 'in_progress'
 		ORDER BY created_at DESC
 		LIMIT 1
+		FOR UPDATE
 	`, pvzID)
 	if err != nil {
 		tx.Rollback()
 		if err == sql.ErrNoRows {
-			return models.Item{}, fmt.Errorf("no active reception for PVZ %s", pvzID)
+			return models.Item{}, fmt.Errorf("%w for PVZ %s", ErrNoActiveReception, pvzID)
 		}
 		return models.Item{}, err
 	}
+	if details.Barcode != nil {
+		received, err := lockBarcodes(tx, []string{*details.Barcode})
+		if err != nil {
+			tx.Rollback()
+			return models.Item{}, err
+		}
+		if len(received) > 0 {
+			tx.Rollback()
+			return models.Item{}, fmt.Errorf("%w: %s", ErrBarcodeReceived, *details.Barcode)
+		}
+	}
+
 	var item models.Item
 	err = tx.Get(&item, `
-		INSERT INTO goods (reception_id, type, added_at)
-		VALUES ($1, $2, NOW())
-		RETURNING *
-	`, receptionID, itemType)
+		INSERT INTO goods (reception_id, type, added_at, barcode, sku, description)
+		VALUES ($1, $2, NOW(), $3, $4, $5)
+		RETURNING id, reception_id, type, added_at, barcode, sku, description
+	`, receptionID, itemType, details.Barcode, details.SKU, details.Description)
 	if err != nil {
 		tx.Rollback()
 		return models.Item{}, fmt.Errorf("failed to insert item: %w", err)
 	}
+	if err := insertAuditEvent(tx, actor, models.AuditProductAdded, item.ID, pvzID, nil, item); err != nil {
+		tx.Rollback()
+		return models.Item{}, err
+	}
+	if err := insertOutboxEvent(tx, models.EventProductAdded, item.ID, pvzID, item); err != nil {
+		tx.Rollback()
+		return models.Item{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Item{}, fmt.Errorf("failed to commit item: %w", err)
+	}
 
 	return item, nil
 }
 
-func (r *ReceptionPostgres) DeleteItem(pvzID uuid.UUID) error {
+// AddItems adds items to an open reception with a single insert. Items get
+// added_at one microsecond apart in the given order so that the last one is
+// removed first. Items whose barcode is already in an open reception are
+// skipped and their barcodes are returned.
+func (r *ReceptionPostgres) AddItems(actor models.Actor, receptionID uuid.UUID, newItems []models.NewItem) ([]models.Item, []string, error) {
+	tx := r.db.MustBegin()
+
+	var pvzID uuid.UUID
+	err := tx.Get(&pvzID, `
+		SELECT pvz_id
+		FROM receptions
+		WHERE id = $1 AND status = 'in_progress'
+		FOR UPDATE
+	`, receptionID)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, nil, fmt.Errorf("%w: reception %s is closed", ErrNoActiveReception, receptionID)
+		}
+		return nil, nil, err
+	}
+
+	var barcodes []string
+	for _, newItem := range newItems {
+		if newItem.Barcode != nil {
+			barcodes = append(barcodes, *newItem.Barcode)
+		}
+	}
+	received, err := lockBarcodes(tx, barcodes)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	if len(received) > 0 {
+		rejected := make(map[string]struct{}, len(received))
+		for _, barcode := range received {
+			rejected[barcode] = struct{}{}
+		}
+		accepted := make([]models.NewItem, 0, len(newItems))
+		for _, newItem := range newItems {
+			if newItem.Barcode != nil {
+				if _, ok := rejected[*newItem.Barcode]; ok {
+					continue
+				}
+			}
+			accepted = append(accepted, newItem)
+		}
+		newItems = accepted
+	}
+	if len(newItems) == 0 {
+		tx.Rollback()
+		return nil, received, nil
+	}
+
+	query := sq.Insert("goods").Columns("reception_id", "type", "added_at", "barcode", "sku", "description")
+	for i, newItem := range newItems {
+		query = query.Values(receptionID, newItem.Type, sq.Expr("NOW() + ? * INTERVAL '1 microsecond'", i),
+			newItem.Barcode, newItem.SKU, newItem.Description)
+	}
+	sqlQuery, args, err := query.
+		Suffix("RETURNING id, reception_id, type, added_at, barcode, sku, description").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	var items []models.Item
+	if err := tx.Select(&items, sqlQuery, args...); err != nil {
+		tx.Rollback()
+		return nil, nil, fmt.Errorf("failed to insert items: %w", err)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].AddedAt.Before(items[j].AddedAt) })
+
+	for _, item := range items {
+		if err := insertAuditEvent(tx, actor, models.AuditProductAdded, item.ID, pvzID, nil, item); err != nil {
+			tx.Rollback()
+			return nil, nil, err
+		}
+		if err := insertOutboxEvent(tx, models.EventProductAdded, item.ID, pvzID, item); err != nil {
+			tx.Rollback()
+			return nil, nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit items: %w", err)
+	}
+
+	return items, received, nil
+}
+
+func (r *ReceptionPostgres) DeleteItem(actor models.Actor, pvzID uuid.UUID) error {
 	tx := r.db.MustBegin()
 
 	var receptionID uuid.UUID
@@ -102,14 +260,14 @@ func (r *ReceptionPostgres) DeleteItem(pvzID uuid.UUID) error {
 	if err != nil {
 		tx.Rollback()
 		if err == sql.ErrNoRows {
-			return fmt.Errorf("no active reception for pvz %s", pvzID.String())
+			return fmt.Errorf("%w for pvz %s", ErrNoActiveReception, pvzID.String())
 		}
 		return err
 	}
 
 	var item models.Item
 	err = tx.Get(&item, `
-		SELECT id, reception_id, type, added_at
+		SELECT id, reception_id, type, added_at, barcode, sku, description
 		FROM goods
 		WHERE reception_id = $1
 		ORDER BY added_at DESC
@@ -117,6 +275,9 @@ func (r *ReceptionPostgres) DeleteItem(pvzID uuid.UUID) error {
 	`, receptionID)
 	if err != nil {
 		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w %s", ErrNoItems, receptionID.String())
+		}
 		return err
 	}
 
@@ -128,10 +289,66 @@ func (r *ReceptionPostgres) DeleteItem(pvzID uuid.UUID) error {
 		tx.Rollback()
 		return err
 	}
+	if err := insertAuditEvent(tx, actor, models.AuditProductDeleted, item.ID, pvzID, item, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := insertOutboxEvent(tx, models.EventProductRemoved, item.ID, pvzID, item); err != nil {
+		tx.Rollback()
+		return err
+	}
 
 	return tx.Commit()
 }
 
+// DeleteItemByID removes a product from an open reception. A product that is
+// not in the reception is reported as a zero Item.
+func (r *ReceptionPostgres) DeleteItemByID(actor models.Actor, receptionID, itemID uuid.UUID) (models.Item, error) {
+	tx := r.db.MustBegin()
+
+	var pvzID uuid.UUID
+	err := tx.Get(&pvzID, `
+		SELECT pvz_id
+		FROM receptions
+		WHERE id = $1 AND status = 'in_progress'
+		FOR UPDATE
+	`, receptionID)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return models.Item{}, fmt.Errorf("%w: reception %s is closed", ErrNoActiveReception, receptionID)
+		}
+		return models.Item{}, err
+	}
+
+	var item models.Item
+	err = tx.Get(&item, `
+		DELETE FROM goods
+		WHERE id = $1 AND reception_id = $2
+		RETURNING id, reception_id, type, added_at, barcode, sku, description
+	`, itemID, receptionID)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return models.Item{}, nil
+		}
+		return models.Item{}, fmt.Errorf("failed to delete item %s: %w", itemID, err)
+	}
+	if err := insertAuditEvent(tx, actor, models.AuditProductDeleted, item.ID, pvzID, item, nil); err != nil {
+		tx.Rollback()
+		return models.Item{}, err
+	}
+	if err := insertOutboxEvent(tx, models.EventProductRemoved, item.ID, pvzID, item); err != nil {
+		tx.Rollback()
+		return models.Item{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Item{}, fmt.Errorf("failed to commit item deletion: %w", err)
+	}
+	return item, nil
+}
+
 func (r *ReceptionPostgres) GetActiveReception(pvzID uuid.UUID) (models.Reception, error) {
 	var reception models.Reception
 	err := r.db.Get(&reception, `
@@ -151,39 +368,69 @@ func (r *ReceptionPostgres) GetActiveReception(pvzID uuid.UUID) (models.Receptio
 	return reception, nil
 }
 
-func (r *ReceptionPostgres) CloseReception(receptionID uuid.UUID) error {
-	res, err := r.db.Exec(`
-		UPDATE receptions
-		SET status = 'closed'
+func (r *ReceptionPostgres) CloseReception(actor models.Actor, receptionID uuid.UUID) error {
+	tx := r.db.MustBegin()
+
+	var before models.Reception
+	err := tx.Get(&before, `
+		SELECT id, pvz_id, status, created_at
+		FROM receptions
 		WHERE id = $1 AND status = 'in_progress'
+		FOR UPDATE
 	`, receptionID)
 	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: reception %s is already closed or does not exist", ErrNoActiveReception, receptionID.String())
+		}
 		return fmt.Errorf("failed to close reception %s: %w", receptionID.String(), err)
 	}
 
-	rowsAffected, err := res.RowsAffected()
+	_, err = tx.Exec(`
+		UPDATE receptions
+		SET status = 'closed'
+		WHERE id = $1
+	`, receptionID)
 	if err != nil {
-		return fmt.Errorf("could not determine result of reception close: %w", err)
+		tx.Rollback()
+		return fmt.Errorf("failed to close reception %s: %w", receptionID.String(), err)
+	}
+
+	after := before
+	after.Status = models.ReceptionStatusClosed
+	if err := insertAuditEvent(tx, actor, models.AuditReceptionClosed, receptionID, before.PVZID, before, after); err != nil {
+		tx.Rollback()
+		return err
 	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("reception %s is already closed or does not exist", receptionID.String())
+	if err := insertOutboxEvent(tx, models.EventReceptionClosed, receptionID, before.PVZID, after); err != nil {
+		tx.Rollback()
+		return err
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reception close: %w", err)
+	}
 	return nil
 }
 
-func (r *ReceptionPostgres) GetReceptionsWithProducts(pvzID uuid.UUID, start, end *time.Time) ([]models.Reception, error) {
+func (r *ReceptionPostgres) GetReceptionsWithProducts(pvzID uuid.UUID, filter models.ReceptionFilter) ([]models.Reception, error) {
 	query := sq.
 		Select("id", "pvz_id", "created_at", "status").
 		From("receptions").
 		Where(sq.Eq{"pvz_id": pvzID}).
 		OrderBy("created_at DESC")
 
-	if start != nil {
-		query = query.Where(sq.GtOrEq{"created_at": *start})
+	if filter.Status != "" {
+		query = query.Where(sq.Eq{"status": filter.Status})
 	}
-	if end != nil {
-		query = query.Where(sq.LtOrEq{"created_at": *end})
+	if filter.Start != nil {
+		query = query.Where(sq.GtOrEq{"created_at": *filter.Start})
+	}
+	if filter.End != nil {
+		query = query.Where(sq.LtOrEq{"created_at": *filter.End})
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(uint64(filter.Limit)).Offset(uint64(filter.Offset))
 	}
 
 	sqlQuery, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
@@ -196,13 +443,177 @@ func (r *ReceptionPostgres) GetReceptionsWithProducts(pvzID uuid.UUID, start, en
 	return receptions, err
 }
 
+func (r *ReceptionPostgres) GetReceptionByID(receptionID uuid.UUID) (models.Reception, error) {
+	var reception models.Reception
+	err := r.db.Get(&reception, `
+		SELECT id, pvz_id, status, created_at
+		FROM receptions
+		WHERE id = $1
+	`, receptionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Reception{}, nil
+		}
+		return models.Reception{}, fmt.Errorf("failed to get reception %s: %w", receptionID.String(), err)
+	}
+
+	return reception, nil
+}
+
 func (r *ReceptionPostgres) GetItemsByReceptionID(receptionID uuid.UUID) ([]models.Item, error) {
 	var items []models.Item
 	err := r.db.Select(&items, `
-        SELECT id, reception_id, type, added_at
+        SELECT id, reception_id, type, added_at, barcode, sku, description
         FROM goods
         WHERE reception_id = $1
         ORDER BY added_at ASC
     `, receptionID)
 	return items, err
 }
+
+// GetItemsByReceptionIDs returns the goods of all given receptions in one
+// query, oldest first.
+func (r *ReceptionPostgres) GetItemsByReceptionIDs(receptionIDs []uuid.UUID) ([]models.Item, error) {
+	if len(receptionIDs) == 0 {
+		return nil, nil
+	}
+
+	var items []models.Item
+	err := r.db.Select(&items, `
+		SELECT id, reception_id, type, added_at, barcode, sku, description
+		FROM goods
+		WHERE reception_id = ANY($1)
+		ORDER BY added_at ASC
+	`, pq.Array(uuidStrings(receptionIDs)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goods: %w", err)
+	}
+	return items, nil
+}
+
+// GetLatestItemByBarcode returns the item with barcode that was received
+// last, or a zero Item if the barcode was never received. Items received in
+// a deactivated PVZ are skipped unless includeDeactivated is set.
+func (r *ReceptionPostgres) GetLatestItemByBarcode(barcode string, includeDeactivated bool) (models.Item, error) {
+	query := sq.
+		Select("g.id", "g.reception_id", "g.type", "g.added_at", "g.barcode", "g.sku", "g.description").
+		From("goods g").
+		Join("receptions r ON r.id = g.reception_id").
+		Join("pvz p ON p.id = r.pvz_id").
+		Where(sq.Eq{"g.barcode": barcode})
+	if !includeDeactivated {
+		query = query.Where(sq.Eq{"p.deactivated_at": nil})
+	}
+
+	sqlQuery, args, err := query.
+		OrderBy("g.added_at DESC").
+		Limit(1).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return models.Item{}, err
+	}
+
+	var item models.Item
+	err = r.db.Get(&item, sqlQuery, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Item{}, nil
+		}
+		return models.Item{}, fmt.Errorf("failed to get item by barcode: %w", err)
+	}
+	return item, nil
+}
+
+func (r *ReceptionPostgres) GetReceptionBlocksByPVZIDs(pvzIDs []uuid.UUID, start, end *time.Time) ([]models.ReceptionBlock, error) {
+	if len(pvzIDs) == 0 {
+		return nil, nil
+	}
+
+	query := sq.
+		Select("id", "pvz_id", "created_at", "status").
+		From("receptions").
+		Where(sq.Expr("pvz_id = ANY(?)", pq.Array(uuidStrings(pvzIDs)))).
+		OrderBy("created_at DESC")
+
+	if start != nil {
+		query = query.Where(sq.GtOrEq{"created_at": *start})
+	}
+	if end != nil {
+		query = query.Where(sq.LtOrEq{"created_at": *end})
+	}
+
+	sqlQuery, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var receptions []models.Reception
+	if err := r.db.Select(&receptions, sqlQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to get receptions: %w", err)
+	}
+	if len(receptions) == 0 {
+		return nil, nil
+	}
+
+	receptionIDs := make([]uuid.UUID, 0, len(receptions))
+	for _, reception := range receptions {
+		receptionIDs = append(receptionIDs, reception.ID)
+	}
+
+	items, err := r.GetItemsByReceptionIDs(receptionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	itemsByReception := make(map[uuid.UUID][]models.Item, len(receptions))
+	for _, item := range items {
+		itemsByReception[item.ReceptionID] = append(itemsByReception[item.ReceptionID], item)
+	}
+
+	blocks := make([]models.ReceptionBlock, 0, len(receptions))
+	for _, reception := range receptions {
+		blocks = append(blocks, models.ReceptionBlock{
+			Reception: reception,
+			Products:  itemsByReception[reception.ID],
+		})
+	}
+
+	return blocks, nil
+}
+
+// lockBarcodes makes receptions of the same barcode wait for each other and
+// returns the barcodes that are already in an open reception. Locks are
+// taken in sorted order so that concurrent batches do not deadlock.
+func lockBarcodes(tx *sqlx.Tx, barcodes []string) ([]string, error) {
+	if len(barcodes) == 0 {
+		return nil, nil
+	}
+	sorted := append([]string(nil), barcodes...)
+	sort.Strings(sorted)
+	for _, barcode := range sorted {
+		if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, barcode); err != nil {
+			return nil, fmt.Errorf("failed to lock barcode %s: %w", barcode, err)
+		}
+	}
+
+	var received []string
+	err := tx.Select(&received, `
+		SELECT DISTINCT g.barcode
+		FROM goods g
+		JOIN receptions r ON r.id = g.reception_id
+		WHERE g.barcode = ANY($1) AND r.status = 'in_progress'
+	`, pq.Array(sorted))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check barcodes: %w", err)
+	}
+	return received, nil
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, id.String())
+	}
+	return result
+}
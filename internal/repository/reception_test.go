@@ -2,7 +2,7 @@ package repository_test
 
 import (
 	"database/sql"
-	"fmt"
+	"errors"
 	"pvz-test/internal/models"
 	"pvz-test/internal/repository"
 	"testing"
@@ -11,6 +11,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -32,6 +33,9 @@ func TestReceptionPostgres_CreateReception(t *testing.T) {
 		}
 
 		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT deactivated_at FROM pvz WHERE id = \$1 FOR SHARE`).
+			WithArgs(pvzID).
+			WillReturnRows(sqlmock.NewRows([]string{"deactivated_at"}).AddRow(nil))
 		mock.ExpectQuery(`SELECT EXISTS \( SELECT 1 FROM receptions WHERE pvz_id = \$1 AND status = 'in_progress' \)`).
 			WithArgs(pvzID).
 			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
@@ -39,9 +43,13 @@ func TestReceptionPostgres_CreateReception(t *testing.T) {
 			WithArgs(pvzID).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "pvz_id", "status", "created_at"}).
 				AddRow(expectedReception.ID, expectedReception.PVZID, expectedReception.Status, expectedReception.CreatedAt))
+		expectAudit(mock, models.AuditReceptionCreated, expectedReception.ID, pvzID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectOutbox(mock, models.EventReceptionOpened, expectedReception.ID, pvzID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectCommit()
 
-		reception, err := repo.CreateReception(pvzID)
+		reception, err := repo.CreateReception(testActor, pvzID)
 		assert.NoError(t, err)
 		assert.Equal(t, expectedReception, reception)
 	})
@@ -50,13 +58,50 @@ func TestReceptionPostgres_CreateReception(t *testing.T) {
 		pvzID := uuid.New()
 
 		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT deactivated_at FROM pvz WHERE id = \$1 FOR SHARE`).
+			WithArgs(pvzID).
+			WillReturnRows(sqlmock.NewRows([]string{"deactivated_at"}).AddRow(nil))
 		mock.ExpectQuery(`SELECT EXISTS \( SELECT 1 FROM receptions WHERE pvz_id = \$1 AND status = 'in_progress' \)`).
 			WithArgs(pvzID).
 			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
 		mock.ExpectRollback()
 
-		_, err := repo.CreateReception(pvzID)
-		assert.EqualError(t, err, fmt.Sprintf("pvz: %s have active reception", pvzID.String()))
+		_, err := repo.CreateReception(testActor, pvzID)
+		assert.ErrorIs(t, err, repository.ErrReceptionInProgress)
+	})
+
+	t.Run("Concurrent reception rejected by index", func(t *testing.T) {
+		pvzID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT deactivated_at FROM pvz WHERE id = \$1 FOR SHARE`).
+			WithArgs(pvzID).
+			WillReturnRows(sqlmock.NewRows([]string{"deactivated_at"}).AddRow(nil))
+		mock.ExpectQuery(`SELECT EXISTS \( SELECT 1 FROM receptions WHERE pvz_id = \$1 AND status = 'in_progress' \)`).
+			WithArgs(pvzID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery(`INSERT INTO receptions`).
+			WithArgs(pvzID).
+			WillReturnError(&pq.Error{Code: "23505"})
+		mock.ExpectRollback()
+
+		_, err := repo.CreateReception(testActor, pvzID)
+		assert.ErrorIs(t, err, repository.ErrReceptionInProgress)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("PVZ deactivated", func(t *testing.T) {
+		pvzID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT deactivated_at FROM pvz WHERE id = \$1 FOR SHARE`).
+			WithArgs(pvzID).
+			WillReturnRows(sqlmock.NewRows([]string{"deactivated_at"}).AddRow(time.Now()))
+		mock.ExpectRollback()
+
+		_, err := repo.CreateReception(testActor, pvzID)
+		assert.ErrorIs(t, err, repository.ErrPvzDeactivated)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
@@ -110,24 +155,39 @@ func TestReceptionPostgres_CloseReception(t *testing.T) {
 
 	t.Run("Successful closure", func(t *testing.T) {
 		receptionID := uuid.New()
+		pvzID := uuid.New()
 
-		mock.ExpectExec(`UPDATE receptions SET status = 'closed' WHERE id = \$1 AND status = 'in_progress'`).
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, pvz_id, status, created_at FROM receptions WHERE id = \$1 AND status = 'in_progress' FOR UPDATE`).
 			WithArgs(receptionID).
-			WillReturnResult(sqlmock.NewResult(1, 1))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "pvz_id", "status", "created_at"}).
+				AddRow(receptionID, pvzID, "in_progress", time.Now()))
+		mock.ExpectExec(`UPDATE receptions SET status = 'closed' WHERE id = \$1`).
+			WithArgs(receptionID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectAudit(mock, models.AuditReceptionClosed, receptionID, pvzID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectOutbox(mock, models.EventReceptionClosed, receptionID, pvzID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
 
-		err := repo.CloseReception(receptionID)
+		err := repo.CloseReception(testActor, receptionID)
 		assert.NoError(t, err)
 	})
 
 	t.Run("Reception already closed", func(t *testing.T) {
 		receptionID := uuid.New()
 
-		mock.ExpectExec(`UPDATE receptions SET status = 'closed' WHERE id = \$1 AND status = 'in_progress'`).
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, pvz_id, status, created_at FROM receptions WHERE id = \$1 AND status = 'in_progress' FOR UPDATE`).
 			WithArgs(receptionID).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
 
-		err := repo.CloseReception(receptionID)
-		assert.EqualError(t, err, "reception "+receptionID.String()+" is already closed or does not exist")
+		err := repo.CloseReception(testActor, receptionID)
+		assert.ErrorIs(t, err, repository.ErrNoActiveReception)
+		assert.EqualError(t, err, "no active reception: reception "+receptionID.String()+" is already closed or does not exist")
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
@@ -154,12 +214,13 @@ func TestReceptionPostgres_AddItem_Good(t *testing.T) {
 
 	mock.ExpectBegin()
 
-	mock.ExpectQuery(`SELECT id FROM receptions WHERE pvz_id = \$1 AND status = 'in_progress' ORDER BY created_at DESC LIMIT 1`).
+	mock.ExpectQuery(`SELECT id FROM receptions WHERE pvz_id = \$1 AND status = 'in_progress' ORDER BY created_at DESC LIMIT 1 FOR UPDATE`).
 		WithArgs(pvzID).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(receptionID))
 
-	mock.ExpectQuery(`INSERT INTO goods \(reception_id, type, added_at\) VALUES \(\$1, \$2, NOW\(\)\) RETURNING \*`).
-		WithArgs(receptionID, expectedItem.Type).
+	mock.ExpectQuery(`INSERT INTO goods \(reception_id, type, added_at, barcode, sku, description\) VALUES \(\$1, \$2, NOW\(\), \$3, \$4, \$5\) `+
+		`RETURNING id, reception_id, type, added_at, barcode, sku, description`).
+		WithArgs(receptionID, expectedItem.Type, nil, nil, nil).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "reception_id", "type", "added_at"}).
 			AddRow(
 				expectedItem.ID,
@@ -168,15 +229,21 @@ func TestReceptionPostgres_AddItem_Good(t *testing.T) {
 				expectedItem.AddedAt,
 			))
 
+	expectAudit(mock, models.AuditProductAdded, itemID, pvzID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectOutbox(mock, models.EventProductAdded, itemID, pvzID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
 	mock.ExpectCommit()
 
-	item, err := repo.AddItem(pvzID, string(expectedItem.Type))
+	item, err := repo.AddItem(testActor, pvzID, string(expectedItem.Type), models.ItemDetails{})
 	assert.NoError(t, err, "unexpected error: %v", err)
 
 	assert.Equal(t, expectedItem.ID, item.ID)
 	assert.Equal(t, expectedItem.ReceptionID, item.ReceptionID)
 	assert.Equal(t, expectedItem.Type, item.Type)
 	assert.WithinDuration(t, expectedItem.AddedAt, item.AddedAt, time.Second)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestReceptionPostgres_AddItem_Bad(t *testing.T) {
@@ -197,9 +264,166 @@ func TestReceptionPostgres_AddItem_Bad(t *testing.T) {
 			WillReturnError(sql.ErrNoRows)
 		mock.ExpectRollback()
 
-		_, err := repo.AddItem(pvzID, itemType)
+		_, err := repo.AddItem(testActor, pvzID, itemType, models.ItemDetails{})
 		assert.EqualError(t, err, "no active reception for PVZ "+pvzID.String())
 	})
+
+	t.Run("Commit failure", func(t *testing.T) {
+		pvzID := uuid.New()
+		receptionID := uuid.New()
+		itemID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM receptions WHERE pvz_id = \$1 AND status = 'in_progress' ORDER BY created_at DESC LIMIT 1 FOR UPDATE`).
+			WithArgs(pvzID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(receptionID))
+		mock.ExpectQuery(`INSERT INTO goods`).
+			WithArgs(receptionID, "shoes", nil, nil, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "reception_id", "type", "added_at"}).
+				AddRow(itemID, receptionID, "shoes", time.Now()))
+		expectAudit(mock, models.AuditProductAdded, itemID, pvzID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectOutbox(mock, models.EventProductAdded, itemID, pvzID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit().WillReturnError(errors.New("connection lost"))
+
+		_, err := repo.AddItem(testActor, pvzID, "shoes", models.ItemDetails{})
+		assert.ErrorContains(t, err, "connection lost")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReceptionPostgres_AddItems(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewReceptionPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	t.Run("Items are inserted in one statement", func(t *testing.T) {
+		pvzID, receptionID := uuid.New(), uuid.New()
+		barcode, sku := "4006381333931", "SH-42"
+		addedAt := time.Date(2025, 4, 16, 22, 29, 15, 0, time.UTC)
+		shoes := models.Item{ID: uuid.New(), ReceptionID: receptionID, Type: models.ItemTypeShoes, AddedAt: addedAt,
+			ItemDetails: models.ItemDetails{Barcode: &barcode, SKU: &sku}}
+		clothing := models.Item{ID: uuid.New(), ReceptionID: receptionID, Type: models.ItemTypeClothing, AddedAt: addedAt.Add(time.Microsecond)}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT pvz_id FROM receptions WHERE id = \$1 AND status = 'in_progress' FOR UPDATE`).
+			WithArgs(receptionID).
+			WillReturnRows(sqlmock.NewRows([]string{"pvz_id"}).AddRow(pvzID))
+		mock.ExpectExec(`SELECT pg_advisory_xact_lock\(hashtext\(\$1\)\)`).
+			WithArgs(barcode).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`SELECT DISTINCT g.barcode FROM goods g JOIN receptions r ON r.id = g.reception_id WHERE g.barcode = ANY\(\$1\) AND r.status = 'in_progress'`).
+			WillReturnRows(sqlmock.NewRows([]string{"barcode"}))
+		mock.ExpectQuery(`INSERT INTO goods \(reception_id,type,added_at,barcode,sku,description\) `+
+			`VALUES \(\$1,\$2,NOW\(\) \+ \$3 \* INTERVAL '1 microsecond',\$4,\$5,\$6\),\(\$7,\$8,NOW\(\) \+ \$9 \* INTERVAL '1 microsecond',\$10,\$11,\$12\) `+
+			`RETURNING id, reception_id, type, added_at, barcode, sku, description`).
+			WithArgs(receptionID, models.ItemTypeShoes, 0, barcode, sku, nil, receptionID, models.ItemTypeClothing, 1, nil, nil, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "reception_id", "type", "added_at", "barcode", "sku", "description"}).
+				AddRow(clothing.ID, clothing.ReceptionID, clothing.Type, clothing.AddedAt, nil, nil, nil).
+				AddRow(shoes.ID, shoes.ReceptionID, shoes.Type, shoes.AddedAt, barcode, sku, nil))
+		for _, item := range []models.Item{shoes, clothing} {
+			expectAudit(mock, models.AuditProductAdded, item.ID, pvzID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			expectOutbox(mock, models.EventProductAdded, item.ID, pvzID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectCommit()
+
+		items, received, err := repo.AddItems(testActor, receptionID, []models.NewItem{
+			{Type: models.ItemTypeShoes, ItemDetails: models.ItemDetails{Barcode: &barcode, SKU: &sku}},
+			{Type: models.ItemTypeClothing},
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, received)
+		assert.Equal(t, []models.Item{shoes, clothing}, items)
+	})
+
+	t.Run("Barcode in an open reception is skipped", func(t *testing.T) {
+		pvzID, receptionID := uuid.New(), uuid.New()
+		first, second := "CODE-B", "CODE-A"
+		added := models.Item{ID: uuid.New(), ReceptionID: receptionID, Type: models.ItemTypeShoes, AddedAt: time.Now().UTC(),
+			ItemDetails: models.ItemDetails{Barcode: &second}}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT pvz_id FROM receptions`).
+			WithArgs(receptionID).
+			WillReturnRows(sqlmock.NewRows([]string{"pvz_id"}).AddRow(pvzID))
+		mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WithArgs(second).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WithArgs(first).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`SELECT DISTINCT g.barcode FROM goods g`).
+			WillReturnRows(sqlmock.NewRows([]string{"barcode"}).AddRow(first))
+		mock.ExpectQuery(`INSERT INTO goods \(reception_id,type,added_at,barcode,sku,description\) `+
+			`VALUES \(\$1,\$2,NOW\(\) \+ \$3 \* INTERVAL '1 microsecond',\$4,\$5,\$6\) RETURNING`).
+			WithArgs(receptionID, models.ItemTypeShoes, 0, second, nil, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "reception_id", "type", "added_at", "barcode", "sku", "description"}).
+				AddRow(added.ID, added.ReceptionID, added.Type, added.AddedAt, second, nil, nil))
+		expectAudit(mock, models.AuditProductAdded, added.ID, pvzID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectOutbox(mock, models.EventProductAdded, added.ID, pvzID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		items, received, err := repo.AddItems(testActor, receptionID, []models.NewItem{
+			{Type: models.ItemTypeShoes, ItemDetails: models.ItemDetails{Barcode: &first}},
+			{Type: models.ItemTypeShoes, ItemDetails: models.ItemDetails{Barcode: &second}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{first}, received)
+		assert.Equal(t, []models.Item{added}, items)
+	})
+
+	t.Run("Every barcode in an open reception", func(t *testing.T) {
+		receptionID := uuid.New()
+		barcode := "CODE-A"
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT pvz_id FROM receptions`).
+			WithArgs(receptionID).
+			WillReturnRows(sqlmock.NewRows([]string{"pvz_id"}).AddRow(uuid.New()))
+		mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WithArgs(barcode).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`SELECT DISTINCT g.barcode FROM goods g`).
+			WillReturnRows(sqlmock.NewRows([]string{"barcode"}).AddRow(barcode))
+		mock.ExpectRollback()
+
+		items, received, err := repo.AddItems(testActor, receptionID, []models.NewItem{
+			{Type: models.ItemTypeShoes, ItemDetails: models.ItemDetails{Barcode: &barcode}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{barcode}, received)
+		assert.Empty(t, items)
+	})
+
+	t.Run("Reception closed", func(t *testing.T) {
+		receptionID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT pvz_id FROM receptions`).
+			WithArgs(receptionID).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, _, err := repo.AddItems(testActor, receptionID, []models.NewItem{{Type: models.ItemTypeShoes}})
+		assert.ErrorIs(t, err, repository.ErrNoActiveReception)
+	})
+
+	t.Run("Insert failure rolls back", func(t *testing.T) {
+		receptionID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT pvz_id FROM receptions`).
+			WithArgs(receptionID).
+			WillReturnRows(sqlmock.NewRows([]string{"pvz_id"}).AddRow(uuid.New()))
+		mock.ExpectQuery(`INSERT INTO goods`).WillReturnError(errors.New("db error"))
+		mock.ExpectRollback()
+
+		_, _, err := repo.AddItems(testActor, receptionID, []models.NewItem{{Type: models.ItemTypeShoes}})
+		assert.ErrorContains(t, err, "failed to insert items")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestReceptionPostgres_DeleteItem(t *testing.T) {
@@ -221,7 +445,7 @@ func TestReceptionPostgres_DeleteItem(t *testing.T) {
 			WithArgs(pvzID).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(receptionID))
 
-		mock.ExpectQuery(`SELECT id, reception_id, type, added_at FROM goods WHERE reception_id = \$1 ORDER BY added_at DESC LIMIT 1`).
+		mock.ExpectQuery(`SELECT id, reception_id, type, added_at, barcode, sku, description FROM goods WHERE reception_id = \$1 ORDER BY added_at DESC LIMIT 1`).
 			WithArgs(receptionID).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "reception_id", "type", "added_at"}).
 				AddRow(
@@ -234,9 +458,13 @@ func TestReceptionPostgres_DeleteItem(t *testing.T) {
 		mock.ExpectExec(`DELETE FROM goods WHERE id = \$1`).
 			WithArgs(itemID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectAudit(mock, models.AuditProductDeleted, itemID, pvzID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectOutbox(mock, models.EventProductRemoved, itemID, pvzID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectCommit()
 
-		err := repo.DeleteItem(pvzID)
+		err := repo.DeleteItem(testActor, pvzID)
 		assert.NoError(t, err)
 	})
 
@@ -249,11 +477,74 @@ func TestReceptionPostgres_DeleteItem(t *testing.T) {
 			WillReturnError(sql.ErrNoRows)
 		mock.ExpectRollback()
 
-		err := repo.DeleteItem(pvzID)
+		err := repo.DeleteItem(testActor, pvzID)
 		assert.EqualError(t, err, "no active reception for pvz "+pvzID.String())
 	})
 }
 
+func TestReceptionPostgres_DeleteItemByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewReceptionPostgres(sqlx.NewDb(db, "sqlmock"))
+	columns := []string{"id", "reception_id", "type", "added_at"}
+
+	t.Run("Product deleted", func(t *testing.T) {
+		pvzID, receptionID := uuid.New(), uuid.New()
+		item := models.Item{ID: uuid.New(), ReceptionID: receptionID, Type: models.ItemTypeShoes, AddedAt: time.Now()}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT pvz_id FROM receptions WHERE id = \$1 AND status = 'in_progress' FOR UPDATE`).
+			WithArgs(receptionID).
+			WillReturnRows(sqlmock.NewRows([]string{"pvz_id"}).AddRow(pvzID))
+		mock.ExpectQuery(`DELETE FROM goods WHERE id = \$1 AND reception_id = \$2 RETURNING id, reception_id, type, added_at, barcode, sku, description`).
+			WithArgs(item.ID, receptionID).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(item.ID, item.ReceptionID, item.Type, item.AddedAt))
+		expectAudit(mock, models.AuditProductDeleted, item.ID, pvzID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectOutbox(mock, models.EventProductRemoved, item.ID, pvzID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		deleted, err := repo.DeleteItemByID(testActor, receptionID, item.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, item, deleted)
+	})
+
+	t.Run("Product not in reception", func(t *testing.T) {
+		receptionID, itemID := uuid.New(), uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT pvz_id FROM receptions`).
+			WithArgs(receptionID).
+			WillReturnRows(sqlmock.NewRows([]string{"pvz_id"}).AddRow(uuid.New()))
+		mock.ExpectQuery(`DELETE FROM goods`).
+			WithArgs(itemID, receptionID).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		deleted, err := repo.DeleteItemByID(testActor, receptionID, itemID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.Item{}, deleted)
+	})
+
+	t.Run("Reception closed", func(t *testing.T) {
+		receptionID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT pvz_id FROM receptions`).
+			WithArgs(receptionID).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err := repo.DeleteItemByID(testActor, receptionID, uuid.New())
+		assert.ErrorIs(t, err, repository.ErrNoActiveReception)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestReceptionPostgres_GetReceptionsWithProducts(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -273,10 +564,65 @@ func TestReceptionPostgres_GetReceptionsWithProducts(t *testing.T) {
 			WillReturnRows(sqlmock.NewRows([]string{"id", "pvz_id", "created_at", "status"}).
 				AddRow(expectedReceptions[0].ID, expectedReceptions[0].PVZID, expectedReceptions[0].CreatedAt, expectedReceptions[0].Status))
 
-		receptions, err := repo.GetReceptionsWithProducts(pvzID, nil, nil)
+		receptions, err := repo.GetReceptionsWithProducts(pvzID, models.ReceptionFilter{})
 		assert.NoError(t, err)
 		assert.Equal(t, expectedReceptions, receptions)
 	})
+
+	t.Run("Status, dates and page", func(t *testing.T) {
+		pvzID := uuid.New()
+		start, end := time.Now().Add(-time.Hour), time.Now()
+
+		mock.ExpectQuery(`SELECT id, pvz_id, created_at, status FROM receptions WHERE pvz_id = \$1 AND status = \$2 AND created_at >= \$3 AND created_at <= \$4 ORDER BY created_at DESC LIMIT 5 OFFSET 10`).
+			WithArgs(pvzID, "closed", start, end).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "pvz_id", "created_at", "status"}))
+
+		receptions, err := repo.GetReceptionsWithProducts(pvzID, models.ReceptionFilter{
+			Status: "closed",
+			Start:  &start,
+			End:    &end,
+			Limit:  5,
+			Offset: 10,
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, receptions)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReceptionPostgres_GetReceptionByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := repository.NewReceptionPostgres(sqlxDB)
+
+	t.Run("Reception found", func(t *testing.T) {
+		expectedReception := models.Reception{ID: uuid.New(), PVZID: uuid.New(), Status: "closed", CreatedAt: time.Now()}
+
+		mock.ExpectQuery(`SELECT id, pvz_id, status, created_at FROM receptions WHERE id = \$1`).
+			WithArgs(expectedReception.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "pvz_id", "status", "created_at"}).
+				AddRow(expectedReception.ID, expectedReception.PVZID, expectedReception.Status, expectedReception.CreatedAt))
+
+		reception, err := repo.GetReceptionByID(expectedReception.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedReception, reception)
+	})
+
+	t.Run("Reception not found", func(t *testing.T) {
+		receptionID := uuid.New()
+
+		mock.ExpectQuery(`SELECT id, pvz_id, status, created_at FROM receptions WHERE id = \$1`).
+			WithArgs(receptionID).
+			WillReturnError(sql.ErrNoRows)
+
+		reception, err := repo.GetReceptionByID(receptionID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.Reception{}, reception)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestReceptionPostgres_GetItemsByReceptionID(t *testing.T) {
@@ -293,7 +639,7 @@ func TestReceptionPostgres_GetItemsByReceptionID(t *testing.T) {
 			{ID: uuid.New(), ReceptionID: receptionID, Type: models.ItemTypeElectronics, AddedAt: time.Now()},
 		}
 
-		mock.ExpectQuery(`SELECT id, reception_id, type, added_at FROM goods WHERE reception_id = \$1 ORDER BY added_at ASC`).
+		mock.ExpectQuery(`SELECT id, reception_id, type, added_at, barcode, sku, description FROM goods WHERE reception_id = \$1 ORDER BY added_at ASC`).
 			WithArgs(receptionID).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "reception_id", "type", "added_at"}).
 				AddRow(
@@ -308,3 +654,104 @@ func TestReceptionPostgres_GetItemsByReceptionID(t *testing.T) {
 		assert.Equal(t, expectedItems, items)
 	})
 }
+
+func TestReceptionPostgres_GetLatestItemByBarcode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewReceptionPostgres(sqlx.NewDb(db, "sqlmock"))
+	barcode := "4006381333931"
+
+	t.Run("Found", func(t *testing.T) {
+		item := models.Item{ID: uuid.New(), ReceptionID: uuid.New(), Type: models.ItemTypeElectronics, AddedAt: time.Now(),
+			ItemDetails: models.ItemDetails{Barcode: &barcode}}
+		mock.ExpectQuery(`SELECT g.id, g.reception_id, g.type, g.added_at, g.barcode, g.sku, g.description FROM goods g JOIN receptions r ON r.id = g.reception_id JOIN pvz p ON p.id = r.pvz_id WHERE g.barcode = \$1 AND p.deactivated_at IS NULL ORDER BY g.added_at DESC LIMIT 1`).
+			WithArgs(barcode).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "reception_id", "type", "added_at", "barcode", "sku", "description"}).
+				AddRow(item.ID, item.ReceptionID, item.Type, item.AddedAt, barcode, nil, nil))
+
+		found, err := repo.GetLatestItemByBarcode(barcode, false)
+		assert.NoError(t, err)
+		assert.Equal(t, item, found)
+	})
+
+	t.Run("Including deactivated PVZ", func(t *testing.T) {
+		mock.ExpectQuery(`FROM goods g JOIN receptions r ON r.id = g.reception_id JOIN pvz p ON p.id = r.pvz_id WHERE g.barcode = \$1 ORDER BY g.added_at DESC LIMIT 1`).
+			WithArgs(barcode).
+			WillReturnError(sql.ErrNoRows)
+
+		found, err := repo.GetLatestItemByBarcode(barcode, true)
+		assert.NoError(t, err)
+		assert.Equal(t, models.Item{}, found)
+	})
+
+	t.Run("Never received", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT g.id, g.reception_id, g.type, g.added_at, g.barcode, g.sku, g.description FROM goods g`).
+			WithArgs(barcode).
+			WillReturnError(sql.ErrNoRows)
+
+		found, err := repo.GetLatestItemByBarcode(barcode, false)
+		assert.NoError(t, err)
+		assert.Equal(t, models.Item{}, found)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReceptionPostgres_GetReceptionBlocksByPVZIDs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := repository.NewReceptionPostgres(sqlxDB)
+
+	t.Run("Receptions with goods", func(t *testing.T) {
+		pvzIDs := []uuid.UUID{uuid.New(), uuid.New()}
+		firstReception := models.Reception{ID: uuid.New(), PVZID: pvzIDs[0], Status: "closed", CreatedAt: time.Now()}
+		secondReception := models.Reception{ID: uuid.New(), PVZID: pvzIDs[1], Status: "in_progress", CreatedAt: time.Now()}
+		firstItem := models.Item{ID: uuid.New(), ReceptionID: firstReception.ID, Type: models.ItemTypeShoes, AddedAt: time.Now()}
+		secondItem := models.Item{ID: uuid.New(), ReceptionID: firstReception.ID, Type: models.ItemTypeClothing, AddedAt: time.Now()}
+
+		mock.ExpectQuery(`SELECT id, pvz_id, created_at, status FROM receptions WHERE pvz_id = ANY\(\$1\) ORDER BY created_at DESC`).
+			WithArgs(pq.Array([]string{pvzIDs[0].String(), pvzIDs[1].String()})).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "pvz_id", "created_at", "status"}).
+				AddRow(firstReception.ID, firstReception.PVZID, firstReception.CreatedAt, firstReception.Status).
+				AddRow(secondReception.ID, secondReception.PVZID, secondReception.CreatedAt, secondReception.Status))
+		mock.ExpectQuery(`SELECT id, reception_id, type, added_at, barcode, sku, description FROM goods WHERE reception_id = ANY\(\$1\) ORDER BY added_at ASC`).
+			WithArgs(pq.Array([]string{firstReception.ID.String(), secondReception.ID.String()})).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "reception_id", "type", "added_at"}).
+				AddRow(firstItem.ID, firstItem.ReceptionID, firstItem.Type, firstItem.AddedAt).
+				AddRow(secondItem.ID, secondItem.ReceptionID, secondItem.Type, secondItem.AddedAt))
+
+		blocks, err := repo.GetReceptionBlocksByPVZIDs(pvzIDs, nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.ReceptionBlock{
+			{Reception: firstReception, Products: []models.Item{firstItem, secondItem}},
+			{Reception: secondReception},
+		}, blocks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No receptions in window", func(t *testing.T) {
+		pvzIDs := []uuid.UUID{uuid.New()}
+		start := time.Now().Add(-time.Hour)
+
+		mock.ExpectQuery(`SELECT id, pvz_id, created_at, status FROM receptions WHERE pvz_id = ANY\(\$1\) AND created_at >= \$2 ORDER BY created_at DESC`).
+			WithArgs(pq.Array([]string{pvzIDs[0].String()}), start).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "pvz_id", "created_at", "status"}))
+
+		blocks, err := repo.GetReceptionBlocksByPVZIDs(pvzIDs, &start, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, blocks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Empty PVZ set", func(t *testing.T) {
+		blocks, err := repo.GetReceptionBlocksByPVZIDs(nil, nil, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, blocks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
 	"pvz-test/internal/models"
 	"time"
 
@@ -12,34 +14,121 @@ type UserRepository interface {
 	CreateUser(user models.RegisterRequest) (uuid.UUID, error)
 	GetUserByEmail(email string) (models.User, error)
 	GetUserById(userID uuid.UUID) (models.User, error)
+	UpdatePasswordHash(userID uuid.UUID, passwordHash string) error
 }
 
 type PvzRepository interface {
-	CreatePvz(city string) (models.PVZ, error)
+	CreatePvz(actor models.Actor, city string) (models.PVZ, error)
 	Exists(pvzID uuid.UUID) (bool, error)
-	GetPVZList(limit, offset int) ([]models.PVZ, error)
+	GetPVZList(status models.PVZStatus, limit, offset int) ([]models.PVZ, error)
+	GetPVZListWithReceptions(start, end *time.Time, status models.PVZStatus, limit, offset int) ([]models.PVZ, error)
+	GetAllPVZ() ([]models.PVZ, error)
+	GetPVZByID(pvzID uuid.UUID) (models.PVZ, error)
+	UpdatePVZ(actor models.Actor, pvzID uuid.UUID, city string) (models.PVZ, error)
+	DeactivatePVZ(actor models.Actor, pvzID uuid.UUID) (models.PVZ, error)
 }
 
 type ReceptionRepository interface {
-	AddItem(pvzID uuid.UUID, itemType string) (models.Item, error)
-	DeleteItem(pvzID uuid.UUID) error
-	CreateReception(pvzID uuid.UUID) (models.Reception, error)
+	AddItem(actor models.Actor, pvzID uuid.UUID, itemType string, details models.ItemDetails) (models.Item, error)
+	AddItems(actor models.Actor, receptionID uuid.UUID, newItems []models.NewItem) ([]models.Item, []string, error)
+	DeleteItem(actor models.Actor, pvzID uuid.UUID) error
+	DeleteItemByID(actor models.Actor, receptionID, itemID uuid.UUID) (models.Item, error)
+	CreateReception(actor models.Actor, pvzID uuid.UUID) (models.Reception, error)
 	GetActiveReception(pvzID uuid.UUID) (models.Reception, error)
-	CloseReception(receptionID uuid.UUID) error
-	GetReceptionsWithProducts(pvzID uuid.UUID, start, end *time.Time) ([]models.Reception, error)
+	CloseReception(actor models.Actor, receptionID uuid.UUID) error
+	GetReceptionsWithProducts(pvzID uuid.UUID, filter models.ReceptionFilter) ([]models.Reception, error)
+	GetReceptionByID(receptionID uuid.UUID) (models.Reception, error)
 	GetItemsByReceptionID(receptionID uuid.UUID) ([]models.Item, error)
+	GetItemsByReceptionIDs(receptionIDs []uuid.UUID) ([]models.Item, error)
+	GetLatestItemByBarcode(barcode string, includeDeactivated bool) (models.Item, error)
+	GetReceptionBlocksByPVZIDs(pvzIDs []uuid.UUID, start, end *time.Time) ([]models.ReceptionBlock, error)
+}
+
+type TokenRepository interface {
+	CreateRefreshToken(token models.RefreshToken) error
+	RotateRefreshToken(tokenHash string, next models.RefreshToken) (models.RefreshToken, error)
+	RevokeRefreshTokenFamily(tokenHash string) error
+	RevokeAccessToken(jti uuid.UUID, expiresAt time.Time) error
+	GetRevokedAccessTokens() ([]models.RevokedToken, error)
+}
+
+type CityRepository interface {
+	CreateCity(name string) (models.City, error)
+	GetCities() ([]models.City, error)
+	GetCityByID(cityID uuid.UUID) (models.City, error)
+	UpdateCity(cityID uuid.UUID, name string) (models.City, error)
+	DeleteCity(cityID uuid.UUID) (bool, error)
+}
+
+type AssignmentRepository interface {
+	AssignEmployee(userID, pvzID uuid.UUID) (models.EmployeeAssignment, error)
+	UnassignEmployee(userID, pvzID uuid.UUID) (bool, error)
+	IsAssigned(userID, pvzID uuid.UUID) (bool, error)
+	GetPVZEmployees(pvzID uuid.UUID) ([]models.EmployeeAssignment, error)
+}
+
+type AuditRepository interface {
+	GetAuditEvents(filter models.AuditFilter) ([]models.AuditEvent, error)
+}
+
+type OutboxRepository interface {
+	ClaimEvents(limit int, lease time.Duration) ([]models.Event, error)
+	MarkDelivered(eventID uuid.UUID) error
+	MarkFailed(eventID uuid.UUID, reason string, retryAt time.Time) error
+}
+
+type WebhookRepository interface {
+	CreateWebhook(webhook models.Webhook) (models.Webhook, error)
+	GetWebhooks() ([]models.Webhook, error)
+	GetWebhookByID(webhookID uuid.UUID) (models.Webhook, error)
+	DeleteWebhook(webhookID uuid.UUID) (bool, error)
+	EnqueueDeliveries(event models.Event) (int64, error)
+	ClaimDeliveries(limit int, lease time.Duration) ([]models.WebhookAttempt, error)
+	MarkDeliveryDelivered(deliveryID uuid.UUID, responseStatus int) error
+	MarkDeliveryFailed(deliveryID uuid.UUID, reason string, responseStatus *int, retryAt *time.Time) error
+	GetDeliveries(webhookID uuid.UUID, filter models.DeliveryFilter) ([]models.WebhookDelivery, error)
+	RetryDelivery(webhookID, deliveryID uuid.UUID) (models.WebhookDelivery, error)
+}
+
+type IdempotencyRepository interface {
+	ReserveKey(key models.IdempotencyKey, requestHash string, ttl time.Duration) (models.IdempotencyRecord, bool, error)
+	SaveResponse(key models.IdempotencyKey, response models.StoredResponse) error
+	DeleteKey(key models.IdempotencyKey) error
+	DeleteExpiredKeys() (int64, error)
+}
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (uint, bool, error)
+	PoolStats() sql.DBStats
 }
 
 type Repository struct {
 	UserRepository
 	PvzRepository
 	ReceptionRepository
+	TokenRepository
+	CityRepository
+	AssignmentRepository
+	AuditRepository
+	OutboxRepository
+	WebhookRepository
+	IdempotencyRepository
+	HealthRepository
 }
 
 func NewRepository(db *sqlx.DB) *Repository {
 	return &Repository{
-		UserRepository:      NewUserPostgres(db),
-		PvzRepository:       NewPvzPostgres(db),
-		ReceptionRepository: NewReceptionPostgres(db),
+		UserRepository:        NewUserPostgres(db),
+		PvzRepository:         NewPvzPostgres(db),
+		ReceptionRepository:   NewReceptionPostgres(db),
+		TokenRepository:       NewTokenPostgres(db),
+		CityRepository:        NewCityPostgres(db),
+		AssignmentRepository:  NewAssignmentPostgres(db),
+		AuditRepository:       NewAuditPostgres(db),
+		OutboxRepository:      NewOutboxPostgres(db),
+		WebhookRepository:     NewWebhookPostgres(db),
+		IdempotencyRepository: NewIdempotencyPostgres(db),
+		HealthRepository:      NewHealthPostgres(db),
 	}
 }
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrRefreshTokenNotFound = apperr.New(apperr.ErrUnauthorized, "refresh token not found")
+	ErrRefreshTokenExpired  = apperr.New(apperr.ErrUnauthorized, "refresh token expired")
+	ErrRefreshTokenReused   = apperr.New(apperr.ErrUnauthorized, "refresh token reused")
+)
+
+type TokenPostgres struct {
+	db *sqlx.DB
+}
+
+func NewTokenPostgres(db *sqlx.DB) *TokenPostgres {
+	return &TokenPostgres{db: db}
+}
+
+func (r *TokenPostgres) CreateRefreshToken(token models.RefreshToken) error {
+	_, err := r.db.Exec(`
+		INSERT INTO refresh_tokens (user_id, role, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, token.UserID, token.Role, token.FamilyID, token.TokenHash, token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	return nil
+}
+
+// RotateRefreshToken marks the presented token as used and stores next in the
+// same family. Presenting a token that was already used or revoked revokes
+// the whole family and returns ErrRefreshTokenReused.
+func (r *TokenPostgres) RotateRefreshToken(tokenHash string, next models.RefreshToken) (models.RefreshToken, error) {
+	tx := r.db.MustBegin()
+
+	var current models.RefreshToken
+	err := tx.Get(&current, `
+		SELECT id, user_id, role, family_id, token_hash, expires_at, created_at, used_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, tokenHash)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return models.RefreshToken{}, ErrRefreshTokenNotFound
+		}
+		return models.RefreshToken{}, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if current.UsedAt != nil || current.RevokedAt != nil {
+		if err := revokeFamily(tx, current.FamilyID); err != nil {
+			tx.Rollback()
+			return models.RefreshToken{}, err
+		}
+		if err := tx.Commit(); err != nil {
+			return models.RefreshToken{}, err
+		}
+		return models.RefreshToken{}, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		tx.Rollback()
+		return models.RefreshToken{}, ErrRefreshTokenExpired
+	}
+
+	_, err = tx.Exec(`
+		UPDATE refresh_tokens
+		SET used_at = NOW()
+		WHERE id = $1
+	`, current.ID)
+	if err != nil {
+		tx.Rollback()
+		return models.RefreshToken{}, fmt.Errorf("failed to mark refresh token used: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO refresh_tokens (user_id, role, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, current.UserID, current.Role, current.FamilyID, next.TokenHash, next.ExpiresAt)
+	if err != nil {
+		tx.Rollback()
+		return models.RefreshToken{}, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.RefreshToken{}, err
+	}
+
+	return current, nil
+}
+
+func (r *TokenPostgres) RevokeRefreshTokenFamily(tokenHash string) error {
+	_, err := r.db.Exec(`
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE revoked_at IS NULL AND family_id = (
+			SELECT family_id FROM refresh_tokens WHERE token_hash = $1
+		)
+	`, tokenHash)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+func (r *TokenPostgres) RevokeAccessToken(jti uuid.UUID, expiresAt time.Time) error {
+	_, err := r.db.Exec(`
+		INSERT INTO revoked_tokens (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING
+	`, jti, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token %s: %w", jti.String(), err)
+	}
+	return nil
+}
+
+func (r *TokenPostgres) GetRevokedAccessTokens() ([]models.RevokedToken, error) {
+	var tokens []models.RevokedToken
+	err := r.db.Select(&tokens, `
+		SELECT jti, expires_at
+		FROM revoked_tokens
+		WHERE expires_at > NOW()
+	`)
+	return tokens, err
+}
+
+func revokeFamily(tx *sqlx.Tx, familyID uuid.UUID) error {
+	_, err := tx.Exec(`
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token family %s: %w", familyID.String(), err)
+	}
+	return nil
+}
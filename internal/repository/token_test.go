@@ -0,0 +1,115 @@
+package repository_test
+
+import (
+	"database/sql"
+	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var refreshTokenColumns = []string{"id", "user_id", "role", "family_id", "token_hash", "expires_at", "created_at", "used_at", "revoked_at"}
+
+func TestTokenPostgres_RotateRefreshToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := repository.NewTokenPostgres(sqlxDB)
+
+	next := models.RefreshToken{TokenHash: "next_hash", ExpiresAt: time.Now().Add(time.Hour)}
+
+	t.Run("Token rotated", func(t *testing.T) {
+		id, userID, familyID := uuid.New(), uuid.New(), uuid.New()
+		expiresAt := time.Now().Add(time.Hour)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT (.+) FROM refresh_tokens WHERE token_hash = \$1 FOR UPDATE`).
+			WithArgs("current_hash").
+			WillReturnRows(sqlmock.NewRows(refreshTokenColumns).
+				AddRow(id, userID, models.RoleEmployee, familyID, "current_hash", expiresAt, time.Now(), nil, nil))
+		mock.ExpectExec(`UPDATE refresh_tokens SET used_at = NOW\(\) WHERE id = \$1`).
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO refresh_tokens`).
+			WithArgs(userID, models.RoleEmployee, familyID, next.TokenHash, next.ExpiresAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		current, err := repo.RotateRefreshToken("current_hash", next)
+		assert.NoError(t, err)
+		assert.Equal(t, userID, current.UserID)
+		assert.Equal(t, models.RoleEmployee, current.Role)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Reused token revokes family", func(t *testing.T) {
+		familyID := uuid.New()
+		usedAt := time.Now().Add(-time.Minute)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT (.+) FROM refresh_tokens WHERE token_hash = \$1 FOR UPDATE`).
+			WithArgs("used_hash").
+			WillReturnRows(sqlmock.NewRows(refreshTokenColumns).
+				AddRow(uuid.New(), uuid.New(), models.RoleEmployee, familyID, "used_hash", time.Now().Add(time.Hour), time.Now(), usedAt, nil))
+		mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at = NOW\(\) WHERE family_id = \$1`).
+			WithArgs(familyID).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		_, err := repo.RotateRefreshToken("used_hash", next)
+		assert.ErrorIs(t, err, repository.ErrRefreshTokenReused)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Token not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT (.+) FROM refresh_tokens WHERE token_hash = \$1 FOR UPDATE`).
+			WithArgs("unknown_hash").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err := repo.RotateRefreshToken("unknown_hash", next)
+		assert.ErrorIs(t, err, repository.ErrRefreshTokenNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Token expired", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT (.+) FROM refresh_tokens WHERE token_hash = \$1 FOR UPDATE`).
+			WithArgs("expired_hash").
+			WillReturnRows(sqlmock.NewRows(refreshTokenColumns).
+				AddRow(uuid.New(), uuid.New(), models.RoleEmployee, uuid.New(), "expired_hash", time.Now().Add(-time.Hour), time.Now(), nil, nil))
+		mock.ExpectRollback()
+
+		_, err := repo.RotateRefreshToken("expired_hash", next)
+		assert.ErrorIs(t, err, repository.ErrRefreshTokenExpired)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTokenPostgres_RevokeAccessToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := repository.NewTokenPostgres(sqlxDB)
+
+	jti := uuid.New()
+	expiresAt := time.Now().Add(time.Minute)
+
+	mock.ExpectExec(`INSERT INTO revoked_tokens \(jti, expires_at\) VALUES \(\$1, \$2\) ON CONFLICT \(jti\) DO NOTHING`).
+		WithArgs(jti, expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.RevokeAccessToken(jti, expiresAt)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
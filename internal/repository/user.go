@@ -2,6 +2,7 @@ package repository
 
 import (
 	"fmt"
+	"pvz-test/internal/apperr"
 	"pvz-test/internal/models"
 
 	"database/sql"
@@ -10,6 +11,8 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+var ErrUserExists = apperr.New(apperr.ErrConflict, "user already exists")
+
 type UserPostgres struct {
 	db *sqlx.DB
 }
@@ -48,7 +51,27 @@ func (r *UserPostgres) CreateUser(user models.RegisterRequest) (uuid.UUID, error
 	query := fmt.Sprintf(`INSERT INTO %s (email, password_hash, role) VALUES ($1, $2, $3) RETURNING id;`, userTable)
 	err := r.db.QueryRow(query, user.Email, user.Password, user.Role).Scan(&userID)
 	if err != nil {
+		if isPqError(err, pqUniqueViolation) {
+			return uuid.Nil, ErrUserExists
+		}
 		return uuid.Nil, fmt.Errorf("user create error: %w", err)
 	}
 	return userID, err
 }
+
+func (r *UserPostgres) UpdatePasswordHash(userID uuid.UUID, passwordHash string) error {
+	query := fmt.Sprintf(`UPDATE %s SET password_hash = $1 WHERE id = $2;`, userTable)
+	res, err := r.db.Exec(query, passwordHash, userID)
+	if err != nil {
+		return fmt.Errorf("password hash update error: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("could not determine result of password hash update: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("no user with id: %s found", userID.String())
+	}
+	return nil
+}
@@ -148,3 +148,45 @@ func TestUserPostgres_CreateUser(t *testing.T) {
 		assert.Equal(t, uuid.Nil, createdID)
 	})
 }
+
+func TestUserPostgres_UpdatePasswordHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := repository.NewUserPostgres(sqlxDB)
+
+	t.Run("Successful update", func(t *testing.T) {
+		userID := uuid.New()
+
+		mock.ExpectExec(`UPDATE users SET password_hash = \$1 WHERE id = \$2;`).
+			WithArgs("$argon2id$hash", userID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.UpdatePasswordHash(userID, "$argon2id$hash")
+		assert.NoError(t, err)
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		userID := uuid.New()
+
+		mock.ExpectExec(`UPDATE users SET password_hash = \$1 WHERE id = \$2;`).
+			WithArgs("$argon2id$hash", userID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.UpdatePasswordHash(userID, "$argon2id$hash")
+		assert.EqualError(t, err, "no user with id: "+userID.String()+" found")
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		userID := uuid.New()
+
+		mock.ExpectExec(`UPDATE users SET password_hash = \$1 WHERE id = \$2;`).
+			WithArgs("$argon2id$hash", userID).
+			WillReturnError(errors.New("database error"))
+
+		err := repo.UpdatePasswordHash(userID, "$argon2id$hash")
+		assert.EqualError(t, err, "password hash update error: database error")
+	})
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"pvz-test/internal/models"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type WebhookPostgres struct {
+	db *sqlx.DB
+}
+
+func NewWebhookPostgres(db *sqlx.DB) *WebhookPostgres {
+	return &WebhookPostgres{db: db}
+}
+
+type webhookRow struct {
+	ID         uuid.UUID      `db:"id"`
+	URL        string         `db:"url"`
+	Secret     string         `db:"secret"`
+	EventTypes pq.StringArray `db:"event_types"`
+	PVZID      *uuid.UUID     `db:"pvz_id"`
+	City       *string        `db:"city"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+func (row webhookRow) toModel() models.Webhook {
+	webhook := models.Webhook{
+		ID:         row.ID,
+		URL:        row.URL,
+		Secret:     row.Secret,
+		EventTypes: make([]models.EventType, len(row.EventTypes)),
+		PVZID:      row.PVZID,
+		City:       row.City,
+		CreatedAt:  row.CreatedAt,
+	}
+	for i, eventType := range row.EventTypes {
+		webhook.EventTypes[i] = models.EventType(eventType)
+	}
+	return webhook
+}
+
+func (r *WebhookPostgres) CreateWebhook(webhook models.Webhook) (models.Webhook, error) {
+	eventTypes := make([]string, len(webhook.EventTypes))
+	for i, eventType := range webhook.EventTypes {
+		eventTypes[i] = string(eventType)
+	}
+
+	var row webhookRow
+	err := r.db.Get(&row, `
+		INSERT INTO webhook_subscriptions (url, secret, event_types, pvz_id, city)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, url, secret, event_types, pvz_id, city, created_at
+	`, webhook.URL, webhook.Secret, pq.Array(eventTypes), webhook.PVZID, webhook.City)
+	if err != nil {
+		// The city may be renamed or deleted after the service checked it.
+		if webhook.City != nil && isPqConstraint(err, pqForeignKeyViolation, "fk_webhook_subscriptions_city") {
+			return models.Webhook{}, fmt.Errorf("city %s %w", *webhook.City, ErrCityNotSupported)
+		}
+		return models.Webhook{}, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return row.toModel(), nil
+}
+
+// GetWebhooks lists subscriptions without their secrets.
+func (r *WebhookPostgres) GetWebhooks() ([]models.Webhook, error) {
+	var rows []webhookRow
+	err := r.db.Select(&rows, `
+		SELECT id, url, event_types, pvz_id, city, created_at
+		FROM webhook_subscriptions
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	webhooks := make([]models.Webhook, len(rows))
+	for i, row := range rows {
+		webhooks[i] = row.toModel()
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookPostgres) GetWebhookByID(webhookID uuid.UUID) (models.Webhook, error) {
+	var row webhookRow
+	err := r.db.Get(&row, `
+		SELECT id, url, event_types, pvz_id, city, created_at
+		FROM webhook_subscriptions
+		WHERE id = $1
+	`, webhookID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Webhook{}, nil
+		}
+		return models.Webhook{}, fmt.Errorf("failed to get webhook %s: %w", webhookID.String(), err)
+	}
+	return row.toModel(), nil
+}
+
+// DeleteWebhook removes the subscription together with its delivery log.
+func (r *WebhookPostgres) DeleteWebhook(webhookID uuid.UUID) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM webhook_subscriptions WHERE id = $1`, webhookID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook %s: %w", webhookID.String(), err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// EnqueueDeliveries creates a pending delivery of event for every matching
+// subscription. Repeated calls for the same event add nothing.
+func (r *WebhookPostgres) EnqueueDeliveries(event models.Event) (int64, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode event %s: %w", event.ID.String(), err)
+	}
+
+	res, err := r.db.Exec(`
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		SELECT s.id, $1, $2, $3
+		FROM webhook_subscriptions s
+		WHERE $2 = ANY(s.event_types)
+			AND (s.pvz_id IS NULL OR s.pvz_id = $4)
+			AND (s.city IS NULL OR s.city = (SELECT city FROM pvz WHERE id = $4))
+		ON CONFLICT (webhook_id, event_id) DO NOTHING
+	`, event.ID, event.Type, string(payload), event.PVZID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue webhook deliveries for event %s: %w", event.ID.String(), err)
+	}
+	return res.RowsAffected()
+}
+
+// ClaimDeliveries takes up to limit pending deliveries that are due and
+// hides them from other deliverers for lease, like OutboxPostgres.ClaimEvents.
+func (r *WebhookPostgres) ClaimDeliveries(limit int, lease time.Duration) ([]models.WebhookAttempt, error) {
+	attempts := []models.WebhookAttempt{}
+	err := r.db.Select(&attempts, `
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET attempts = attempts + 1,
+				next_attempt_at = NOW() + make_interval(secs => $2)
+			WHERE id IN (
+				SELECT id FROM webhook_deliveries
+				WHERE status = 'pending' AND next_attempt_at <= NOW()
+				ORDER BY created_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at
+		)
+		SELECT c.id, c.webhook_id, c.event_id, c.event_type, c.payload, c.status, c.attempts,
+			c.next_attempt_at, c.created_at, s.url, s.secret
+		FROM claimed c
+		JOIN webhook_subscriptions s ON s.id = c.webhook_id
+		ORDER BY c.created_at
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	return attempts, nil
+}
+
+func (r *WebhookPostgres) MarkDeliveryDelivered(deliveryID uuid.UUID, responseStatus int) error {
+	_, err := r.db.Exec(`
+		UPDATE webhook_deliveries
+		SET status = 'delivered', delivered_at = NOW(), response_status = $2, last_error = NULL
+		WHERE id = $1
+	`, deliveryID, responseStatus)
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivery %s delivered: %w", deliveryID.String(), err)
+	}
+	return nil
+}
+
+// MarkDeliveryFailed reschedules the delivery for retryAt, or moves it to
+// the dead status when retryAt is nil.
+func (r *WebhookPostgres) MarkDeliveryFailed(deliveryID uuid.UUID, reason string, responseStatus *int, retryAt *time.Time) error {
+	status := models.DeliveryStatusPending
+	if retryAt == nil {
+		status = models.DeliveryStatusDead
+	}
+
+	_, err := r.db.Exec(`
+		UPDATE webhook_deliveries
+		SET status = $2, last_error = $3, response_status = $4, next_attempt_at = COALESCE($5, next_attempt_at)
+		WHERE id = $1
+	`, deliveryID, status, reason, responseStatus, retryAt)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule webhook delivery %s: %w", deliveryID.String(), err)
+	}
+	return nil
+}
+
+func (r *WebhookPostgres) GetDeliveries(webhookID uuid.UUID, filter models.DeliveryFilter) ([]models.WebhookDelivery, error) {
+	query := sq.
+		Select("id", "webhook_id", "event_id", "event_type", "status", "attempts", "response_status",
+			"last_error", "next_attempt_at", "created_at", "delivered_at").
+		From("webhook_deliveries").
+		Where(sq.Eq{"webhook_id": webhookID}).
+		OrderBy("created_at DESC")
+
+	if filter.Status != "" {
+		query = query.Where(sq.Eq{"status": filter.Status})
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(uint64(filter.Limit)).Offset(uint64(filter.Offset))
+	}
+
+	sqlQuery, args, err := query.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	if err := r.db.Select(&deliveries, sqlQuery, args...); err != nil {
+		return nil, fmt.Errorf("failed to get deliveries of webhook %s: %w", webhookID.String(), err)
+	}
+	return deliveries, nil
+}
+
+// RetryDelivery puts a dead delivery back in the queue with a fresh attempt
+// budget. It returns the zero value if there is no such dead delivery.
+func (r *WebhookPostgres) RetryDelivery(webhookID, deliveryID uuid.UUID) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.db.Get(&delivery, `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW()
+		WHERE id = $1 AND webhook_id = $2 AND status = 'dead'
+		RETURNING id, webhook_id, event_id, event_type, status, attempts, response_status,
+			last_error, next_attempt_at, created_at, delivered_at
+	`, deliveryID, webhookID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.WebhookDelivery{}, nil
+		}
+		return models.WebhookDelivery{}, fmt.Errorf("failed to retry webhook delivery %s: %w", deliveryID.String(), err)
+	}
+	return delivery, nil
+}
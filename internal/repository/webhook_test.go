@@ -0,0 +1,141 @@
+package repository_test
+
+import (
+	"encoding/json"
+	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookPostgres_CreateWebhook(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	city := "Москва"
+	expected := models.Webhook{
+		ID:         uuid.New(),
+		URL:        "https://partner.example/hooks",
+		Secret:     "secret",
+		EventTypes: []models.EventType{models.EventReceptionClosed},
+		City:       &city,
+		CreatedAt:  time.Now(),
+	}
+
+	mock.ExpectQuery(`INSERT INTO webhook_subscriptions \(url, secret, event_types, pvz_id, city\) VALUES \(\$1, \$2, \$3, \$4, \$5\)`).
+		WithArgs(expected.URL, expected.Secret, pq.Array([]string{"ReceptionClosed"}), nil, &city).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "secret", "event_types", "pvz_id", "city", "created_at"}).
+			AddRow(expected.ID, expected.URL, expected.Secret, "{ReceptionClosed}", nil, city, expected.CreatedAt))
+
+	webhook, err := repo.CreateWebhook(models.Webhook{
+		URL:        expected.URL,
+		Secret:     expected.Secret,
+		EventTypes: expected.EventTypes,
+		City:       &city,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, webhook)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	t.Run("City removed from the catalog", func(t *testing.T) {
+		mock.ExpectQuery(`INSERT INTO webhook_subscriptions`).
+			WillReturnError(&pq.Error{Code: "23503", Constraint: "fk_webhook_subscriptions_city"})
+
+		_, err := repo.CreateWebhook(models.Webhook{
+			URL:        expected.URL,
+			Secret:     expected.Secret,
+			EventTypes: expected.EventTypes,
+			City:       &city,
+		})
+		assert.ErrorIs(t, err, repository.ErrCityNotSupported)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestWebhookPostgres_EnqueueDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	event := models.Event{
+		ID:          uuid.New(),
+		Type:        models.EventReceptionClosed,
+		AggregateID: uuid.New(),
+		PVZID:       uuid.New(),
+		Payload:     json.RawMessage(`{"status":"closed"}`),
+		OccurredAt:  time.Now(),
+	}
+	payload, _ := json.Marshal(event)
+
+	mock.ExpectExec(`INSERT INTO webhook_deliveries \(webhook_id, event_id, event_type, payload\) SELECT s.id, \$1, \$2, \$3 FROM webhook_subscriptions s `+
+		`WHERE \$2 = ANY\(s.event_types\) AND \(s.pvz_id IS NULL OR s.pvz_id = \$4\) AND \(s.city IS NULL OR s.city = \(SELECT city FROM pvz WHERE id = \$4\)\) `+
+		`ON CONFLICT \(webhook_id, event_id\) DO NOTHING`).
+		WithArgs(event.ID, event.Type, string(payload), event.PVZID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	enqueued, err := repo.EnqueueDeliveries(event)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), enqueued)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookPostgres_MarkDeliveryFailed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	t.Run("Rescheduled", func(t *testing.T) {
+		deliveryID := uuid.New()
+		status := 500
+		retryAt := time.Now().Add(time.Minute)
+
+		mock.ExpectExec(`UPDATE webhook_deliveries SET status = \$2, last_error = \$3, response_status = \$4, next_attempt_at = COALESCE\(\$5, next_attempt_at\) WHERE id = \$1`).
+			WithArgs(deliveryID, models.DeliveryStatusPending, "webhook responded with status 500", &status, &retryAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.MarkDeliveryFailed(deliveryID, "webhook responded with status 500", &status, &retryAt))
+	})
+
+	t.Run("Dead", func(t *testing.T) {
+		deliveryID := uuid.New()
+
+		mock.ExpectExec(`UPDATE webhook_deliveries SET status = \$2`).
+			WithArgs(deliveryID, models.DeliveryStatusDead, "connection refused", nil, nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.MarkDeliveryFailed(deliveryID, "connection refused", nil, nil))
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookPostgres_RetryDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewWebhookPostgres(sqlx.NewDb(db, "sqlmock"))
+
+	webhookID, deliveryID := uuid.New(), uuid.New()
+	mock.ExpectQuery(`UPDATE webhook_deliveries SET status = 'pending', attempts = 0, next_attempt_at = NOW\(\) WHERE id = \$1 AND webhook_id = \$2 AND status = 'dead'`).
+		WithArgs(deliveryID, webhookID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	delivery, err := repo.RetryDelivery(webhookID, deliveryID)
+	assert.NoError(t, err)
+	assert.Equal(t, uuid.Nil, delivery.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEmployeeNotFound   = apperr.New(apperr.ErrNotFound, "employee not found")
+	ErrNotEmployee        = apperr.New(apperr.ErrValidation, "user is not an employee")
+	ErrAssignmentNotFound = apperr.New(apperr.ErrNotFound, "employee is not assigned to pvz")
+	ErrPvzNotAssigned     = apperr.New(apperr.ErrForbidden, "pvz is not assigned to the caller")
+	ErrDummyUserAssigned  = apperr.New(apperr.ErrValidation, "dummy user cannot be assigned")
+)
+
+type AssignmentService struct {
+	assignmentRepo repository.AssignmentRepository
+	pvzRepo        repository.PvzRepository
+	userRepo       repository.UserRepository
+}
+
+func NewAssignmentService(assignmentRepo repository.AssignmentRepository, pvzRepo repository.PvzRepository, userRepo repository.UserRepository) *AssignmentService {
+	return &AssignmentService{
+		assignmentRepo: assignmentRepo,
+		pvzRepo:        pvzRepo,
+		userRepo:       userRepo,
+	}
+}
+
+func (s *AssignmentService) AssignEmployee(pvzID, userID uuid.UUID) (models.EmployeeAssignment, error) {
+	pvz, err := s.pvzRepo.GetPVZByID(pvzID)
+	if err != nil {
+		return models.EmployeeAssignment{}, err
+	}
+	if (pvz == models.PVZ{}) {
+		return models.EmployeeAssignment{}, fmt.Errorf("%w: %s", ErrPvzNotFound, pvzID.String())
+	}
+	if !pvz.Active() {
+		return models.EmployeeAssignment{}, fmt.Errorf("%w: %s", ErrPvzDeactivated, pvzID.String())
+	}
+
+	if err := s.checkEmployee(userID); err != nil {
+		return models.EmployeeAssignment{}, err
+	}
+
+	return s.assignmentRepo.AssignEmployee(userID, pvzID)
+}
+
+func (s *AssignmentService) UnassignEmployee(pvzID, userID uuid.UUID) error {
+	removed, err := s.assignmentRepo.UnassignEmployee(userID, pvzID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("%w: user %s, pvz %s", ErrAssignmentNotFound, userID.String(), pvzID.String())
+	}
+	return nil
+}
+
+func (s *AssignmentService) GetPVZEmployees(pvzID uuid.UUID) ([]models.EmployeeAssignment, error) {
+	pvz, err := s.pvzRepo.GetPVZByID(pvzID)
+	if err != nil {
+		return nil, err
+	}
+	if (pvz == models.PVZ{}) {
+		return nil, fmt.Errorf("%w: %s", ErrPvzNotFound, pvzID.String())
+	}
+
+	return s.assignmentRepo.GetPVZEmployees(pvzID)
+}
+
+// checkEmployee accepts registered employees only. The dummy user is shared by
+// every dummyLogin token, so assigning it would open the PVZ to all of them.
+func (s *AssignmentService) checkEmployee(userID uuid.UUID) error {
+	if userID == DummyUserID {
+		return fmt.Errorf("%w: %s", ErrDummyUserAssigned, userID.String())
+	}
+
+	user, err := s.userRepo.GetUserById(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %s", ErrEmployeeNotFound, userID.String())
+	}
+	if err != nil {
+		return err
+	}
+	if user.Role != models.RoleEmployee {
+		return fmt.Errorf("%w: %s", ErrNotEmployee, userID.String())
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package service_test
+
+import (
+	"database/sql"
+	"fmt"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/models"
+	"pvz-test/internal/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAssignmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAssignmentRepository) AssignEmployee(userID, pvzID uuid.UUID) (models.EmployeeAssignment, error) {
+	args := m.Called(userID, pvzID)
+	return args.Get(0).(models.EmployeeAssignment), args.Error(1)
+}
+
+func (m *MockAssignmentRepository) UnassignEmployee(userID, pvzID uuid.UUID) (bool, error) {
+	args := m.Called(userID, pvzID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAssignmentRepository) IsAssigned(userID, pvzID uuid.UUID) (bool, error) {
+	args := m.Called(userID, pvzID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAssignmentRepository) GetPVZEmployees(pvzID uuid.UUID) ([]models.EmployeeAssignment, error) {
+	args := m.Called(pvzID)
+	return args.Get(0).([]models.EmployeeAssignment), args.Error(1)
+}
+
+func TestAssignmentService_AssignEmployee(t *testing.T) {
+	mockAssignmentRepo := new(MockAssignmentRepository)
+	mockPvzRepo := new(MockPvzRepository)
+	mockUserRepo := new(MockUserRepository)
+	svc := service.NewAssignmentService(mockAssignmentRepo, mockPvzRepo, mockUserRepo)
+
+	t.Run("Employee assigned", func(t *testing.T) {
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва"}
+		user := models.User{ID: uuid.New(), Role: models.RoleEmployee}
+		expected := models.EmployeeAssignment{UserID: user.ID, PVZID: pvz.ID, AssignedAt: time.Now()}
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+		mockUserRepo.On("GetUserById", user.ID).Return(user, nil).Once()
+		mockAssignmentRepo.On("AssignEmployee", user.ID, pvz.ID).Return(expected, nil).Once()
+
+		assignment, err := svc.AssignEmployee(pvz.ID, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, assignment)
+	})
+
+	t.Run("Dummy user cannot be assigned", func(t *testing.T) {
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва"}
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+
+		_, err := svc.AssignEmployee(pvz.ID, service.DummyUserID)
+		assert.ErrorIs(t, err, service.ErrDummyUserAssigned)
+		mockUserRepo.AssertNotCalled(t, "GetUserById", service.DummyUserID)
+		mockAssignmentRepo.AssertNotCalled(t, "AssignEmployee", service.DummyUserID, pvz.ID)
+	})
+
+	t.Run("Moderator cannot be assigned", func(t *testing.T) {
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва"}
+		user := models.User{ID: uuid.New(), Role: models.RoleModerator}
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+		mockUserRepo.On("GetUserById", user.ID).Return(user, nil).Once()
+
+		_, err := svc.AssignEmployee(pvz.ID, user.ID)
+		assert.ErrorIs(t, err, service.ErrNotEmployee)
+		assert.ErrorIs(t, err, apperr.ErrValidation)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва"}
+		userID := uuid.New()
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+		mockUserRepo.On("GetUserById", userID).Return(models.User{}, fmt.Errorf("no user: %w", sql.ErrNoRows)).Once()
+
+		_, err := svc.AssignEmployee(pvz.ID, userID)
+		assert.ErrorIs(t, err, service.ErrEmployeeNotFound)
+	})
+
+	t.Run("Deactivated PVZ", func(t *testing.T) {
+		deactivatedAt := time.Now()
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва", DeactivatedAt: &deactivatedAt}
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+
+		_, err := svc.AssignEmployee(pvz.ID, uuid.New())
+		assert.ErrorIs(t, err, service.ErrPvzDeactivated)
+	})
+
+	t.Run("Missing PVZ", func(t *testing.T) {
+		pvzID := uuid.New()
+		mockPvzRepo.On("GetPVZByID", pvzID).Return(models.PVZ{}, nil).Once()
+
+		_, err := svc.AssignEmployee(pvzID, uuid.New())
+		assert.ErrorIs(t, err, service.ErrPvzNotFound)
+	})
+}
+
+func TestAssignmentService_UnassignEmployee(t *testing.T) {
+	mockAssignmentRepo := new(MockAssignmentRepository)
+	svc := service.NewAssignmentService(mockAssignmentRepo, nil, nil)
+
+	t.Run("Unassigned", func(t *testing.T) {
+		pvzID, userID := uuid.New(), uuid.New()
+		mockAssignmentRepo.On("UnassignEmployee", userID, pvzID).Return(true, nil).Once()
+
+		assert.NoError(t, svc.UnassignEmployee(pvzID, userID))
+	})
+
+	t.Run("Not assigned", func(t *testing.T) {
+		pvzID, userID := uuid.New(), uuid.New()
+		mockAssignmentRepo.On("UnassignEmployee", userID, pvzID).Return(false, nil).Once()
+
+		err := svc.UnassignEmployee(pvzID, userID)
+		assert.ErrorIs(t, err, service.ErrAssignmentNotFound)
+		assert.ErrorIs(t, err, apperr.ErrNotFound)
+	})
+}
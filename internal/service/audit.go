@@ -0,0 +1,35 @@
+package service
+
+import (
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
+)
+
+type AuditService struct {
+	auditRepo repository.AuditRepository
+}
+
+func NewAuditService(auditRepo repository.AuditRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+var auditActions = map[models.AuditAction]struct{}{
+	models.AuditPVZCreated:       {},
+	models.AuditPVZUpdated:       {},
+	models.AuditPVZDeactivated:   {},
+	models.AuditReceptionCreated: {},
+	models.AuditReceptionClosed:  {},
+	models.AuditProductAdded:     {},
+	models.AuditProductDeleted:   {},
+}
+
+func (s *AuditService) GetAuditEvents(filter models.AuditFilter) ([]models.AuditEvent, error) {
+	if _, ok := auditActions[filter.Action]; filter.Action != "" && !ok {
+		return nil, &apperr.ValidationError{Field: "action", Value: string(filter.Action), Reason: "unknown audit action"}
+	}
+	if filter.Start != nil && filter.End != nil && filter.End.Before(*filter.Start) {
+		return nil, apperr.Validation("endDate is before startDate")
+	}
+	return s.auditRepo.GetAuditEvents(filter)
+}
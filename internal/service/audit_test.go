@@ -0,0 +1,52 @@
+package service_test
+
+import (
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/models"
+	"pvz-test/internal/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) GetAuditEvents(filter models.AuditFilter) ([]models.AuditEvent, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]models.AuditEvent), args.Error(1)
+}
+
+func TestAuditService_GetAuditEvents(t *testing.T) {
+	mockAuditRepo := new(MockAuditRepository)
+	svc := service.NewAuditService(mockAuditRepo)
+
+	t.Run("Filtered events", func(t *testing.T) {
+		pvzID := uuid.New()
+		filter := models.AuditFilter{PVZID: &pvzID, Action: models.AuditProductDeleted, Limit: 10}
+		events := []models.AuditEvent{{ID: uuid.New(), PVZID: pvzID, Action: models.AuditProductDeleted}}
+		mockAuditRepo.On("GetAuditEvents", filter).Return(events, nil).Once()
+
+		result, err := svc.GetAuditEvents(filter)
+		assert.NoError(t, err)
+		assert.Equal(t, events, result)
+	})
+
+	t.Run("Unknown action", func(t *testing.T) {
+		_, err := svc.GetAuditEvents(models.AuditFilter{Action: "pvz.deleted"})
+		assert.ErrorIs(t, err, apperr.ErrValidation)
+	})
+
+	t.Run("Reversed dates", func(t *testing.T) {
+		start := time.Now()
+		end := start.Add(-time.Hour)
+
+		_, err := svc.GetAuditEvents(models.AuditFilter{Start: &start, End: &end})
+		assert.ErrorIs(t, err, apperr.ErrValidation)
+		mockAuditRepo.AssertNumberOfCalls(t, "GetAuditEvents", 1)
+	})
+}
@@ -1,24 +1,38 @@
 package service
 
 import (
-	"crypto/sha1"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"pvz-test/internal/apperr"
 	"pvz-test/internal/models"
 	"pvz-test/internal/repository"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 const NEW_USER_BALANCE = 1000
-const (
-	salt       = "someSalt"
-	signingKey = "podpis"
-	tokenTTL   = time.Hour * 100
-)
+const defaultSalt = "someSalt"
+
+var ErrTokenRevoked = apperr.New(apperr.ErrUnauthorized, "token revoked")
+
+// DummyUserID is the subject of tokens issued by DummyLogin.
+var DummyUserID = uuid.Max
+
+type AuthConfig struct {
+	Keyring        *Keyring
+	TokenTTL       time.Duration
+	RefreshTTL     time.Duration
+	PasswordHasher PasswordHasher
+	// PasswordSalt is only used to verify legacy SHA-1 hashes.
+	PasswordSalt string
+}
 
 type TokenClaims struct {
 	jwt.StandardClaims
@@ -27,15 +41,40 @@ type TokenClaims struct {
 }
 
 type AuthorizationService struct {
-	userRepo repository.UserRepository
+	userRepo    repository.UserRepository
+	tokenRepo   repository.TokenRepository
+	revocations *RevocationList
+	cfg         AuthConfig
+	hashers     []PasswordHasher
 }
 
-func NewAuthService(userRepo repository.UserRepository) *AuthorizationService {
-	return &AuthorizationService{userRepo: userRepo}
+func NewAuthService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository, revocations *RevocationList, cfg AuthConfig) *AuthorizationService {
+	if cfg.PasswordSalt == "" {
+		cfg.PasswordSalt = defaultSalt
+	}
+	if cfg.PasswordHasher == nil {
+		cfg.PasswordHasher = NewArgon2idHasher()
+	}
+	return &AuthorizationService{
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		revocations: revocations,
+		cfg:         cfg,
+		hashers: []PasswordHasher{
+			cfg.PasswordHasher,
+			NewArgon2idHasher(),
+			NewBcryptHasher(),
+			&LegacySHA1Hasher{Salt: cfg.PasswordSalt},
+		},
+	}
 }
 
 func (s *AuthorizationService) Register(user models.RegisterRequest) (models.UserResponse, error) {
-	user.Password = GeneratePasswordHash(user.Password)
+	hash, err := s.cfg.PasswordHasher.Hash(user.Password)
+	if err != nil {
+		return models.UserResponse{}, fmt.Errorf("password hash error: %w", err)
+	}
+	user.Password = hash
 	UserID, err := s.userRepo.CreateUser(user)
 	if err != nil {
 		logrus.Info(err)
@@ -45,66 +84,186 @@ func (s *AuthorizationService) Register(user models.RegisterRequest) (models.Use
 	return response, err
 }
 
-func (s *AuthorizationService) Login(userReq models.LoginRequest) (string, error) {
+func (s *AuthorizationService) Login(userReq models.LoginRequest) (models.TokenPair, error) {
 	user, err := s.userRepo.GetUserByEmail(userReq.Email)
 	if err != nil {
 		logrus.Info(err)
-		return "", err
+		return models.TokenPair{}, err
 	}
 	if user == (models.User{}) {
-		return "", fmt.Errorf("Unauthorized")
-	} else if user.PasswordHash != GeneratePasswordHash(userReq.Password) {
-		return "", fmt.Errorf("Unauthorized")
+		return models.TokenPair{}, fmt.Errorf("Unauthorized")
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &TokenClaims{
-		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(tokenTTL).Unix(),
-			IssuedAt:  time.Now().Unix(),
-		},
-		user.ID,
-		user.Role,
-	})
+	valid, needsRehash, err := s.verifyPassword(userReq.Password, user.PasswordHash)
+	if err != nil {
+		logrus.Errorf("password verify error for user %s: %s", user.ID, err.Error())
+		return models.TokenPair{}, fmt.Errorf("Unauthorized")
+	}
+	if !valid {
+		return models.TokenPair{}, fmt.Errorf("Unauthorized")
+	}
+	if needsRehash {
+		s.rehashPassword(user.ID, userReq.Password)
+	}
 
-	return token.SignedString([]byte(signingKey))
+	return s.issueTokenPair(user.ID, user.Role)
 }
 
-func (s *AuthorizationService) DummyLogin(role models.Role) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &TokenClaims{
-		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(tokenTTL).Unix(),
-			IssuedAt:  time.Now().Unix(),
-		},
-		uuid.Max,
-		role,
+func (s *AuthorizationService) DummyLogin(role models.Role) (models.TokenPair, error) {
+	return s.issueTokenPair(DummyUserID, role)
+}
+
+func (s *AuthorizationService) Refresh(refreshToken string) (models.TokenPair, error) {
+	next, err := newRefreshToken()
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	expiresAt := time.Now().Add(s.cfg.RefreshTTL)
+
+	current, err := s.tokenRepo.RotateRefreshToken(hashRefreshToken(refreshToken), models.RefreshToken{
+		TokenHash: hashRefreshToken(next),
+		ExpiresAt: expiresAt,
 	})
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenReused) {
+			logrus.Warnf("refresh token reuse detected, token family revoked")
+		}
+		return models.TokenPair{}, err
+	}
 
-	return token.SignedString([]byte(signingKey))
+	accessToken, err := s.signAccessToken(current.UserID, current.Role)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	return models.TokenPair{AccessToken: accessToken, RefreshToken: next, RefreshExpiresAt: expiresAt}, nil
 }
 
-func (s *AuthorizationService) ParseToken(accessToken string) (uuid.UUID, models.Role, error) {
-	token, err := jwt.ParseWithClaims(accessToken, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
+func (s *AuthorizationService) Logout(accessToken, refreshToken string) error {
+	claims, err := s.parseClaims(accessToken)
+	if err != nil {
+		return err
+	}
+
+	if claims.Id != "" {
+		jti, err := uuid.Parse(claims.Id)
+		if err != nil {
+			return fmt.Errorf("invalid token id: %w", err)
 		}
+		if err := s.revocations.Revoke(jti, time.Unix(claims.ExpiresAt, 0)); err != nil {
+			return err
+		}
+	}
 
-		return []byte(signingKey), nil
-	})
+	if refreshToken != "" {
+		return s.tokenRepo.RevokeRefreshTokenFamily(hashRefreshToken(refreshToken))
+	}
+	return nil
+}
+
+func (s *AuthorizationService) ParseToken(accessToken string) (uuid.UUID, models.Role, error) {
+	claims, err := s.parseClaims(accessToken)
 	if err != nil {
 		return uuid.Nil, "", err
 	}
 
+	return claims.UserId, claims.Role, nil
+}
+
+func (s *AuthorizationService) parseClaims(accessToken string) (*TokenClaims, error) {
+	token, err := jwt.ParseWithClaims(accessToken, &TokenClaims{}, s.cfg.Keyring.Keyfunc)
+	if err != nil {
+		return nil, err
+	}
+
 	claims, ok := token.Claims.(*TokenClaims)
 	if !ok {
-		return uuid.Nil, "", errors.New("invalid token struct")
+		return nil, errors.New("invalid token struct")
 	}
 
-	return claims.UserId, claims.Role, nil
+	if claims.Id != "" {
+		if jti, err := uuid.Parse(claims.Id); err == nil && s.revocations.IsRevoked(jti) {
+			return nil, ErrTokenRevoked
+		}
+	}
+
+	return claims, nil
 }
 
-func GeneratePasswordHash(password string) string {
-	hash := sha1.New()
-	hash.Write([]byte(password))
+func (s *AuthorizationService) issueTokenPair(userID uuid.UUID, role models.Role) (models.TokenPair, error) {
+	accessToken, err := s.signAccessToken(userID, role)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	expiresAt := time.Now().Add(s.cfg.RefreshTTL)
 
-	return fmt.Sprintf("%x", hash.Sum([]byte(salt)))
+	err = s.tokenRepo.CreateRefreshToken(models.RefreshToken{
+		UserID:    userID,
+		Role:      role,
+		FamilyID:  uuid.New(),
+		TokenHash: hashRefreshToken(refreshToken),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	return models.TokenPair{AccessToken: accessToken, RefreshToken: refreshToken, RefreshExpiresAt: expiresAt}, nil
+}
+
+func (s *AuthorizationService) signAccessToken(userID uuid.UUID, role models.Role) (string, error) {
+	return s.cfg.Keyring.Sign(&TokenClaims{
+		jwt.StandardClaims{
+			Id:        uuid.NewString(),
+			ExpiresAt: time.Now().Add(s.cfg.TokenTTL).Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+		userID,
+		role,
+	})
+}
+
+func newRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate refresh token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *AuthorizationService) verifyPassword(password, encoded string) (bool, bool, error) {
+	for _, hasher := range s.hashers {
+		if !hasher.Supports(encoded) {
+			continue
+		}
+		valid, err := hasher.Verify(password, encoded)
+		if err != nil || !valid {
+			return false, false, err
+		}
+		return true, hasher != s.cfg.PasswordHasher || hasher.NeedsRehash(encoded), nil
+	}
+	return false, false, ErrInvalidPasswordHash
+}
+
+func (s *AuthorizationService) rehashPassword(userID uuid.UUID, password string) {
+	hash, err := s.cfg.PasswordHasher.Hash(password)
+	if err != nil {
+		logrus.Errorf("password rehash error for user %s: %s", userID, err.Error())
+		return
+	}
+	if err := s.userRepo.UpdatePasswordHash(userID, hash); err != nil {
+		logrus.Errorf("password hash update error for user %s: %s", userID, err.Error())
+		return
+	}
+	logrus.Infof("password hash upgraded for user %s", userID)
 }
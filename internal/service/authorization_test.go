@@ -7,7 +7,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -21,8 +21,21 @@ const (
 	salt       = "someSalt"
 	signingKey = "podpis"
 	tokenTTL   = time.Hour / 2
+	refreshTTL = time.Hour * 24
 )
 
+func testAuthConfig() service.AuthConfig {
+	keyring, err := service.NewKeyring("test", service.NewHMACKey("test", []byte(signingKey)))
+	if err != nil {
+		panic(err)
+	}
+	return service.AuthConfig{Keyring: keyring, TokenTTL: tokenTTL, RefreshTTL: refreshTTL, PasswordSalt: salt}
+}
+
+func newTestAuthService(userRepo *MockUserRepository, tokenRepo *MockTokenRepository) *service.AuthorizationService {
+	return service.NewAuthService(userRepo, tokenRepo, service.NewRevocationList(tokenRepo), testAuthConfig())
+}
+
 func (m *MockUserRepository) CreateUser(user models.RegisterRequest) (uuid.UUID, error) {
 	args := m.Called(user)
 	return args.Get(0).(uuid.UUID), args.Error(1)
@@ -38,19 +51,38 @@ func (m *MockUserRepository) GetUserById(userID uuid.UUID) (models.User, error)
 	return args.Get(0).(models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) UpdatePasswordHash(userID uuid.UUID, passwordHash string) error {
+	args := m.Called(userID, passwordHash)
+	return args.Error(0)
+}
+
+func argon2idHashOf(password string) interface{} {
+	return mock.MatchedBy(func(hash string) bool {
+		valid, err := service.NewArgon2idHasher().Verify(password, hash)
+		return err == nil && valid
+	})
+}
+
+func registerRequestWithHashOf(request models.RegisterRequest) interface{} {
+	return mock.MatchedBy(func(actual models.RegisterRequest) bool {
+		valid, err := service.NewArgon2idHasher().Verify(request.Password, actual.Password)
+		return err == nil && valid && actual.Email == request.Email && actual.Role == request.Role
+	})
+}
+
 func TestAuthorizationService_Login_Bad(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	authService := service.NewAuthService(mockRepo)
+	authService := newTestAuthService(mockRepo, newTokenRepository())
 
 	t.Run("User not found", func(t *testing.T) {
 		mockRepo.On("GetUserByEmail", "test@example.com").Return(models.User{}, errors.New("Unauthorized"))
 
-		token, err := authService.Login(models.LoginRequest{
+		tokens, err := authService.Login(models.LoginRequest{
 			Email:    "test@example.com",
 			Password: "password123",
 		})
 
-		assert.Empty(t, token)
+		assert.Empty(t, tokens.AccessToken)
 		assert.EqualError(t, err, "Unauthorized")
 		mockRepo.AssertExpectations(t)
 	})
@@ -62,12 +94,12 @@ func TestAuthorizationService_Login_Bad(t *testing.T) {
 			PasswordHash: service.GeneratePasswordHash("password"),
 		}, errors.New("user not found"))
 
-		token, err := authService.Login(models.LoginRequest{
+		tokens, err := authService.Login(models.LoginRequest{
 			Email:    "test@example.com",
 			Password: "wrong_password",
 		})
 
-		assert.Empty(t, token)
+		assert.Empty(t, tokens.AccessToken)
 		assert.EqualError(t, err, "Unauthorized")
 		mockRepo.AssertExpectations(t)
 	})
@@ -78,7 +110,7 @@ func TestAuthorizationService_Login_Bad(t *testing.T) {
 
 func TestAuthorizationService_Login_Good(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	authService := service.NewAuthService(mockRepo)
+	authService := newTestAuthService(mockRepo, newTokenRepository())
 	t.Run("Successful login", func(t *testing.T) {
 		userID := uuid.New()
 		mockRepo.On("GetUserByEmail", "test@example.com").Return(models.User{
@@ -87,14 +119,17 @@ func TestAuthorizationService_Login_Good(t *testing.T) {
 			PasswordHash: service.GeneratePasswordHash("password123"),
 			Role:         models.RoleEmployee,
 		}, nil)
+		mockRepo.On("UpdatePasswordHash", userID, argon2idHashOf("password123")).Return(nil)
 
-		token, err := authService.Login(models.LoginRequest{
+		tokens, err := authService.Login(models.LoginRequest{
 			Email:    "test@example.com",
 			Password: "password123",
 		})
+		token := tokens.AccessToken
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, token)
+		assert.NotEmpty(t, tokens.RefreshToken)
 
 		// Validate the token
 		parsedToken, err := jwt.ParseWithClaims(token, &service.TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
@@ -111,20 +146,20 @@ func TestAuthorizationService_Login_Good(t *testing.T) {
 }
 
 func TestAuthorizationService_DummyLogin(t *testing.T) {
-	authService := service.NewAuthService(nil)
+	authService := newTestAuthService(nil, newTokenRepository())
 
 	role := models.RoleEmployee
-	token, err := authService.DummyLogin(role)
+	tokens, err := authService.DummyLogin(role)
 	assert.NoError(t, err)
 
-	userID, parsedRole, err := authService.ParseToken(token)
+	userID, parsedRole, err := authService.ParseToken(tokens.AccessToken)
 	assert.NoError(t, err)
 	assert.Equal(t, uuid.Max, userID)
 	assert.Equal(t, role, parsedRole)
 }
 
 func TestAuthorizationService_ParseToken(t *testing.T) {
-	authService := service.NewAuthService(nil)
+	authService := newTestAuthService(nil, newTokenRepository())
 
 	role := models.RoleModerator
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &service.TokenClaims{
@@ -144,7 +179,7 @@ func TestAuthorizationService_ParseToken(t *testing.T) {
 }
 
 func TestAuthorizationService_ParseToken_InvalidToken(t *testing.T) {
-	authService := service.NewAuthService(nil)
+	authService := newTestAuthService(nil, newTokenRepository())
 
 	_, _, err := authService.ParseToken("invalid_token")
 	assert.Error(t, err)
@@ -160,7 +195,7 @@ func TestGeneratePasswordHash(t *testing.T) {
 
 func TestAuthorizationService_Register_Good(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	authService := service.NewAuthService(mockRepo)
+	authService := newTestAuthService(mockRepo, newTokenRepository())
 
 	t.Run("Successful registration", func(t *testing.T) {
 		request := models.RegisterRequest{
@@ -168,18 +203,12 @@ func TestAuthorizationService_Register_Good(t *testing.T) {
 			Password: "password123",
 			Role:     "employee",
 		}
-		hashedPassword := service.GeneratePasswordHash(request.Password)
-		expectedRequest := models.RegisterRequest{
-			Email:    request.Email,
-			Password: hashedPassword,
-			Role:     request.Role,
-		}
 		expectedUser := models.UserResponse{
 			ID:    uuid.New(),
 			Email: request.Email,
 			Role:  request.Role,
 		}
-		mockRepo.On("CreateUser", expectedRequest).Return(expectedUser.ID, nil)
+		mockRepo.On("CreateUser", registerRequestWithHashOf(request)).Return(expectedUser.ID, nil)
 
 		user, err := authService.Register(request)
 		assert.NoError(t, err)
@@ -190,7 +219,7 @@ func TestAuthorizationService_Register_Good(t *testing.T) {
 
 func TestAuthorizationService_Register_Bad(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	authService := service.NewAuthService(mockRepo)
+	authService := newTestAuthService(mockRepo, newTokenRepository())
 
 	t.Run("Error during user creation", func(t *testing.T) {
 		request := models.RegisterRequest{
@@ -198,16 +227,67 @@ func TestAuthorizationService_Register_Bad(t *testing.T) {
 			Password: "password123",
 			Role:     "employee",
 		}
-		hashedPassword := service.GeneratePasswordHash(request.Password)
-		expectedRequest := models.RegisterRequest{
-			Email:    request.Email,
-			Password: hashedPassword,
-			Role:     request.Role,
-		}
-		mockRepo.On("CreateUser", expectedRequest).Return(uuid.Nil, errors.New("database error"))
+		mockRepo.On("CreateUser", registerRequestWithHashOf(request)).Return(uuid.Nil, errors.New("database error"))
 
 		_, err := authService.Register(request)
 		assert.EqualError(t, err, "database error")
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestAuthorizationService_Login_Rehash(t *testing.T) {
+	t.Run("Argon2id hash is not rewritten", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		authService := newTestAuthService(mockRepo, newTokenRepository())
+		hash, err := service.NewArgon2idHasher().Hash("password123")
+		assert.NoError(t, err)
+
+		mockRepo.On("GetUserByEmail", "test@example.com").Return(models.User{
+			ID:           uuid.New(),
+			Email:        "test@example.com",
+			PasswordHash: hash,
+			Role:         models.RoleEmployee,
+		}, nil)
+
+		tokens, err := authService.Login(models.LoginRequest{Email: "test@example.com", Password: "password123"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		mockRepo.AssertNotCalled(t, "UpdatePasswordHash", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Wrong password against legacy hash is not rewritten", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		authService := newTestAuthService(mockRepo, newTokenRepository())
+
+		mockRepo.On("GetUserByEmail", "test@example.com").Return(models.User{
+			ID:           uuid.New(),
+			Email:        "test@example.com",
+			PasswordHash: service.GeneratePasswordHash("password123"),
+			Role:         models.RoleEmployee,
+		}, nil)
+
+		tokens, err := authService.Login(models.LoginRequest{Email: "test@example.com", Password: "wrong_password"})
+		assert.Empty(t, tokens.AccessToken)
+		assert.EqualError(t, err, "Unauthorized")
+		mockRepo.AssertNotCalled(t, "UpdatePasswordHash", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failed rehash does not block login", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		authService := newTestAuthService(mockRepo, newTokenRepository())
+		userID := uuid.New()
+
+		mockRepo.On("GetUserByEmail", "test@example.com").Return(models.User{
+			ID:           userID,
+			Email:        "test@example.com",
+			PasswordHash: service.GeneratePasswordHash("password123"),
+			Role:         models.RoleEmployee,
+		}, nil)
+		mockRepo.On("UpdatePasswordHash", userID, argon2idHashOf("password123")).Return(errors.New("database error"))
+
+		tokens, err := authService.Login(models.LoginRequest{Email: "test@example.com", Password: "password123"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"fmt"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCityNotFound     = apperr.New(apperr.ErrNotFound, "city not found")
+	ErrCityNotSupported = repository.ErrCityNotSupported
+)
+
+const defaultCityCacheTTL = time.Minute
+
+// CityCatalog caches city names for PVZ validation. It is invalidated on every
+// catalog change made through this instance, and reloaded after ttl to pick
+// up changes made by other instances.
+type CityCatalog struct {
+	cityRepo repository.CityRepository
+	ttl      time.Duration
+
+	mu        sync.RWMutex
+	names     map[string]struct{}
+	expiresAt time.Time
+}
+
+func NewCityCatalog(cityRepo repository.CityRepository, ttl time.Duration) *CityCatalog {
+	if ttl <= 0 {
+		ttl = defaultCityCacheTTL
+	}
+	return &CityCatalog{cityRepo: cityRepo, ttl: ttl}
+}
+
+func (c *CityCatalog) Contains(name string) (bool, error) {
+	c.mu.RLock()
+	if time.Now().Before(c.expiresAt) {
+		_, ok := c.names[name]
+		c.mu.RUnlock()
+		return ok, nil
+	}
+	c.mu.RUnlock()
+
+	if err := c.reload(); err != nil {
+		return false, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.names[name]
+	return ok, nil
+}
+
+func (c *CityCatalog) Invalidate() {
+	c.mu.Lock()
+	c.expiresAt = time.Time{}
+	c.mu.Unlock()
+}
+
+func (c *CityCatalog) reload() error {
+	cities, err := c.cityRepo.GetCities()
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]struct{}, len(cities))
+	for _, city := range cities {
+		names[city.Name] = struct{}{}
+	}
+
+	c.mu.Lock()
+	c.names = names
+	c.expiresAt = time.Now().Add(c.ttl)
+	c.mu.Unlock()
+	return nil
+}
+
+type CityService struct {
+	cityRepo repository.CityRepository
+	catalog  *CityCatalog
+}
+
+func NewCityService(cityRepo repository.CityRepository, catalog *CityCatalog) *CityService {
+	return &CityService{cityRepo: cityRepo, catalog: catalog}
+}
+
+func (s *CityService) CreateCity(name string) (models.City, error) {
+	name, err := cityName(name)
+	if err != nil {
+		return models.City{}, err
+	}
+	city, err := s.cityRepo.CreateCity(name)
+	if err != nil {
+		return models.City{}, err
+	}
+	s.catalog.Invalidate()
+	return city, nil
+}
+
+func (s *CityService) GetCities() ([]models.City, error) {
+	return s.cityRepo.GetCities()
+}
+
+func (s *CityService) GetCityByID(cityID uuid.UUID) (models.City, error) {
+	city, err := s.cityRepo.GetCityByID(cityID)
+	if err != nil {
+		return models.City{}, err
+	}
+	if city == (models.City{}) {
+		return models.City{}, ErrCityNotFound
+	}
+	return city, nil
+}
+
+func (s *CityService) UpdateCity(cityID uuid.UUID, name string) (models.City, error) {
+	name, err := cityName(name)
+	if err != nil {
+		return models.City{}, err
+	}
+	city, err := s.cityRepo.UpdateCity(cityID, name)
+	if err != nil {
+		return models.City{}, err
+	}
+	if city == (models.City{}) {
+		return models.City{}, ErrCityNotFound
+	}
+	s.catalog.Invalidate()
+	return city, nil
+}
+
+func (s *CityService) DeleteCity(cityID uuid.UUID) error {
+	deleted, err := s.cityRepo.DeleteCity(cityID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrCityNotFound
+	}
+	s.catalog.Invalidate()
+	return nil
+}
+
+// cityName trims the name and rejects one that is blank.
+func cityName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", apperr.Validation("city name must not be blank")
+	}
+	return name, nil
+}
+
+func cityNotSupported(city string) error {
+	return fmt.Errorf("city %s %w", city, ErrCityNotSupported)
+}
@@ -0,0 +1,157 @@
+package service_test
+
+import (
+	"errors"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
+	"pvz-test/internal/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCityRepository struct {
+	mock.Mock
+}
+
+func (m *MockCityRepository) CreateCity(name string) (models.City, error) {
+	args := m.Called(name)
+	return args.Get(0).(models.City), args.Error(1)
+}
+
+func (m *MockCityRepository) GetCities() ([]models.City, error) {
+	args := m.Called()
+	return args.Get(0).([]models.City), args.Error(1)
+}
+
+func (m *MockCityRepository) GetCityByID(cityID uuid.UUID) (models.City, error) {
+	args := m.Called(cityID)
+	return args.Get(0).(models.City), args.Error(1)
+}
+
+func (m *MockCityRepository) UpdateCity(cityID uuid.UUID, name string) (models.City, error) {
+	args := m.Called(cityID, name)
+	return args.Get(0).(models.City), args.Error(1)
+}
+
+func (m *MockCityRepository) DeleteCity(cityID uuid.UUID) (bool, error) {
+	args := m.Called(cityID)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestCityCatalog_Contains(t *testing.T) {
+	t.Run("Catalog is cached", func(t *testing.T) {
+		mockCityRepo := new(MockCityRepository)
+		catalog := service.NewCityCatalog(mockCityRepo, time.Minute)
+		mockCityRepo.On("GetCities").Return([]models.City{{Name: "Москва"}}, nil).Once()
+
+		ok, err := catalog.Contains("Москва")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = catalog.Contains("Казань")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		mockCityRepo.AssertNumberOfCalls(t, "GetCities", 1)
+	})
+
+	t.Run("Catalog is reloaded after expiry", func(t *testing.T) {
+		mockCityRepo := new(MockCityRepository)
+		catalog := service.NewCityCatalog(mockCityRepo, time.Millisecond)
+		mockCityRepo.On("GetCities").Return([]models.City{{Name: "Москва"}}, nil).Once()
+		mockCityRepo.On("GetCities").Return([]models.City{{Name: "Москва"}, {Name: "Казань"}}, nil).Once()
+
+		ok, err := catalog.Contains("Казань")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		time.Sleep(5 * time.Millisecond)
+		ok, err = catalog.Contains("Казань")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		mockCityRepo.AssertExpectations(t)
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		mockCityRepo := new(MockCityRepository)
+		catalog := service.NewCityCatalog(mockCityRepo, time.Minute)
+		mockCityRepo.On("GetCities").Return([]models.City(nil), errors.New("database error"))
+
+		_, err := catalog.Contains("Москва")
+		assert.EqualError(t, err, "database error")
+	})
+}
+
+func TestCityService_ChangesInvalidateCatalog(t *testing.T) {
+	mockCityRepo := new(MockCityRepository)
+	catalog := service.NewCityCatalog(mockCityRepo, time.Hour)
+	svc := service.NewCityService(mockCityRepo, catalog)
+
+	mockCityRepo.On("GetCities").Return([]models.City{{Name: "Москва"}}, nil).Once()
+	ok, err := catalog.Contains("Новосибирск")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	mockCityRepo.On("CreateCity", "Новосибирск").Return(models.City{ID: uuid.New(), Name: "Новосибирск"}, nil)
+	mockCityRepo.On("GetCities").Return([]models.City{{Name: "Москва"}, {Name: "Новосибирск"}}, nil).Once()
+
+	_, err = svc.CreateCity(" Новосибирск ")
+	assert.NoError(t, err)
+
+	ok, err = catalog.Contains("Новосибирск")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	mockCityRepo.AssertExpectations(t)
+}
+
+func TestCityService_Bad(t *testing.T) {
+	mockCityRepo := new(MockCityRepository)
+	svc := service.NewCityService(mockCityRepo, service.NewCityCatalog(mockCityRepo, time.Minute))
+
+	t.Run("City not found", func(t *testing.T) {
+		cityID := uuid.New()
+		mockCityRepo.On("GetCityByID", cityID).Return(models.City{}, nil)
+
+		_, err := svc.GetCityByID(cityID)
+		assert.ErrorIs(t, err, service.ErrCityNotFound)
+	})
+
+	t.Run("Update missing city", func(t *testing.T) {
+		cityID := uuid.New()
+		mockCityRepo.On("UpdateCity", cityID, "Казань").Return(models.City{}, nil)
+
+		_, err := svc.UpdateCity(cityID, "Казань")
+		assert.ErrorIs(t, err, service.ErrCityNotFound)
+	})
+
+	t.Run("Delete city with pvz", func(t *testing.T) {
+		cityID := uuid.New()
+		mockCityRepo.On("DeleteCity", cityID).Return(false, repository.ErrCityInUse)
+
+		err := svc.DeleteCity(cityID)
+		assert.ErrorIs(t, err, repository.ErrCityInUse)
+	})
+
+	t.Run("Whitespace-only name", func(t *testing.T) {
+		cityID := uuid.New()
+
+		_, err := svc.CreateCity("   ")
+		assert.ErrorIs(t, err, apperr.ErrValidation)
+		_, err = svc.UpdateCity(cityID, " \t ")
+		assert.ErrorIs(t, err, apperr.ErrValidation)
+		mockCityRepo.AssertNotCalled(t, "CreateCity", "")
+		mockCityRepo.AssertNotCalled(t, "UpdateCity", cityID, "")
+	})
+
+	t.Run("Delete missing city", func(t *testing.T) {
+		cityID := uuid.New()
+		mockCityRepo.On("DeleteCity", cityID).Return(false, nil)
+
+		err := svc.DeleteCity(cityID)
+		assert.ErrorIs(t, err, service.ErrCityNotFound)
+	})
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
+	"sync"
+	"time"
+)
+
+const defaultHealthCheckTimeout = 2 * time.Second
+
+// ReadinessProbe reports whether the instance accepts traffic. It turns false
+// while the process is draining on shutdown.
+type ReadinessProbe interface {
+	IsReady() bool
+}
+
+type HealthConfig struct {
+	// CheckTimeout bounds every dependency check separately.
+	CheckTimeout time.Duration
+	// MigrationVersion is the schema version the binary was started with.
+	MigrationVersion uint
+	// MaxPoolUsage is the share of MaxOpenConnections in use above which the
+	// pool is reported as saturated.
+	MaxPoolUsage float64
+	Readiness    ReadinessProbe
+}
+
+type HealthService struct {
+	healthRepo repository.HealthRepository
+	cfg        HealthConfig
+}
+
+func NewHealthService(healthRepo repository.HealthRepository, cfg HealthConfig) *HealthService {
+	if cfg.CheckTimeout <= 0 {
+		cfg.CheckTimeout = defaultHealthCheckTimeout
+	}
+	return &HealthService{healthRepo: healthRepo, cfg: cfg}
+}
+
+func (s *HealthService) Liveness() models.HealthReport {
+	return models.HealthReport{Status: models.HealthStatusOK}
+}
+
+func (s *HealthService) Readiness(ctx context.Context) models.HealthReport {
+	checks := map[string]func(ctx context.Context) models.ComponentHealth{
+		"postgres":   s.checkPostgres,
+		"migrations": s.checkMigrations,
+		"pool":       s.checkPool,
+	}
+
+	report := models.HealthReport{
+		Status:     models.HealthStatusOK,
+		Components: make(map[string]models.ComponentHealth, len(checks)+1),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(ctx context.Context) models.ComponentHealth) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, s.cfg.CheckTimeout)
+			defer cancel()
+
+			result := check(checkCtx)
+			mu.Lock()
+			report.Components[name] = result
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	if s.cfg.Readiness != nil && !s.cfg.Readiness.IsReady() {
+		report.Components["lifecycle"] = failed(fmt.Errorf("instance is shutting down"), nil)
+	}
+
+	for _, component := range report.Components {
+		if component.Status != models.HealthStatusOK {
+			report.Status = models.HealthStatusFail
+			break
+		}
+	}
+	return report
+}
+
+func (s *HealthService) checkPostgres(ctx context.Context) models.ComponentHealth {
+	if err := s.healthRepo.Ping(ctx); err != nil {
+		return failed(err, nil)
+	}
+	return models.ComponentHealth{Status: models.HealthStatusOK}
+}
+
+func (s *HealthService) checkMigrations(ctx context.Context) models.ComponentHealth {
+	version, dirty, err := s.healthRepo.MigrationVersion(ctx)
+	if err != nil {
+		return failed(err, nil)
+	}
+
+	details := map[string]interface{}{"version": version, "expected": s.cfg.MigrationVersion}
+	if dirty {
+		return failed(fmt.Errorf("migration %d is dirty", version), details)
+	}
+	// A newer schema is fine: another instance may have migrated ahead of us
+	// during a rolling deploy.
+	if version < s.cfg.MigrationVersion {
+		return failed(fmt.Errorf("schema version %d is behind expected %d", version, s.cfg.MigrationVersion), details)
+	}
+	return models.ComponentHealth{Status: models.HealthStatusOK, Details: details}
+}
+
+func (s *HealthService) checkPool(ctx context.Context) models.ComponentHealth {
+	stats := s.healthRepo.PoolStats()
+	details := map[string]interface{}{
+		"open":       stats.OpenConnections,
+		"in_use":     stats.InUse,
+		"idle":       stats.Idle,
+		"max_open":   stats.MaxOpenConnections,
+		"wait_count": stats.WaitCount,
+	}
+
+	if stats.MaxOpenConnections > 0 && s.cfg.MaxPoolUsage > 0 {
+		usage := float64(stats.InUse) / float64(stats.MaxOpenConnections)
+		details["usage"] = usage
+		if usage >= s.cfg.MaxPoolUsage {
+			return failed(fmt.Errorf("connection pool is saturated: %d of %d in use", stats.InUse, stats.MaxOpenConnections), details)
+		}
+	}
+	return models.ComponentHealth{Status: models.HealthStatusOK, Details: details}
+}
+
+func failed(err error, details map[string]interface{}) models.ComponentHealth {
+	return models.ComponentHealth{Status: models.HealthStatusFail, Error: err.Error(), Details: details}
+}
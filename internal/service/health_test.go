@@ -0,0 +1,151 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"pvz-test/internal/models"
+	"pvz-test/internal/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHealthRepository struct {
+	mock.Mock
+}
+
+func (m *MockHealthRepository) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockHealthRepository) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(uint), args.Bool(1), args.Error(2)
+}
+
+func (m *MockHealthRepository) PoolStats() sql.DBStats {
+	args := m.Called()
+	return args.Get(0).(sql.DBStats)
+}
+
+type staticReadiness bool
+
+func (r staticReadiness) IsReady() bool {
+	return bool(r)
+}
+
+func healthyRepository() *MockHealthRepository {
+	healthRepo := new(MockHealthRepository)
+	healthRepo.On("Ping", mock.Anything).Return(nil)
+	healthRepo.On("MigrationVersion", mock.Anything).Return(uint(5), false, nil)
+	healthRepo.On("PoolStats").Return(sql.DBStats{MaxOpenConnections: 10, InUse: 2, Idle: 3})
+	return healthRepo
+}
+
+func TestHealthService_Readiness(t *testing.T) {
+	cfg := service.HealthConfig{
+		CheckTimeout:     time.Second,
+		MigrationVersion: 5,
+		MaxPoolUsage:     0.9,
+		Readiness:        staticReadiness(true),
+	}
+
+	t.Run("All components healthy", func(t *testing.T) {
+		svc := service.NewHealthService(healthyRepository(), cfg)
+
+		report := svc.Readiness(context.Background())
+		assert.Equal(t, models.HealthStatusOK, report.Status)
+		assert.Len(t, report.Components, 3)
+		for _, component := range report.Components {
+			assert.Equal(t, models.HealthStatusOK, component.Status)
+		}
+	})
+
+	t.Run("Postgres unavailable", func(t *testing.T) {
+		healthRepo := new(MockHealthRepository)
+		healthRepo.On("Ping", mock.Anything).Return(errors.New("connection refused"))
+		healthRepo.On("MigrationVersion", mock.Anything).Return(uint(0), false, errors.New("connection refused"))
+		healthRepo.On("PoolStats").Return(sql.DBStats{})
+		svc := service.NewHealthService(healthRepo, cfg)
+
+		report := svc.Readiness(context.Background())
+		assert.Equal(t, models.HealthStatusFail, report.Status)
+		assert.Equal(t, "connection refused", report.Components["postgres"].Error)
+		assert.Equal(t, models.HealthStatusOK, report.Components["pool"].Status)
+	})
+
+	t.Run("Migration version behind", func(t *testing.T) {
+		healthRepo := new(MockHealthRepository)
+		healthRepo.On("Ping", mock.Anything).Return(nil)
+		healthRepo.On("MigrationVersion", mock.Anything).Return(uint(4), false, nil)
+		healthRepo.On("PoolStats").Return(sql.DBStats{})
+		svc := service.NewHealthService(healthRepo, cfg)
+
+		report := svc.Readiness(context.Background())
+		assert.Equal(t, models.HealthStatusFail, report.Status)
+		assert.Equal(t, models.HealthStatusFail, report.Components["migrations"].Status)
+	})
+
+	t.Run("Migration version ahead", func(t *testing.T) {
+		healthRepo := new(MockHealthRepository)
+		healthRepo.On("Ping", mock.Anything).Return(nil)
+		healthRepo.On("MigrationVersion", mock.Anything).Return(uint(6), false, nil)
+		healthRepo.On("PoolStats").Return(sql.DBStats{})
+		svc := service.NewHealthService(healthRepo, cfg)
+
+		report := svc.Readiness(context.Background())
+		assert.Equal(t, models.HealthStatusOK, report.Status)
+		assert.Equal(t, models.HealthStatusOK, report.Components["migrations"].Status)
+	})
+
+	t.Run("Migration dirty", func(t *testing.T) {
+		healthRepo := new(MockHealthRepository)
+		healthRepo.On("Ping", mock.Anything).Return(nil)
+		healthRepo.On("MigrationVersion", mock.Anything).Return(uint(5), true, nil)
+		healthRepo.On("PoolStats").Return(sql.DBStats{})
+		svc := service.NewHealthService(healthRepo, cfg)
+
+		report := svc.Readiness(context.Background())
+		assert.Equal(t, models.HealthStatusFail, report.Status)
+		assert.Equal(t, models.HealthStatusFail, report.Components["migrations"].Status)
+	})
+
+	t.Run("Pool saturated", func(t *testing.T) {
+		healthRepo := new(MockHealthRepository)
+		healthRepo.On("Ping", mock.Anything).Return(nil)
+		healthRepo.On("MigrationVersion", mock.Anything).Return(uint(5), false, nil)
+		healthRepo.On("PoolStats").Return(sql.DBStats{MaxOpenConnections: 10, InUse: 10})
+		svc := service.NewHealthService(healthRepo, cfg)
+
+		report := svc.Readiness(context.Background())
+		assert.Equal(t, models.HealthStatusFail, report.Status)
+		assert.Equal(t, models.HealthStatusFail, report.Components["pool"].Status)
+	})
+
+	t.Run("Check times out", func(t *testing.T) {
+		healthRepo := new(MockHealthRepository)
+		healthRepo.On("Ping", mock.Anything).Run(func(args mock.Arguments) {
+			<-args.Get(0).(context.Context).Done()
+		}).Return(context.DeadlineExceeded)
+		healthRepo.On("MigrationVersion", mock.Anything).Return(uint(5), false, nil)
+		healthRepo.On("PoolStats").Return(sql.DBStats{})
+		svc := service.NewHealthService(healthRepo, service.HealthConfig{CheckTimeout: 10 * time.Millisecond, MigrationVersion: 5})
+
+		report := svc.Readiness(context.Background())
+		assert.Equal(t, models.HealthStatusFail, report.Components["postgres"].Status)
+	})
+
+	t.Run("Instance is draining", func(t *testing.T) {
+		draining := cfg
+		draining.Readiness = staticReadiness(false)
+		svc := service.NewHealthService(healthyRepository(), draining)
+
+		report := svc.Readiness(context.Background())
+		assert.Equal(t, models.HealthStatusFail, report.Status)
+		assert.Equal(t, models.HealthStatusFail, report.Components["lifecycle"].Status)
+	})
+}
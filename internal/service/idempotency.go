@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrIdempotencyKeyReused     = apperr.New(apperr.ErrUnprocessable, "idempotency key was used with a different request")
+	ErrIdempotencyKeyInProgress = apperr.New(apperr.ErrConflict, "request with this idempotency key is in progress")
+)
+
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// IdempotencyStore remembers the first successful response to a request
+// with an Idempotency-Key so that retries get it back instead of repeating
+// the request.
+type IdempotencyStore struct {
+	repo repository.IdempotencyRepository
+	ttl  time.Duration
+}
+
+func NewIdempotencyStore(repo repository.IdempotencyRepository, ttl time.Duration) *IdempotencyStore {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	return &IdempotencyStore{repo: repo, ttl: ttl}
+}
+
+// Begin reserves key for a request whose body hashes to requestHash. It
+// returns the stored response if the same request already completed, and
+// nil if the caller should run the request and then Complete or Release.
+func (s *IdempotencyStore) Begin(key models.IdempotencyKey, requestHash string) (*models.StoredResponse, error) {
+	record, reserved, err := s.repo.ReserveKey(key, requestHash, s.ttl)
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+	if record.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if record.Response == nil {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	return record.Response, nil
+}
+
+func (s *IdempotencyStore) Complete(key models.IdempotencyKey, response models.StoredResponse) error {
+	return s.repo.SaveResponse(key, response)
+}
+
+// Release forgets a reserved key so that a retry runs the request again.
+func (s *IdempotencyStore) Release(key models.IdempotencyKey) error {
+	return s.repo.DeleteKey(key)
+}
+
+// Purge deletes expired keys every interval until ctx is cancelled.
+func (s *IdempotencyStore) Purge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.repo.DeleteExpiredKeys(); err != nil {
+				logrus.Errorf("idempotency key purge error: %s", err.Error())
+			}
+		}
+	}
+}
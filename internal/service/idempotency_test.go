@@ -0,0 +1,78 @@
+package service_test
+
+import (
+	"pvz-test/internal/models"
+	"pvz-test/internal/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) ReserveKey(key models.IdempotencyKey, requestHash string, ttl time.Duration) (models.IdempotencyRecord, bool, error) {
+	args := m.Called(key, requestHash, ttl)
+	return args.Get(0).(models.IdempotencyRecord), args.Bool(1), args.Error(2)
+}
+
+func (m *MockIdempotencyRepository) SaveResponse(key models.IdempotencyKey, response models.StoredResponse) error {
+	args := m.Called(key, response)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteKey(key models.IdempotencyKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpiredKeys() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestIdempotencyStore_Begin(t *testing.T) {
+	repo := new(MockIdempotencyRepository)
+	store := service.NewIdempotencyStore(repo, time.Hour)
+	key := models.IdempotencyKey{UserID: uuid.New(), Route: "POST /api/pvz", Key: "key-1"}
+
+	t.Run("New key", func(t *testing.T) {
+		repo.On("ReserveKey", key, "hash", time.Hour).Return(models.IdempotencyRecord{}, true, nil).Once()
+
+		stored, err := store.Begin(key, "hash")
+		assert.NoError(t, err)
+		assert.Nil(t, stored)
+	})
+
+	t.Run("Completed request is replayed", func(t *testing.T) {
+		response := &models.StoredResponse{StatusCode: 201, ContentType: "application/json", Body: []byte(`{}`)}
+		repo.On("ReserveKey", key, "hash", time.Hour).
+			Return(models.IdempotencyRecord{RequestHash: "hash", Response: response}, false, nil).Once()
+
+		stored, err := store.Begin(key, "hash")
+		assert.NoError(t, err)
+		assert.Equal(t, response, stored)
+	})
+
+	t.Run("Different request", func(t *testing.T) {
+		repo.On("ReserveKey", key, "other", time.Hour).
+			Return(models.IdempotencyRecord{RequestHash: "hash"}, false, nil).Once()
+
+		_, err := store.Begin(key, "other")
+		assert.ErrorIs(t, err, service.ErrIdempotencyKeyReused)
+	})
+
+	t.Run("Request in progress", func(t *testing.T) {
+		repo.On("ReserveKey", key, "hash", time.Hour).
+			Return(models.IdempotencyRecord{RequestHash: "hash"}, false, nil).Once()
+
+		_, err := store.Begin(key, "hash")
+		assert.ErrorIs(t, err, service.ErrIdempotencyKeyInProgress)
+	})
+
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var ErrUnknownKeyID = errors.New("unknown signing key id")
+
+type SigningKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+func NewHMACKey(id string, secret []byte) SigningKey {
+	return SigningKey{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+// LoadPEMKey reads an RS256 or EdDSA key from a PEM file. A private key can
+// both sign and verify, a public key is only used for verification.
+func LoadPEMKey(id string, method jwt.SigningMethod, path string) (SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("read key %s: %w", id, err)
+	}
+
+	key := SigningKey{ID: id, Method: method}
+	switch method {
+	case jwt.SigningMethodRS256:
+		if private, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			key.signKey, key.verifyKey = private, &private.PublicKey
+			return key, nil
+		}
+		public, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return SigningKey{}, fmt.Errorf("parse RSA key %s: %w", id, err)
+		}
+		key.verifyKey = public
+	case jwt.SigningMethodEdDSA:
+		if private, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+			edPrivate, ok := private.(ed25519.PrivateKey)
+			if !ok {
+				return SigningKey{}, fmt.Errorf("key %s is not an Ed25519 private key", id)
+			}
+			key.signKey, key.verifyKey = edPrivate, edPrivate.Public()
+			return key, nil
+		}
+		public, err := jwt.ParseEdPublicKeyFromPEM(data)
+		if err != nil {
+			return SigningKey{}, fmt.Errorf("parse EdDSA key %s: %w", id, err)
+		}
+		key.verifyKey = public
+	default:
+		return SigningKey{}, fmt.Errorf("unsupported PEM signing method %s for key %s", method.Alg(), id)
+	}
+
+	return key, nil
+}
+
+// ParseKeySpecs parses a comma separated list of "kid:ALG:value" entries.
+// For HS256 the value is the secret itself, for RS256 and EdDSA it is a path
+// to a PEM file.
+func ParseKeySpecs(spec string) ([]SigningKey, error) {
+	var keys []SigningKey
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid key spec %q, expected kid:ALG:value", entry)
+		}
+
+		id, alg, value := parts[0], parts[1], parts[2]
+		switch alg {
+		case jwt.SigningMethodHS256.Alg():
+			keys = append(keys, NewHMACKey(id, []byte(value)))
+		case jwt.SigningMethodRS256.Alg():
+			key, err := LoadPEMKey(id, jwt.SigningMethodRS256, value)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		case jwt.SigningMethodEdDSA.Alg():
+			key, err := LoadPEMKey(id, jwt.SigningMethodEdDSA, value)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		default:
+			return nil, fmt.Errorf("unsupported signing method %s for key %s", alg, id)
+		}
+	}
+	return keys, nil
+}
+
+// Keyring signs tokens with the active key and verifies them with whichever
+// key the kid header points to, so old keys keep working during rotation.
+type Keyring struct {
+	activeID string
+	keys     map[string]SigningKey
+}
+
+func NewKeyring(activeID string, keys ...SigningKey) (*Keyring, error) {
+	k := &Keyring{activeID: activeID, keys: make(map[string]SigningKey, len(keys))}
+	for _, key := range keys {
+		if _, ok := k.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate signing key id %s", key.ID)
+		}
+		k.keys[key.ID] = key
+	}
+
+	active, ok := k.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("active signing key %s: %w", activeID, ErrUnknownKeyID)
+	}
+	if active.signKey == nil {
+		return nil, fmt.Errorf("active signing key %s has no private part", activeID)
+	}
+	return k, nil
+}
+
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	key := k.keys[k.activeID]
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.signKey)
+}
+
+// Keyfunc resolves the verification key for jwt.Parse. Tokens issued before
+// key ids were introduced carry no kid and are checked against the active key.
+func (k *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = k.activeID
+	}
+
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("invalid signing method")
+	}
+	return key.verifyKey, nil
+}
@@ -0,0 +1,180 @@
+package service_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"pvz-test/internal/models"
+	"pvz-test/internal/service"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClaims(role models.Role) *service.TokenClaims {
+	return &service.TokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(time.Hour).Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+		UserId: uuid.New(),
+		Role:   role,
+	}
+}
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	path := filepath.Join(t.TempDir(), blockType+".pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	oldKey := service.NewHMACKey("2025-03", []byte("old-secret"))
+	newKey := service.NewHMACKey("2025-04", []byte("new-secret"))
+
+	oldKeyring, err := service.NewKeyring("2025-03", oldKey)
+	require.NoError(t, err)
+	rotatedKeyring, err := service.NewKeyring("2025-04", oldKey, newKey)
+	require.NoError(t, err)
+
+	oldToken, err := oldKeyring.Sign(newClaims(models.RoleEmployee))
+	require.NoError(t, err)
+
+	t.Run("Token signed with previous key is still valid", func(t *testing.T) {
+		tokenRepo := newTokenRepository()
+		authService := service.NewAuthService(nil, tokenRepo, service.NewRevocationList(tokenRepo), service.AuthConfig{Keyring: rotatedKeyring, TokenTTL: time.Hour})
+
+		_, role, err := authService.ParseToken(oldToken)
+		assert.NoError(t, err)
+		assert.Equal(t, models.RoleEmployee, role)
+	})
+
+	t.Run("New tokens carry the active kid", func(t *testing.T) {
+		token, err := rotatedKeyring.Sign(newClaims(models.RoleModerator))
+		require.NoError(t, err)
+
+		parsed, _, err := new(jwt.Parser).ParseUnverified(token, &service.TokenClaims{})
+		require.NoError(t, err)
+		assert.Equal(t, "2025-04", parsed.Header["kid"])
+	})
+
+	t.Run("Unknown kid is rejected", func(t *testing.T) {
+		tokenRepo := newTokenRepository()
+		authService := service.NewAuthService(nil, tokenRepo, service.NewRevocationList(tokenRepo), service.AuthConfig{Keyring: oldKeyring, TokenTTL: time.Hour})
+		token, err := rotatedKeyring.Sign(newClaims(models.RoleModerator))
+		require.NoError(t, err)
+
+		_, _, err = authService.ParseToken(token)
+		assert.ErrorIs(t, err, service.ErrUnknownKeyID)
+	})
+
+	t.Run("Algorithm must match the key", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS512, newClaims(models.RoleModerator))
+		token.Header["kid"] = "2025-04"
+		tokenString, err := token.SignedString([]byte("new-secret"))
+		require.NoError(t, err)
+
+		_, err = jwt.ParseWithClaims(tokenString, &service.TokenClaims{}, rotatedKeyring.Keyfunc)
+		assert.Error(t, err)
+	})
+}
+
+func TestNewKeyring_Bad(t *testing.T) {
+	t.Run("Missing active key", func(t *testing.T) {
+		_, err := service.NewKeyring("missing", service.NewHMACKey("k1", []byte("secret")))
+		assert.ErrorIs(t, err, service.ErrUnknownKeyID)
+	})
+
+	t.Run("Duplicate key id", func(t *testing.T) {
+		_, err := service.NewKeyring("k1", service.NewHMACKey("k1", []byte("a")), service.NewHMACKey("k1", []byte("b")))
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadPEMKey(t *testing.T) {
+	t.Run("RS256 verified with public key only", func(t *testing.T) {
+		private, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		publicDER, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+		require.NoError(t, err)
+
+		signer, err := service.LoadPEMKey("rsa", jwt.SigningMethodRS256, writePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(private)))
+		require.NoError(t, err)
+		verifier, err := service.LoadPEMKey("rsa", jwt.SigningMethodRS256, writePEM(t, "PUBLIC KEY", publicDER))
+		require.NoError(t, err)
+
+		signKeyring, err := service.NewKeyring("rsa", signer)
+		require.NoError(t, err)
+		token, err := signKeyring.Sign(newClaims(models.RoleEmployee))
+		require.NoError(t, err)
+
+		_, err = service.NewKeyring("rsa", verifier)
+		assert.Error(t, err, "public key alone cannot be the active signing key")
+
+		_, err = jwt.ParseWithClaims(token, &service.TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+			return &private.PublicKey, nil
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("EdDSA key pair", func(t *testing.T) {
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+		require.NoError(t, err)
+		publicDER, err := x509.MarshalPKIXPublicKey(public)
+		require.NoError(t, err)
+
+		signer, err := service.LoadPEMKey("ed", jwt.SigningMethodEdDSA, writePEM(t, "PRIVATE KEY", privateDER))
+		require.NoError(t, err)
+		verifier, err := service.LoadPEMKey("ed-public", jwt.SigningMethodEdDSA, writePEM(t, "PUBLIC KEY", publicDER))
+		require.NoError(t, err)
+
+		keyring, err := service.NewKeyring("ed", signer, verifier)
+		require.NoError(t, err)
+		tokenRepo := newTokenRepository()
+		authService := service.NewAuthService(nil, tokenRepo, service.NewRevocationList(tokenRepo), service.AuthConfig{Keyring: keyring, TokenTTL: time.Hour})
+
+		tokens, err := authService.DummyLogin(models.RoleModerator)
+		require.NoError(t, err)
+		token := tokens.AccessToken
+
+		_, role, err := authService.ParseToken(token)
+		assert.NoError(t, err)
+		assert.Equal(t, models.RoleModerator, role)
+
+		_, err = jwt.ParseWithClaims(token, &service.TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+			return public, nil
+		})
+		assert.NoError(t, err)
+	})
+}
+
+func TestParseKeySpecs(t *testing.T) {
+	t.Run("HMAC keys", func(t *testing.T) {
+		keys, err := service.ParseKeySpecs("k1:HS256:first, k2:HS256:se:cret")
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.Equal(t, "k1", keys[0].ID)
+		assert.Equal(t, "k2", keys[1].ID)
+	})
+
+	t.Run("Malformed entry", func(t *testing.T) {
+		_, err := service.ParseKeySpecs("k1:HS256")
+		assert.Error(t, err)
+	})
+
+	t.Run("Unsupported algorithm", func(t *testing.T) {
+		_, err := service.ParseKeySpecs("k1:none:secret")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EventSink receives domain events from the outbox. Delivery is at least
+// once: an event is published again if any sink fails or the dispatcher
+// stops before recording the delivery, so sinks must tolerate duplicates
+// and can deduplicate by event id.
+type EventSink interface {
+	Publish(ctx context.Context, event models.Event) error
+}
+
+type OutboxConfig struct {
+	Sinks     []EventSink
+	BatchSize int
+	// Lease is how long claimed events stay hidden from other dispatchers.
+	Lease     time.Duration
+	RetryBase time.Duration
+	RetryMax  time.Duration
+}
+
+// OutboxDispatcher delivers events written by the repositories to every sink
+// and reschedules failed ones with exponential backoff.
+type OutboxDispatcher struct {
+	repo repository.OutboxRepository
+	cfg  OutboxConfig
+}
+
+func NewOutboxDispatcher(repo repository.OutboxRepository, cfg OutboxConfig) *OutboxDispatcher {
+	return &OutboxDispatcher{repo: repo, cfg: cfg}
+}
+
+// Dispatch delivers one batch of due events and returns how many were claimed.
+func (d *OutboxDispatcher) Dispatch(ctx context.Context) (int, error) {
+	events, err := d.repo.ClaimEvents(d.cfg.BatchSize, d.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		if ctx.Err() != nil {
+			// The rest are claimed and will be retried after the lease.
+			return len(events), ctx.Err()
+		}
+
+		if err := d.publish(ctx, event); err != nil {
+			retryAt := time.Now().Add(retryDelay(event.Attempts, d.cfg.RetryBase, d.cfg.RetryMax))
+			logrus.Warnf("outbox event %s (%s) delivery failed, attempt %d, retry at %s: %s",
+				event.ID, event.Type, event.Attempts, retryAt.Format(time.RFC3339), err.Error())
+			if err := d.repo.MarkFailed(event.ID, err.Error(), retryAt); err != nil {
+				return len(events), err
+			}
+			continue
+		}
+
+		if err := d.repo.MarkDelivered(event.ID); err != nil {
+			return len(events), err
+		}
+	}
+	return len(events), nil
+}
+
+// Run dispatches due events every interval until ctx is cancelled. A full
+// batch is followed by the next one right away so a backlog drains quickly.
+func (d *OutboxDispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				claimed, err := d.Dispatch(ctx)
+				if err != nil {
+					if !errors.Is(err, context.Canceled) {
+						logrus.Errorf("outbox dispatch error: %s", err.Error())
+					}
+					break
+				}
+				if claimed < d.cfg.BatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+func (d *OutboxDispatcher) publish(ctx context.Context, event models.Event) error {
+	var errs []error
+	for _, sink := range d.cfg.Sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// retryDelay doubles base for every attempt after the first, up to max.
+func retryDelay(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
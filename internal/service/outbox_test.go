@@ -0,0 +1,159 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"pvz-test/internal/models"
+	"pvz-test/internal/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) ClaimEvents(limit int, lease time.Duration) ([]models.Event, error) {
+	args := m.Called(limit, lease)
+	return args.Get(0).([]models.Event), args.Error(1)
+}
+
+func (m *MockOutboxRepository) MarkDelivered(eventID uuid.UUID) error {
+	args := m.Called(eventID)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkFailed(eventID uuid.UUID, reason string, retryAt time.Time) error {
+	args := m.Called(eventID, reason, retryAt)
+	return args.Error(0)
+}
+
+type failingSink struct{}
+
+func (failingSink) Publish(ctx context.Context, event models.Event) error {
+	return errors.New("sink unavailable")
+}
+
+func outboxConfig(sinks ...service.EventSink) service.OutboxConfig {
+	return service.OutboxConfig{
+		Sinks:     sinks,
+		BatchSize: 10,
+		Lease:     30 * time.Second,
+		RetryBase: time.Second,
+		RetryMax:  time.Minute,
+	}
+}
+
+func newTestEvent(eventType models.EventType, attempts int) models.Event {
+	return models.Event{
+		ID:          uuid.New(),
+		Type:        eventType,
+		AggregateID: uuid.New(),
+		PVZID:       uuid.New(),
+		Payload:     json.RawMessage(`{}`),
+		OccurredAt:  time.Now(),
+		Attempts:    attempts,
+	}
+}
+
+func TestOutboxDispatcher_Dispatch(t *testing.T) {
+	t.Run("Events reach every sink", func(t *testing.T) {
+		repo := new(MockOutboxRepository)
+		first, second := service.NewMemorySink(), service.NewMemorySink()
+		dispatcher := service.NewOutboxDispatcher(repo, outboxConfig(first, second))
+
+		events := []models.Event{
+			newTestEvent(models.EventReceptionOpened, 1),
+			newTestEvent(models.EventProductAdded, 1),
+		}
+		repo.On("ClaimEvents", 10, 30*time.Second).Return(events, nil).Once()
+		repo.On("MarkDelivered", events[0].ID).Return(nil).Once()
+		repo.On("MarkDelivered", events[1].ID).Return(nil).Once()
+
+		claimed, err := dispatcher.Dispatch(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, claimed)
+		assert.Equal(t, events, first.Events())
+		assert.Equal(t, events, second.Events())
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Failed delivery is retried with backoff", func(t *testing.T) {
+		repo := new(MockOutboxRepository)
+		memory := service.NewMemorySink()
+		dispatcher := service.NewOutboxDispatcher(repo, outboxConfig(memory, failingSink{}))
+
+		event := newTestEvent(models.EventReceptionClosed, 3)
+		repo.On("ClaimEvents", 10, 30*time.Second).Return([]models.Event{event}, nil).Once()
+		repo.On("MarkFailed", event.ID, "sink unavailable", mock.MatchedBy(func(retryAt time.Time) bool {
+			return time.Until(retryAt) > 3*time.Second && time.Until(retryAt) <= 4*time.Second
+		})).Return(nil).Once()
+
+		_, err := dispatcher.Dispatch(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, memory.Events(), 1)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Backoff is capped", func(t *testing.T) {
+		repo := new(MockOutboxRepository)
+		dispatcher := service.NewOutboxDispatcher(repo, outboxConfig(failingSink{}))
+
+		event := newTestEvent(models.EventPVZCreated, 40)
+		repo.On("ClaimEvents", 10, 30*time.Second).Return([]models.Event{event}, nil).Once()
+		repo.On("MarkFailed", event.ID, "sink unavailable", mock.MatchedBy(func(retryAt time.Time) bool {
+			return time.Until(retryAt) > 59*time.Second && time.Until(retryAt) <= time.Minute
+		})).Return(nil).Once()
+
+		_, err := dispatcher.Dispatch(context.Background())
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Claim error", func(t *testing.T) {
+		repo := new(MockOutboxRepository)
+		dispatcher := service.NewOutboxDispatcher(repo, outboxConfig(service.LogSink{}))
+
+		repo.On("ClaimEvents", 10, 30*time.Second).Return([]models.Event(nil), errors.New("database error")).Once()
+
+		_, err := dispatcher.Dispatch(context.Background())
+		assert.EqualError(t, err, "database error")
+	})
+}
+
+func TestWebhookSink_Publish(t *testing.T) {
+	event := newTestEvent(models.EventProductRemoved, 1)
+
+	t.Run("Delivered", func(t *testing.T) {
+		var received models.Event
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, event.ID.String(), r.Header.Get("X-Event-Id"))
+			assert.Equal(t, string(models.EventProductRemoved), r.Header.Get("X-Event-Type"))
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		sink := service.NewWebhookSink(server.URL, time.Second)
+		assert.NoError(t, sink.Publish(context.Background(), event))
+		assert.Equal(t, event.ID, received.ID)
+		assert.Equal(t, event.AggregateID, received.AggregateID)
+	})
+
+	t.Run("Receiver error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		sink := service.NewWebhookSink(server.URL, time.Second)
+		assert.EqualError(t, sink.Publish(context.Background(), event), "webhook responded with status 503")
+	})
+}
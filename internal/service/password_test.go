@@ -0,0 +1,102 @@
+package service_test
+
+import (
+	"pvz-test/internal/service"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher := service.NewArgon2idHasher()
+
+	t.Run("Hash and verify", func(t *testing.T) {
+		hash, err := hasher.Hash("password")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=1,p=4$"))
+		assert.True(t, hasher.Supports(hash))
+		assert.False(t, hasher.NeedsRehash(hash))
+
+		valid, err := hasher.Verify("password", hash)
+		assert.NoError(t, err)
+		assert.True(t, valid)
+
+		valid, err = hasher.Verify("different_password", hash)
+		assert.NoError(t, err)
+		assert.False(t, valid)
+	})
+
+	t.Run("Random salt per hash", func(t *testing.T) {
+		first, err := hasher.Hash("password")
+		require.NoError(t, err)
+		second, err := hasher.Hash("password")
+		require.NoError(t, err)
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("Weaker parameters need rehash", func(t *testing.T) {
+		weak := &service.Argon2idHasher{Memory: 8 * 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+		hash, err := weak.Hash("password")
+		require.NoError(t, err)
+
+		valid, err := hasher.Verify("password", hash)
+		assert.NoError(t, err)
+		assert.True(t, valid)
+		assert.True(t, hasher.NeedsRehash(hash))
+	})
+
+	t.Run("Malformed hash", func(t *testing.T) {
+		_, err := hasher.Verify("password", "$argon2id$broken")
+		assert.ErrorIs(t, err, service.ErrInvalidPasswordHash)
+	})
+}
+
+func TestBcryptHasher(t *testing.T) {
+	hasher := &service.BcryptHasher{Cost: bcrypt.MinCost}
+
+	hash, err := hasher.Hash("password")
+	require.NoError(t, err)
+	assert.True(t, hasher.Supports(hash))
+
+	valid, err := hasher.Verify("password", hash)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = hasher.Verify("different_password", hash)
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	assert.True(t, service.NewBcryptHasher().NeedsRehash(hash))
+}
+
+func TestLegacySHA1Hasher(t *testing.T) {
+	hasher := &service.LegacySHA1Hasher{Salt: "someSalt"}
+	hash := service.GeneratePasswordHash("password")
+
+	assert.True(t, hasher.Supports(hash))
+	assert.True(t, hasher.NeedsRehash(hash))
+
+	valid, err := hasher.Verify("password", hash)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = hasher.Verify("different_password", hash)
+	assert.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestNewPasswordHasher(t *testing.T) {
+	hasher, err := service.NewPasswordHasher("bcrypt")
+	assert.NoError(t, err)
+	assert.IsType(t, &service.BcryptHasher{}, hasher)
+
+	hasher, err = service.NewPasswordHasher("")
+	assert.NoError(t, err)
+	assert.IsType(t, &service.Argon2idHasher{}, hasher)
+
+	_, err = service.NewPasswordHasher("md5")
+	assert.Error(t, err)
+}
@@ -2,69 +2,149 @@ package service
 
 import (
 	"fmt"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/metrics"
 	"pvz-test/internal/models"
 	"pvz-test/internal/repository"
 	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPvzNotFound           = apperr.New(apperr.ErrNotFound, "pvz not found")
+	ErrPvzDeactivated        = repository.ErrPvzDeactivated
+	ErrPvzHasActiveReception = repository.ErrPvzHasActiveReception
 )
 
 type PvzService struct {
 	pvzRepo       repository.PvzRepository
 	receptionRepo repository.ReceptionRepository
+	cities        *CityCatalog
 }
 
-func NewPvzService(pvzRepo repository.PvzRepository, receptionRepo repository.ReceptionRepository) *PvzService {
-	return &PvzService{pvzRepo: pvzRepo, receptionRepo: receptionRepo}
+func NewPvzService(pvzRepo repository.PvzRepository, receptionRepo repository.ReceptionRepository, cities *CityCatalog) *PvzService {
+	return &PvzService{pvzRepo: pvzRepo, receptionRepo: receptionRepo, cities: cities}
 }
 
-var allowedCities = map[string]struct{}{
-	"Москва":          {},
-	"Санкт-Петербург": {},
-	"Казань":          {},
-}
+func (s *PvzService) CreatePvz(actor models.Actor, city string) (models.PVZ, error) {
 
-func (s *PvzService) CreatePvz(city string) (models.PVZ, error) {
-
-	if _, ok := allowedCities[city]; !ok {
-		return models.PVZ{}, fmt.Errorf("city %s city is not supported", city)
+	supported, err := s.cities.Contains(city)
+	if err != nil {
+		return models.PVZ{}, fmt.Errorf("city catalog error: %w", err)
+	}
+	if !supported {
+		return models.PVZ{}, cityNotSupported(city)
 	}
-	pvz, err := s.pvzRepo.CreatePvz(city)
+	pvz, err := s.pvzRepo.CreatePvz(actor, city)
 	if err != nil {
-		return models.PVZ{}, fmt.Errorf("pvz create error: %s", err.Error())
+		return models.PVZ{}, fmt.Errorf("pvz create error: %w", err)
 	}
+	metrics.PVZCreatedTotal.WithLabelValues(pvz.City).Inc()
 
 	return pvz, nil
 }
 
-func (s *PvzService) GetFilteredPVZ(start, end *time.Time, limit, offset int) ([]models.PVZResponse, error) {
-	pvzs, err := s.pvzRepo.GetPVZList(limit, offset)
+func (s *PvzService) GetFilteredPVZ(start, end *time.Time, includeEmpty bool, status models.PVZStatus, limit, offset int) ([]models.PVZResponse, error) {
+	var (
+		pvzs []models.PVZ
+		err  error
+	)
+	if includeEmpty || (start == nil && end == nil) {
+		pvzs, err = s.pvzRepo.GetPVZList(status, limit, offset)
+	} else {
+		pvzs, err = s.pvzRepo.GetPVZListWithReceptions(start, end, status, limit, offset)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if len(pvzs) == 0 {
+		return nil, nil
+	}
 
-	var result []models.PVZResponse
+	pvzIDs := make([]uuid.UUID, 0, len(pvzs))
 	for _, pvz := range pvzs {
-		receptions, err := s.receptionRepo.GetReceptionsWithProducts(pvz.ID, start, end)
-		if err != nil {
-			return nil, err
-		}
-
-		var blocks []models.ReceptionBlock
-		for _, r := range receptions {
-			items, err := s.receptionRepo.GetItemsByReceptionID(r.ID)
-			if err != nil {
-				return nil, err
-			}
-			blocks = append(blocks, models.ReceptionBlock{
-				Reception: r,
-				Products:  items,
-			})
-		}
+		pvzIDs = append(pvzIDs, pvz.ID)
+	}
 
+	blocks, err := s.receptionRepo.GetReceptionBlocksByPVZIDs(pvzIDs, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	blocksByPVZ := make(map[uuid.UUID][]models.ReceptionBlock, len(pvzs))
+	for _, block := range blocks {
+		blocksByPVZ[block.Reception.PVZID] = append(blocksByPVZ[block.Reception.PVZID], block)
+	}
+
+	result := make([]models.PVZResponse, 0, len(pvzs))
+	for _, pvz := range pvzs {
 		result = append(result, models.PVZResponse{
 			PVZ:        pvz,
-			Receptions: blocks,
+			Receptions: blocksByPVZ[pvz.ID],
 		})
 	}
 
 	return result, nil
 }
+
+func (s *PvzService) GetPVZList() ([]models.PVZ, error) {
+	return s.pvzRepo.GetAllPVZ()
+}
+
+func (s *PvzService) GetPVZByID(pvzID uuid.UUID) (models.PVZ, error) {
+	pvz, err := s.pvzRepo.GetPVZByID(pvzID)
+	if err != nil {
+		return models.PVZ{}, err
+	}
+	if (pvz == models.PVZ{}) {
+		return models.PVZ{}, fmt.Errorf("%w: %s", ErrPvzNotFound, pvzID.String())
+	}
+	return pvz, nil
+}
+
+func (s *PvzService) UpdatePVZ(actor models.Actor, pvzID uuid.UUID, city string) (models.PVZ, error) {
+	pvz, err := s.GetPVZByID(pvzID)
+	if err != nil {
+		return models.PVZ{}, err
+	}
+	if !pvz.Active() {
+		return models.PVZ{}, fmt.Errorf("%w: %s", ErrPvzDeactivated, pvzID.String())
+	}
+
+	supported, err := s.cities.Contains(city)
+	if err != nil {
+		return models.PVZ{}, fmt.Errorf("city catalog error: %w", err)
+	}
+	if !supported {
+		return models.PVZ{}, cityNotSupported(city)
+	}
+
+	pvz, err = s.pvzRepo.UpdatePVZ(actor, pvzID, city)
+	if err != nil {
+		return models.PVZ{}, err
+	}
+	if (pvz == models.PVZ{}) {
+		return models.PVZ{}, fmt.Errorf("%w: %s", ErrPvzNotFound, pvzID.String())
+	}
+	return pvz, nil
+}
+
+func (s *PvzService) DeactivatePVZ(actor models.Actor, pvzID uuid.UUID) (models.PVZ, error) {
+	pvz, err := s.GetPVZByID(pvzID)
+	if err != nil {
+		return models.PVZ{}, err
+	}
+	if !pvz.Active() {
+		return pvz, nil
+	}
+
+	pvz, err = s.pvzRepo.DeactivatePVZ(actor, pvzID)
+	if err != nil {
+		return models.PVZ{}, err
+	}
+	if (pvz == models.PVZ{}) {
+		return models.PVZ{}, fmt.Errorf("%w: %s", ErrPvzNotFound, pvzID.String())
+	}
+	return pvz, nil
+}
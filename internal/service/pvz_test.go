@@ -2,12 +2,19 @@ package service_test
 
 import (
 	"errors"
+	"fmt"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/metrics"
 	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
 	"pvz-test/internal/service"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -16,33 +23,70 @@ type MockPvzRepository struct {
 	mock.Mock
 }
 
-func (m *MockPvzRepository) CreatePvz(city string) (models.PVZ, error) {
-	args := m.Called(city)
+func (m *MockPvzRepository) CreatePvz(actor models.Actor, city string) (models.PVZ, error) {
+	args := m.Called(actor, city)
 	return args.Get(0).(models.PVZ), args.Error(1)
 }
 
-func (m *MockPvzRepository) GetPVZList(limit, offset int) ([]models.PVZ, error) {
-	args := m.Called(limit, offset)
+func (m *MockPvzRepository) GetPVZList(status models.PVZStatus, limit, offset int) ([]models.PVZ, error) {
+	args := m.Called(status, limit, offset)
 	return args.Get(0).([]models.PVZ), args.Error(1)
 }
 
-func (m *MockReceptionRepository) GetReceptionsWithProducts(pvzID uuid.UUID, start, end *time.Time) ([]models.Reception, error) {
-	args := m.Called(pvzID, start, end)
+func (m *MockPvzRepository) GetPVZListWithReceptions(start, end *time.Time, status models.PVZStatus, limit, offset int) ([]models.PVZ, error) {
+	args := m.Called(start, end, status, limit, offset)
+	return args.Get(0).([]models.PVZ), args.Error(1)
+}
+
+func (m *MockPvzRepository) GetAllPVZ() ([]models.PVZ, error) {
+	args := m.Called()
+	return args.Get(0).([]models.PVZ), args.Error(1)
+}
+
+func (m *MockPvzRepository) GetPVZByID(pvzID uuid.UUID) (models.PVZ, error) {
+	args := m.Called(pvzID)
+	return args.Get(0).(models.PVZ), args.Error(1)
+}
+
+func (m *MockPvzRepository) UpdatePVZ(actor models.Actor, pvzID uuid.UUID, city string) (models.PVZ, error) {
+	args := m.Called(actor, pvzID, city)
+	return args.Get(0).(models.PVZ), args.Error(1)
+}
+
+func (m *MockPvzRepository) DeactivatePVZ(actor models.Actor, pvzID uuid.UUID) (models.PVZ, error) {
+	args := m.Called(actor, pvzID)
+	return args.Get(0).(models.PVZ), args.Error(1)
+}
+
+func (m *MockReceptionRepository) GetReceptionsWithProducts(pvzID uuid.UUID, filter models.ReceptionFilter) ([]models.Reception, error) {
+	args := m.Called(pvzID, filter)
 	return args.Get(0).([]models.Reception), args.Error(1)
 }
 
+func (m *MockReceptionRepository) GetReceptionByID(receptionID uuid.UUID) (models.Reception, error) {
+	args := m.Called(receptionID)
+	return args.Get(0).(models.Reception), args.Error(1)
+}
+
 func (m *MockReceptionRepository) GetItemsByReceptionID(receptionID uuid.UUID) ([]models.Item, error) {
 	args := m.Called(receptionID)
 	return args.Get(0).([]models.Item), args.Error(1)
 }
 
+func (m *MockReceptionRepository) GetReceptionBlocksByPVZIDs(pvzIDs []uuid.UUID, start, end *time.Time) ([]models.ReceptionBlock, error) {
+	args := m.Called(pvzIDs, start, end)
+	return args.Get(0).([]models.ReceptionBlock), args.Error(1)
+}
+
 func TestPvzService_CreatePvz(t *testing.T) {
 	mockPvzRepo := new(MockPvzRepository)
 	mockReceptionRepo := new(MockReceptionRepository)
-	service := service.NewPvzService(mockPvzRepo, mockReceptionRepo)
+	mockCityRepo := new(MockCityRepository)
+	mockCityRepo.On("GetCities").Return([]models.City{{Name: "Москва"}}, nil).Once()
+	service := service.NewPvzService(mockPvzRepo, mockReceptionRepo, service.NewCityCatalog(mockCityRepo, time.Minute))
 
 	t.Run("Invalid city", func(t *testing.T) {
-		_, err := service.CreatePvz("InvalidCity")
+		_, err := service.CreatePvz(moderator, "InvalidCity")
 		assert.EqualError(t, err, "city InvalidCity city is not supported")
 	})
 
@@ -52,24 +96,34 @@ func TestPvzService_CreatePvz(t *testing.T) {
 			RegistrationDate: time.Now(),
 			City:             "Москва",
 		}
-		mockPvzRepo.On("CreatePvz", "Москва").Return(expectedPvz, nil)
+		mockPvzRepo.On("CreatePvz", moderator, "Москва").Return(expectedPvz, nil)
 
-		pvz, err := service.CreatePvz("Москва")
+		pvz, err := service.CreatePvz(moderator, "Москва")
 		assert.NoError(t, err)
 		assert.Equal(t, expectedPvz, pvz)
 		mockPvzRepo.AssertExpectations(t)
+		mockCityRepo.AssertExpectations(t)
+	})
+
+	t.Run("Repository error keeps its kind", func(t *testing.T) {
+		actor := models.Actor{UserID: uuid.New(), Role: models.RoleModerator}
+		mockPvzRepo.On("CreatePvz", actor, "Москва").Return(models.PVZ{}, repository.ErrCityNotSupported).Once()
+
+		_, err := service.CreatePvz(actor, "Москва")
+		assert.ErrorIs(t, err, repository.ErrCityNotSupported)
+		assert.ErrorIs(t, err, apperr.ErrValidation)
 	})
 }
 
 func TestPvzService_GetFilteredPVZ_Bad(t *testing.T) {
 	mockPvzRepo := new(MockPvzRepository)
 	mockReceptionRepo := new(MockReceptionRepository)
-	service := service.NewPvzService(mockPvzRepo, mockReceptionRepo)
+	service := service.NewPvzService(mockPvzRepo, mockReceptionRepo, nil)
 
 	t.Run("Error fetching PVZ list", func(t *testing.T) {
-		mockPvzRepo.On("GetPVZList", 10, 0).Return([]models.PVZ{}, errors.New("database error"))
+		mockPvzRepo.On("GetPVZList", models.PVZStatusActive, 10, 0).Return([]models.PVZ{}, errors.New("database error"))
 
-		_, err := service.GetFilteredPVZ(nil, nil, 10, 0)
+		_, err := service.GetFilteredPVZ(nil, nil, false, models.PVZStatusActive, 10, 0)
 		assert.EqualError(t, err, "database error")
 		mockPvzRepo.AssertExpectations(t)
 	})
@@ -79,7 +133,7 @@ func TestPvzService_GetFilteredPVZ_Bad(t *testing.T) {
 func TestPvzService_GetFilteredPVZ_Good(t *testing.T) {
 	mockPvzRepo := new(MockPvzRepository)
 	mockReceptionRepo := new(MockReceptionRepository)
-	service := service.NewPvzService(mockPvzRepo, mockReceptionRepo)
+	service := service.NewPvzService(mockPvzRepo, mockReceptionRepo, nil)
 
 	t.Run("Successful fetch with receptions and items", func(t *testing.T) {
 		pvzID := uuid.New()
@@ -94,11 +148,11 @@ func TestPvzService_GetFilteredPVZ_Good(t *testing.T) {
 			{ID: uuid.New(), ReceptionID: receptionID, Type: models.ItemTypeElectronics, AddedAt: time.Now()},
 		}
 
-		mockPvzRepo.On("GetPVZList", 10, 0).Return(expectedPVZ, nil).Once()
-		mockReceptionRepo.On("GetReceptionsWithProducts", pvzID, (*time.Time)(nil), (*time.Time)(nil)).Return(expectedReceptions, nil).Once()
-		mockReceptionRepo.On("GetItemsByReceptionID", receptionID).Return(expectedItems, nil).Once()
+		mockPvzRepo.On("GetPVZList", models.PVZStatusActive, 10, 0).Return(expectedPVZ, nil).Once()
+		mockReceptionRepo.On("GetReceptionBlocksByPVZIDs", []uuid.UUID{pvzID}, (*time.Time)(nil), (*time.Time)(nil)).
+			Return([]models.ReceptionBlock{{Reception: expectedReceptions[0], Products: expectedItems}}, nil).Once()
 
-		result, err := service.GetFilteredPVZ(nil, nil, 10, 0)
+		result, err := service.GetFilteredPVZ(nil, nil, false, models.PVZStatusActive, 10, 0)
 
 		assert.NoError(t, err, "Expected no error, but got one")
 		assert.Len(t, result, 1, "Expected 1 PVZ in the result")
@@ -111,3 +165,214 @@ func TestPvzService_GetFilteredPVZ_Good(t *testing.T) {
 		mockReceptionRepo.AssertExpectations(t)
 	})
 }
+
+func TestPvzService_GetFilteredPVZ_DateWindow(t *testing.T) {
+	start := time.Now().Add(-24 * time.Hour)
+	end := time.Now()
+
+	t.Run("Only PVZs with receptions in window", func(t *testing.T) {
+		mockPvzRepo := new(MockPvzRepository)
+		mockReceptionRepo := new(MockReceptionRepository)
+		svc := service.NewPvzService(mockPvzRepo, mockReceptionRepo, nil)
+
+		pvz := models.PVZ{ID: uuid.New(), RegistrationDate: time.Now(), City: "Москва"}
+		reception := models.Reception{ID: uuid.New(), PVZID: pvz.ID, Status: "closed", CreatedAt: time.Now()}
+		mockPvzRepo.On("GetPVZListWithReceptions", &start, &end, models.PVZStatusActive, 10, 0).Return([]models.PVZ{pvz}, nil).Once()
+		mockReceptionRepo.On("GetReceptionBlocksByPVZIDs", []uuid.UUID{pvz.ID}, &start, &end).
+			Return([]models.ReceptionBlock{{Reception: reception}}, nil).Once()
+
+		result, err := svc.GetFilteredPVZ(&start, &end, false, models.PVZStatusActive, 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Len(t, result[0].Receptions, 1)
+		mockPvzRepo.AssertExpectations(t)
+		mockReceptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("All PVZs when includeEmpty is set", func(t *testing.T) {
+		mockPvzRepo := new(MockPvzRepository)
+		mockReceptionRepo := new(MockReceptionRepository)
+		svc := service.NewPvzService(mockPvzRepo, mockReceptionRepo, nil)
+
+		pvz := models.PVZ{ID: uuid.New(), RegistrationDate: time.Now(), City: "Казань"}
+		mockPvzRepo.On("GetPVZList", models.PVZStatusActive, 10, 0).Return([]models.PVZ{pvz}, nil).Once()
+		mockReceptionRepo.On("GetReceptionBlocksByPVZIDs", []uuid.UUID{pvz.ID}, &start, &end).
+			Return([]models.ReceptionBlock{}, nil).Once()
+
+		result, err := svc.GetFilteredPVZ(&start, &end, true, models.PVZStatusActive, 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Empty(t, result[0].Receptions)
+		mockPvzRepo.AssertExpectations(t)
+		mockReceptionRepo.AssertExpectations(t)
+	})
+}
+
+func TestPvzService_GetFilteredPVZ_QueryCount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	svc := service.NewPvzService(repository.NewPvzPostgres(sqlxDB), repository.NewReceptionPostgres(sqlxDB), nil)
+
+	now := time.Now()
+	pvzRows := sqlmock.NewRows([]string{"id", "registration_date", "city"})
+	receptionRows := sqlmock.NewRows([]string{"id", "pvz_id", "created_at", "status"})
+	itemRows := sqlmock.NewRows([]string{"id", "reception_id", "type", "added_at"})
+	for i := 0; i < 30; i++ {
+		pvzID := uuid.New()
+		pvzRows.AddRow(pvzID, now, "Москва")
+		for j := 0; j < 5; j++ {
+			receptionID := uuid.New()
+			receptionRows.AddRow(receptionID, pvzID, now, "closed")
+			for k := 0; k < 3; k++ {
+				itemRows.AddRow(uuid.New(), receptionID, "electronics", now)
+			}
+		}
+	}
+
+	mock.ExpectQuery(`SELECT p.id, p.registration_date, p.city, p.deactivated_at FROM pvz p WHERE p.deactivated_at IS NULL ORDER BY p.registration_date DESC LIMIT 30 OFFSET 0`).WillReturnRows(pvzRows)
+	mock.ExpectQuery(`SELECT id, pvz_id, created_at, status FROM receptions WHERE pvz_id = ANY\(\$1\)`).WillReturnRows(receptionRows)
+	mock.ExpectQuery(`SELECT id, reception_id, type, added_at, barcode, sku, description FROM goods WHERE reception_id = ANY\(\$1\)`).WillReturnRows(itemRows)
+
+	result, err := svc.GetFilteredPVZ(nil, nil, false, models.PVZStatusActive, 30, 0)
+	assert.NoError(t, err)
+	assert.Len(t, result, 30)
+	for _, pvz := range result {
+		assert.Len(t, pvz.Receptions, 5)
+		for _, block := range pvz.Receptions {
+			assert.Len(t, block.Products, 3)
+		}
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPvzService_GetPVZByID(t *testing.T) {
+	mockPvzRepo := new(MockPvzRepository)
+	mockReceptionRepo := new(MockReceptionRepository)
+	svc := service.NewPvzService(mockPvzRepo, mockReceptionRepo, nil)
+
+	t.Run("PVZ found", func(t *testing.T) {
+		expectedPvz := models.PVZ{ID: uuid.New(), RegistrationDate: time.Now(), City: "Казань"}
+		mockPvzRepo.On("GetPVZByID", expectedPvz.ID).Return(expectedPvz, nil).Once()
+
+		pvz, err := svc.GetPVZByID(expectedPvz.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, expectedPvz, pvz)
+		mockPvzRepo.AssertExpectations(t)
+	})
+
+	t.Run("PVZ not found", func(t *testing.T) {
+		pvzID := uuid.New()
+		mockPvzRepo.On("GetPVZByID", pvzID).Return(models.PVZ{}, nil).Once()
+
+		_, err := svc.GetPVZByID(pvzID)
+		assert.ErrorIs(t, err, service.ErrPvzNotFound)
+		mockPvzRepo.AssertExpectations(t)
+	})
+}
+
+func TestPvzService_UpdatePVZ(t *testing.T) {
+	mockPvzRepo := new(MockPvzRepository)
+	mockReceptionRepo := new(MockReceptionRepository)
+	mockCityRepo := new(MockCityRepository)
+	mockCityRepo.On("GetCities").Return([]models.City{{Name: "Москва"}, {Name: "Казань"}}, nil)
+	svc := service.NewPvzService(mockPvzRepo, mockReceptionRepo, service.NewCityCatalog(mockCityRepo, time.Minute))
+
+	t.Run("Updated", func(t *testing.T) {
+		pvz := models.PVZ{ID: uuid.New(), RegistrationDate: time.Now(), City: "Москва"}
+		updated := pvz
+		updated.City = "Казань"
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+		mockPvzRepo.On("UpdatePVZ", moderator, pvz.ID, "Казань").Return(updated, nil).Once()
+
+		result, err := svc.UpdatePVZ(moderator, pvz.ID, "Казань")
+		assert.NoError(t, err)
+		assert.Equal(t, updated, result)
+		mockPvzRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unsupported city", func(t *testing.T) {
+		pvz := models.PVZ{ID: uuid.New(), RegistrationDate: time.Now(), City: "Москва"}
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+
+		_, err := svc.UpdatePVZ(moderator, pvz.ID, "Тверь")
+		assert.ErrorIs(t, err, service.ErrCityNotSupported)
+		mockPvzRepo.AssertNotCalled(t, "UpdatePVZ", moderator, pvz.ID, "Тверь")
+	})
+
+	t.Run("Deactivated", func(t *testing.T) {
+		deactivatedAt := time.Now()
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва", DeactivatedAt: &deactivatedAt}
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+
+		_, err := svc.UpdatePVZ(moderator, pvz.ID, "Казань")
+		assert.ErrorIs(t, err, service.ErrPvzDeactivated)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		pvzID := uuid.New()
+		mockPvzRepo.On("GetPVZByID", pvzID).Return(models.PVZ{}, nil).Once()
+
+		_, err := svc.UpdatePVZ(moderator, pvzID, "Казань")
+		assert.ErrorIs(t, err, service.ErrPvzNotFound)
+	})
+}
+
+func TestPvzService_DeactivatePVZ(t *testing.T) {
+	mockPvzRepo := new(MockPvzRepository)
+	mockReceptionRepo := new(MockReceptionRepository)
+	svc := service.NewPvzService(mockPvzRepo, mockReceptionRepo, nil)
+
+	t.Run("Deactivated", func(t *testing.T) {
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва"}
+		deactivatedAt := time.Now()
+		deactivated := pvz
+		deactivated.DeactivatedAt = &deactivatedAt
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+		mockPvzRepo.On("DeactivatePVZ", moderator, pvz.ID).Return(deactivated, nil).Once()
+
+		result, err := svc.DeactivatePVZ(moderator, pvz.ID)
+		assert.NoError(t, err)
+		assert.False(t, result.Active())
+		mockPvzRepo.AssertExpectations(t)
+	})
+
+	t.Run("Active reception", func(t *testing.T) {
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва"}
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+		mockPvzRepo.On("DeactivatePVZ", moderator, pvz.ID).
+			Return(models.PVZ{}, fmt.Errorf("%w: %s", repository.ErrPvzHasActiveReception, pvz.ID)).Once()
+
+		_, err := svc.DeactivatePVZ(moderator, pvz.ID)
+		assert.ErrorIs(t, err, service.ErrPvzHasActiveReception)
+	})
+
+	t.Run("Already deactivated", func(t *testing.T) {
+		deactivatedAt := time.Now()
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва", DeactivatedAt: &deactivatedAt}
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+
+		result, err := svc.DeactivatePVZ(moderator, pvz.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, pvz, result)
+		mockPvzRepo.AssertNotCalled(t, "DeactivatePVZ", moderator, pvz.ID)
+	})
+}
+
+func TestPvzService_CreatePvz_Metrics(t *testing.T) {
+	mockPvzRepo := new(MockPvzRepository)
+	mockReceptionRepo := new(MockReceptionRepository)
+	mockCityRepo := new(MockCityRepository)
+	mockCityRepo.On("GetCities").Return([]models.City{{Name: "Казань"}}, nil)
+	svc := service.NewPvzService(mockPvzRepo, mockReceptionRepo, service.NewCityCatalog(mockCityRepo, time.Minute))
+
+	expectedPvz := models.PVZ{ID: uuid.New(), RegistrationDate: time.Now(), City: "Казань"}
+	mockPvzRepo.On("CreatePvz", moderator, "Казань").Return(expectedPvz, nil)
+
+	before := testutil.ToFloat64(metrics.PVZCreatedTotal.WithLabelValues("Казань"))
+	_, err := svc.CreatePvz(moderator, "Казань")
+	assert.NoError(t, err)
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.PVZCreatedTotal.WithLabelValues("Казань")))
+}
@@ -1,72 +1,394 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/metrics"
 	"pvz-test/internal/models"
 	"pvz-test/internal/repository"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrReceptionNotFound          = apperr.New(apperr.ErrNotFound, "reception not found")
+	ErrProductNotFound            = apperr.New(apperr.ErrNotFound, "product not found")
+	ErrInvalidReceptionTransition = apperr.New(apperr.ErrConflict, "invalid reception status transition")
+)
+
+// Limits of product details, the same as in the single product request.
+const (
+	maxSKULength         = 64
+	maxDescriptionLength = 1000
+)
+
+// receptionTransitions lists the statuses a reception may move to. A closed
+// reception is final.
+var receptionTransitions = map[models.ReceptionStatus][]models.ReceptionStatus{
+	models.ReceptionStatusInProgress: {models.ReceptionStatusClosed},
+}
+
+func checkReceptionTransition(from, to models.ReceptionStatus) error {
+	for _, next := range receptionTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrInvalidReceptionTransition, from, to)
+}
+
 type ReceptionService struct {
-	receptionRepo repository.ReceptionRepository
-	pvzRepo       repository.PvzRepository
+	receptionRepo  repository.ReceptionRepository
+	pvzRepo        repository.PvzRepository
+	assignmentRepo repository.AssignmentRepository
 }
 
-func NewReceptionService(receptionRepo repository.ReceptionRepository, pvzRepo repository.PvzRepository) *ReceptionService {
+func NewReceptionService(receptionRepo repository.ReceptionRepository, pvzRepo repository.PvzRepository, assignmentRepo repository.AssignmentRepository) *ReceptionService {
 	return &ReceptionService{
-		receptionRepo: receptionRepo,
-		pvzRepo:       pvzRepo}
+		receptionRepo:  receptionRepo,
+		pvzRepo:        pvzRepo,
+		assignmentRepo: assignmentRepo}
 }
 
-func (s *ReceptionService) CreateReception(pvzID uuid.UUID) (models.Reception, error) {
-	exists, err := s.pvzRepo.Exists(pvzID)
+func (s *ReceptionService) CreateReception(actor models.Actor, pvzID uuid.UUID) (models.Reception, error) {
+	if err := s.checkAssigned(actor.UserID, pvzID); err != nil {
+		return models.Reception{}, err
+	}
+
+	pvz, err := s.pvzRepo.GetPVZByID(pvzID)
 	if err != nil {
-		return models.Reception{}, fmt.Errorf("failed to check PVZ existence: %s", err.Error())
+		return models.Reception{}, fmt.Errorf("failed to check PVZ existence: %w", err)
 	}
-	if !exists {
-		return models.Reception{}, fmt.Errorf("PVZ: %s does not exist", pvzID.String())
+	if (pvz == models.PVZ{}) {
+		return models.Reception{}, fmt.Errorf("%w: %s", ErrPvzNotFound, pvzID.String())
+	}
+	if !pvz.Active() {
+		return models.Reception{}, fmt.Errorf("%w: %s", ErrPvzDeactivated, pvzID.String())
 	}
 
 	activeReception, err := s.receptionRepo.GetActiveReception(pvzID)
 	if err != nil {
-		return models.Reception{}, fmt.Errorf("reception get error: %s", pvzID.String())
+		return models.Reception{}, fmt.Errorf("reception get error for pvz %s: %w", pvzID.String(), err)
 	}
 	if (activeReception != models.Reception{}) {
-		return models.Reception{}, fmt.Errorf("an active reception already exists for PVZ: %s", pvzID.String())
+		return models.Reception{}, fmt.Errorf("%w: %s", repository.ErrReceptionInProgress, pvzID.String())
 	}
 
-	reception, err := s.receptionRepo.CreateReception(pvzID)
+	reception, err := s.receptionRepo.CreateReception(actor, pvzID)
 	if err != nil {
-		return models.Reception{}, fmt.Errorf("failed to create reception: %s", err.Error())
+		return models.Reception{}, fmt.Errorf("failed to create reception: %w", err)
 	}
+	metrics.ReceptionsCreatedTotal.WithLabelValues(pvz.City).Inc()
 
 	return reception, nil
 }
 
-func (s *ReceptionService) CloseActiveReception(pvzID uuid.UUID) (models.Reception, error) {
+func (s *ReceptionService) CloseActiveReception(actor models.Actor, pvzID uuid.UUID) (models.Reception, error) {
+	if err := s.checkAssigned(actor.UserID, pvzID); err != nil {
+		return models.Reception{}, err
+	}
+
+	pvz, err := s.pvzRepo.GetPVZByID(pvzID)
+	if err != nil {
+		return models.Reception{}, err
+	}
+	if (pvz == models.PVZ{}) {
+		return models.Reception{}, fmt.Errorf("%w: %s", ErrPvzNotFound, pvzID.String())
+	}
+
 	reception, err := s.receptionRepo.GetActiveReception(pvzID)
 	if err != nil {
 		return models.Reception{}, err
 	}
 	if (reception == models.Reception{}) {
-		return models.Reception{}, nil
+		return models.Reception{}, fmt.Errorf("%w for pvz %s", repository.ErrNoActiveReception, pvzID.String())
 	}
 
-	err = s.receptionRepo.CloseReception(reception.ID)
+	if err := checkReceptionTransition(reception.Status, models.ReceptionStatusClosed); err != nil {
+		return models.Reception{}, err
+	}
+
+	err = s.receptionRepo.CloseReception(actor, reception.ID)
 	if err != nil {
 		return models.Reception{}, err
 	}
-	reception.Status = "close"
+	reception.Status = models.ReceptionStatusClosed
 	return reception, nil
 }
 
-func (s *ReceptionService) AddItem(pvzID uuid.UUID, itemType string) (models.Item, error) {
-	item, err := s.receptionRepo.AddItem(pvzID, itemType)
-	return item, err
+func (s *ReceptionService) AddItem(actor models.Actor, pvzID uuid.UUID, itemType string, details models.ItemDetails) (models.Item, error) {
+	if err := validateItem(itemType, details); err != nil {
+		return models.Item{}, err
+	}
+	if err := s.checkAssigned(actor.UserID, pvzID); err != nil {
+		return models.Item{}, err
+	}
+	pvz, err := s.pvzRepo.GetPVZByID(pvzID)
+	if err != nil {
+		return models.Item{}, err
+	}
+
+	item, err := s.receptionRepo.AddItem(actor, pvzID, itemType, details)
+	if err != nil {
+		return item, err
+	}
+	metrics.ProductsAddedTotal.WithLabelValues(pvz.City, string(item.Type)).Inc()
+	return item, nil
 }
 
-func (s *ReceptionService) DeleteItem(pvzID uuid.UUID) error {
-	err := s.receptionRepo.DeleteItem(pvzID)
+// AddItems adds a batch of products to an open reception. Products that fail
+// validation, repeat a barcode of the batch or carry a barcode that is already
+// in an open reception are reported in the result and the rest are added
+// together.
+func (s *ReceptionService) AddItems(actor models.Actor, receptionID uuid.UUID, newItems []models.NewItem) (models.BatchAddResult, error) {
+	reception, err := s.receptionRepo.GetReceptionByID(receptionID)
+	if err != nil {
+		return models.BatchAddResult{}, err
+	}
+	if (reception == models.Reception{}) {
+		return models.BatchAddResult{}, fmt.Errorf("%w: %s", ErrReceptionNotFound, receptionID.String())
+	}
+	if err := s.checkAssigned(actor.UserID, reception.PVZID); err != nil {
+		return models.BatchAddResult{}, err
+	}
+	if reception.Status != models.ReceptionStatusInProgress {
+		return models.BatchAddResult{}, fmt.Errorf("%w: reception %s is closed", repository.ErrNoActiveReception, receptionID.String())
+	}
+
+	result := models.BatchAddResult{Results: make([]models.BatchItemResult, len(newItems))}
+	valid := make([]models.NewItem, 0, len(newItems))
+	indexes := make([]int, 0, len(newItems))
+	barcodes := make(map[string]int)
+	for i, newItem := range newItems {
+		result.Results[i].Index = i
+		err := validateItem(string(newItem.Type), newItem.ItemDetails)
+		if err == nil && newItem.Barcode != nil {
+			if first, ok := barcodes[*newItem.Barcode]; ok {
+				err = &apperr.ValidationError{
+					Field:  "barcode",
+					Value:  *newItem.Barcode,
+					Reason: fmt.Sprintf("repeats product %d of the batch", first),
+				}
+			} else {
+				barcodes[*newItem.Barcode] = i
+			}
+		}
+		if err != nil {
+			result.Results[i].Error = err.Error()
+			result.Failed++
+			continue
+		}
+		valid = append(valid, newItem)
+		indexes = append(indexes, i)
+	}
+	if len(valid) == 0 {
+		return result, nil
+	}
+	pvz, err := s.pvzRepo.GetPVZByID(reception.PVZID)
+	if err != nil {
+		return models.BatchAddResult{}, err
+	}
+
+	items, received, err := s.receptionRepo.AddItems(actor, receptionID, valid)
+	if err != nil {
+		return models.BatchAddResult{}, err
+	}
+	rejected := make(map[string]struct{}, len(received))
+	for _, barcode := range received {
+		rejected[barcode] = struct{}{}
+	}
+	// Items come back in the order of valid with the rejected ones left out.
+	next := 0
+	for i, newItem := range valid {
+		if newItem.Barcode != nil {
+			if _, ok := rejected[*newItem.Barcode]; ok {
+				result.Results[indexes[i]].Error = fmt.Errorf("%w: %s", repository.ErrBarcodeReceived, *newItem.Barcode).Error()
+				result.Failed++
+				continue
+			}
+		}
+		if next == len(items) {
+			break
+		}
+		item := items[next]
+		next++
+		result.Results[indexes[i]].Product = &item
+		metrics.ProductsAddedTotal.WithLabelValues(pvz.City, string(item.Type)).Inc()
+	}
+	result.Added = next
+	return result, nil
+}
+
+func (s *ReceptionService) DeleteItem(actor models.Actor, pvzID uuid.UUID) error {
+	if err := s.checkAssigned(actor.UserID, pvzID); err != nil {
+		return err
+	}
+	err := s.receptionRepo.DeleteItem(actor, pvzID)
 	return err
 }
+
+// DeleteItemByID removes a product from a reception that is still in
+// progress, wherever it is in the scan order.
+func (s *ReceptionService) DeleteItemByID(actor models.Actor, receptionID, itemID uuid.UUID) error {
+	reception, err := s.receptionRepo.GetReceptionByID(receptionID)
+	if err != nil {
+		return err
+	}
+	if (reception == models.Reception{}) {
+		return fmt.Errorf("%w: %s", ErrReceptionNotFound, receptionID.String())
+	}
+	if err := s.checkAssigned(actor.UserID, reception.PVZID); err != nil {
+		return err
+	}
+	if reception.Status != models.ReceptionStatusInProgress {
+		return fmt.Errorf("%w: reception %s is closed", repository.ErrNoActiveReception, receptionID.String())
+	}
+
+	item, err := s.receptionRepo.DeleteItemByID(actor, receptionID, itemID)
+	if err != nil {
+		return err
+	}
+	if (item == models.Item{}) {
+		return fmt.Errorf("%w: %s", ErrProductNotFound, itemID.String())
+	}
+	return nil
+}
+
+// FindProduct returns the reception and PVZ where the parcel with barcode was
+// received last. Parcels received in a deactivated PVZ are only found when
+// includeDeactivated is set.
+func (s *ReceptionService) FindProduct(barcode string, includeDeactivated bool) (models.ProductLocation, error) {
+	if err := models.ValidateBarcode(barcode); err != nil {
+		return models.ProductLocation{}, &apperr.ValidationError{Field: "barcode", Value: barcode, Reason: err.Error()}
+	}
+
+	item, err := s.receptionRepo.GetLatestItemByBarcode(barcode, includeDeactivated)
+	if err != nil {
+		return models.ProductLocation{}, err
+	}
+	if (item == models.Item{}) {
+		return models.ProductLocation{}, fmt.Errorf("%w: barcode %s", ErrProductNotFound, barcode)
+	}
+
+	reception, err := s.receptionRepo.GetReceptionByID(item.ReceptionID)
+	if err != nil {
+		return models.ProductLocation{}, err
+	}
+	pvz, err := s.pvzRepo.GetPVZByID(reception.PVZID)
+	if err != nil {
+		return models.ProductLocation{}, err
+	}
+	return models.ProductLocation{Product: item, Reception: reception, PVZ: pvz}, nil
+}
+
+// GetReceptions returns the reception history of a PVZ. The history of a
+// deactivated PVZ is only returned when includeDeactivated is set.
+func (s *ReceptionService) GetReceptions(pvzID uuid.UUID, includeDeactivated bool, filter models.ReceptionFilter) ([]models.ReceptionBlock, error) {
+	if err := s.checkPVZVisible(pvzID, includeDeactivated); err != nil {
+		return nil, err
+	}
+
+	receptions, err := s.receptionRepo.GetReceptionsWithProducts(pvzID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receptions: %w", err)
+	}
+
+	if len(receptions) == 0 {
+		return []models.ReceptionBlock{}, nil
+	}
+
+	receptionIDs := make([]uuid.UUID, 0, len(receptions))
+	for _, reception := range receptions {
+		receptionIDs = append(receptionIDs, reception.ID)
+	}
+	items, err := s.receptionRepo.GetItemsByReceptionIDs(receptionIDs)
+	if err != nil {
+		return nil, err
+	}
+	itemsByReception := make(map[uuid.UUID][]models.Item, len(receptions))
+	for _, item := range items {
+		itemsByReception[item.ReceptionID] = append(itemsByReception[item.ReceptionID], item)
+	}
+
+	blocks := make([]models.ReceptionBlock, 0, len(receptions))
+	for _, reception := range receptions {
+		blocks = append(blocks, models.ReceptionBlock{Reception: reception, Products: itemsByReception[reception.ID]})
+	}
+
+	return blocks, nil
+}
+
+func (s *ReceptionService) GetReception(receptionID uuid.UUID, includeDeactivated bool) (models.ReceptionBlock, error) {
+	reception, err := s.receptionRepo.GetReceptionByID(receptionID)
+	if err != nil {
+		return models.ReceptionBlock{}, err
+	}
+	if (reception == models.Reception{}) {
+		return models.ReceptionBlock{}, fmt.Errorf("%w: %s", ErrReceptionNotFound, receptionID.String())
+	}
+
+	if err := s.checkPVZVisible(reception.PVZID, includeDeactivated); err != nil {
+		if errors.Is(err, ErrPvzNotFound) {
+			return models.ReceptionBlock{}, fmt.Errorf("%w: %s", ErrReceptionNotFound, receptionID.String())
+		}
+		return models.ReceptionBlock{}, err
+	}
+
+	items, err := s.receptionRepo.GetItemsByReceptionID(receptionID)
+	if err != nil {
+		return models.ReceptionBlock{}, fmt.Errorf("failed to get goods: %w", err)
+	}
+
+	return models.ReceptionBlock{Reception: reception, Products: items}, nil
+}
+
+func (s *ReceptionService) checkPVZVisible(pvzID uuid.UUID, includeDeactivated bool) error {
+	pvz, err := s.pvzRepo.GetPVZByID(pvzID)
+	if err != nil {
+		return err
+	}
+	if (pvz == models.PVZ{}) || (!pvz.Active() && !includeDeactivated) {
+		return fmt.Errorf("%w: %s", ErrPvzNotFound, pvzID.String())
+	}
+	return nil
+}
+
+func validateItem(itemType string, details models.ItemDetails) error {
+	if !models.ItemType(itemType).Valid() {
+		return &apperr.ValidationError{
+			Field:  "type",
+			Value:  itemType,
+			Reason: "must be one of electronics, clothing, shoes",
+		}
+	}
+	if details.Barcode != nil {
+		if err := models.ValidateBarcode(*details.Barcode); err != nil {
+			return &apperr.ValidationError{Field: "barcode", Value: *details.Barcode, Reason: err.Error()}
+		}
+	}
+	if details.SKU != nil && utf8.RuneCountInString(*details.SKU) > maxSKULength {
+		return &apperr.ValidationError{Field: "sku", Value: *details.SKU, Reason: fmt.Sprintf("must be at most %d characters", maxSKULength)}
+	}
+	if details.Description != nil && utf8.RuneCountInString(*details.Description) > maxDescriptionLength {
+		return &apperr.ValidationError{
+			Field:  "description",
+			Value:  *details.Description,
+			Reason: fmt.Sprintf("must be at most %d characters", maxDescriptionLength),
+		}
+	}
+	return nil
+}
+
+// checkAssigned rejects changes to a PVZ the caller is not assigned to.
+func (s *ReceptionService) checkAssigned(userID, pvzID uuid.UUID) error {
+	assigned, err := s.assignmentRepo.IsAssigned(userID, pvzID)
+	if err != nil {
+		return err
+	}
+	if !assigned {
+		return fmt.Errorf("%w: %s", ErrPvzNotAssigned, pvzID.String())
+	}
+	return nil
+}
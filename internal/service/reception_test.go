@@ -2,12 +2,19 @@ package service_test
 
 import (
 	"errors"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/metrics"
 	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
 	"pvz-test/internal/service"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -16,8 +23,8 @@ type MockReceptionRepository struct {
 	mock.Mock
 }
 
-func (m *MockReceptionRepository) CreateReception(pvzID uuid.UUID) (models.Reception, error) {
-	args := m.Called(pvzID)
+func (m *MockReceptionRepository) CreateReception(actor models.Actor, pvzID uuid.UUID) (models.Reception, error) {
+	args := m.Called(actor, pvzID)
 	return args.Get(0).(models.Reception), args.Error(1)
 }
 
@@ -26,21 +33,56 @@ func (m *MockReceptionRepository) GetActiveReception(pvzID uuid.UUID) (models.Re
 	return args.Get(0).(models.Reception), args.Error(1)
 }
 
-func (m *MockReceptionRepository) CloseReception(receptionID uuid.UUID) error {
-	args := m.Called(receptionID)
+func (m *MockReceptionRepository) CloseReception(actor models.Actor, receptionID uuid.UUID) error {
+	args := m.Called(actor, receptionID)
 	return args.Error(0)
 }
 
-func (m *MockReceptionRepository) AddItem(pvzID uuid.UUID, itemType string) (models.Item, error) {
-	args := m.Called(pvzID, itemType)
+func (m *MockReceptionRepository) AddItem(actor models.Actor, pvzID uuid.UUID, itemType string, details models.ItemDetails) (models.Item, error) {
+	args := m.Called(actor, pvzID, itemType, details)
 	return args.Get(0).(models.Item), args.Error(1)
 }
 
-func (m *MockReceptionRepository) DeleteItem(pvzID uuid.UUID) error {
-	args := m.Called(pvzID)
+func (m *MockReceptionRepository) AddItems(actor models.Actor, receptionID uuid.UUID, newItems []models.NewItem) ([]models.Item, []string, error) {
+	args := m.Called(actor, receptionID, newItems)
+	received, _ := args.Get(1).([]string)
+	return args.Get(0).([]models.Item), received, args.Error(2)
+}
+
+func (m *MockReceptionRepository) GetItemsByReceptionIDs(receptionIDs []uuid.UUID) ([]models.Item, error) {
+	args := m.Called(receptionIDs)
+	return args.Get(0).([]models.Item), args.Error(1)
+}
+
+func (m *MockReceptionRepository) GetLatestItemByBarcode(barcode string, includeDeactivated bool) (models.Item, error) {
+	args := m.Called(barcode, includeDeactivated)
+	return args.Get(0).(models.Item), args.Error(1)
+}
+
+func (m *MockReceptionRepository) DeleteItemByID(actor models.Actor, receptionID, itemID uuid.UUID) (models.Item, error) {
+	args := m.Called(actor, receptionID, itemID)
+	return args.Get(0).(models.Item), args.Error(1)
+}
+
+func (m *MockReceptionRepository) DeleteItem(actor models.Actor, pvzID uuid.UUID) error {
+	args := m.Called(actor, pvzID)
 	return args.Error(0)
 }
 
+var (
+	employeeID = uuid.New()
+	employee   = models.Actor{UserID: employeeID, Role: models.RoleEmployee}
+	moderator  = models.Actor{UserID: uuid.New(), Role: models.RoleModerator}
+)
+
+// newAssignedRepo returns an assignment repository in which employeeID is
+// assigned to every PVZ.
+func newAssignedRepo() *MockAssignmentRepository {
+	repo := new(MockAssignmentRepository)
+	repo.On("IsAssigned", employeeID, mock.Anything).Return(true, nil)
+	return repo
+}
+
 func (m *MockPvzRepository) Exists(pvzID uuid.UUID) (bool, error) {
 	args := m.Called(pvzID)
 	return args.Bool(0), args.Error(1)
@@ -49,67 +91,141 @@ func (m *MockPvzRepository) Exists(pvzID uuid.UUID) (bool, error) {
 func TestReceptionService_CreateReception(t *testing.T) {
 	mockReceptionRepo := new(MockReceptionRepository)
 	mockPvzRepo := new(MockPvzRepository)
-	service := service.NewReceptionService(mockReceptionRepo, mockPvzRepo)
+	svc := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, newAssignedRepo())
 
 	t.Run("Non-existent PVZ", func(t *testing.T) {
 		pvzID := uuid.New()
-		mockPvzRepo.On("Exists", pvzID).Return(false, nil)
+		mockPvzRepo.On("GetPVZByID", pvzID).Return(models.PVZ{}, nil)
 
-		_, err := service.CreateReception(pvzID)
-		assert.EqualError(t, err, "PVZ: "+pvzID.String()+" does not exist")
+		_, err := svc.CreateReception(employee, pvzID)
+		assert.ErrorIs(t, err, service.ErrPvzNotFound)
+		assert.ErrorIs(t, err, apperr.ErrNotFound)
 		mockPvzRepo.AssertExpectations(t)
 	})
+
+	t.Run("Deactivated PVZ", func(t *testing.T) {
+		deactivatedAt := time.Now()
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва", DeactivatedAt: &deactivatedAt}
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil)
+
+		_, err := svc.CreateReception(employee, pvz.ID)
+		assert.ErrorIs(t, err, service.ErrPvzDeactivated)
+		mockReceptionRepo.AssertNotCalled(t, "CreateReception", employee, pvz.ID)
+	})
+
+	t.Run("Error fetching active reception", func(t *testing.T) {
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва"}
+		dbErr := errors.New("database error")
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+		mockReceptionRepo.On("GetActiveReception", pvz.ID).Return(models.Reception{}, dbErr).Once()
+
+		_, err := svc.CreateReception(employee, pvz.ID)
+		assert.ErrorIs(t, err, dbErr)
+		mockReceptionRepo.AssertNotCalled(t, "CreateReception", employee, pvz.ID)
+	})
+
+	t.Run("Successful creation", func(t *testing.T) {
+		pvz := models.PVZ{ID: uuid.New(), City: "Казань"}
+		expected := models.Reception{ID: uuid.New(), PVZID: pvz.ID, Status: models.ReceptionStatusInProgress}
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+		mockReceptionRepo.On("GetActiveReception", pvz.ID).Return(models.Reception{}, nil).Once()
+		mockReceptionRepo.On("CreateReception", employee, pvz.ID).Return(expected, nil).Once()
+
+		before := testutil.ToFloat64(metrics.ReceptionsCreatedTotal.WithLabelValues("Казань"))
+		reception, err := svc.CreateReception(employee, pvz.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, reception)
+		assert.Equal(t, before+1, testutil.ToFloat64(metrics.ReceptionsCreatedTotal.WithLabelValues("Казань")))
+	})
+
+	t.Run("PVZ not assigned", func(t *testing.T) {
+		pvzID := uuid.New()
+		strangerID := uuid.New()
+		assignmentRepo := new(MockAssignmentRepository)
+		assignmentRepo.On("IsAssigned", strangerID, pvzID).Return(false, nil)
+		svc := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, assignmentRepo)
+
+		_, err := svc.CreateReception(models.Actor{UserID: strangerID, Role: models.RoleEmployee}, pvzID)
+		assert.ErrorIs(t, err, service.ErrPvzNotAssigned)
+		assert.ErrorIs(t, err, apperr.ErrForbidden)
+		mockPvzRepo.AssertNotCalled(t, "GetPVZByID", pvzID)
+	})
 }
 
 func TestReceptionService_CloseActiveReception(t *testing.T) {
 	mockReceptionRepo := new(MockReceptionRepository)
 	mockPvzRepo := new(MockPvzRepository)
-	service := service.NewReceptionService(mockReceptionRepo, mockPvzRepo)
+	svc := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, newAssignedRepo())
 
 	t.Run("Error fetching active reception", func(t *testing.T) {
 		pvzID := uuid.New()
+		mockPvzRepo.On("GetPVZByID", pvzID).Return(models.PVZ{ID: pvzID, City: "Москва"}, nil)
 		mockReceptionRepo.On("GetActiveReception", pvzID).Return(models.Reception{}, errors.New("database error"))
 
-		_, err := service.CloseActiveReception(pvzID)
+		_, err := svc.CloseActiveReception(employee, pvzID)
 		assert.EqualError(t, err, "database error")
 		mockReceptionRepo.AssertExpectations(t)
 	})
 
 	t.Run("No active reception", func(t *testing.T) {
 		pvzID := uuid.New()
+		mockPvzRepo.On("GetPVZByID", pvzID).Return(models.PVZ{ID: pvzID, City: "Москва"}, nil)
 		mockReceptionRepo.On("GetActiveReception", pvzID).Return(models.Reception{}, nil)
 
-		reception, err := service.CloseActiveReception(pvzID)
-		assert.NoError(t, err)
-		assert.Equal(t, models.Reception{}, reception)
+		_, err := svc.CloseActiveReception(employee, pvzID)
+		assert.ErrorIs(t, err, repository.ErrNoActiveReception)
+		assert.ErrorIs(t, err, apperr.ErrValidation)
 		mockReceptionRepo.AssertExpectations(t)
 	})
 
+	t.Run("Missing PVZ", func(t *testing.T) {
+		pvzID := uuid.New()
+		mockPvzRepo.On("GetPVZByID", pvzID).Return(models.PVZ{}, nil)
+
+		_, err := svc.CloseActiveReception(employee, pvzID)
+		assert.ErrorIs(t, err, apperr.ErrNotFound)
+		mockReceptionRepo.AssertNotCalled(t, "GetActiveReception", pvzID)
+	})
+
 	t.Run("Successful closure", func(t *testing.T) {
 		pvzID := uuid.New()
 		receptionID := uuid.New()
+		mockPvzRepo.On("GetPVZByID", pvzID).Return(models.PVZ{ID: pvzID, City: "Москва"}, nil)
 		activeReception := models.Reception{ID: receptionID, PVZID: pvzID, Status: "in_progress"}
 		mockReceptionRepo.On("GetActiveReception", pvzID).Return(activeReception, nil)
-		mockReceptionRepo.On("CloseReception", receptionID).Return(nil)
+		mockReceptionRepo.On("CloseReception", employee, receptionID).Return(nil)
 
-		reception, err := service.CloseActiveReception(pvzID)
+		reception, err := svc.CloseActiveReception(employee, pvzID)
 		assert.NoError(t, err)
-		assert.Equal(t, "close", reception.Status)
+		assert.Equal(t, models.ReceptionStatusClosed, reception.Status)
 		mockReceptionRepo.AssertExpectations(t)
 	})
+
+	t.Run("Unknown status cannot be closed", func(t *testing.T) {
+		pvzID := uuid.New()
+		receptionID := uuid.New()
+		mockPvzRepo.On("GetPVZByID", pvzID).Return(models.PVZ{ID: pvzID, City: "Москва"}, nil)
+		reception := models.Reception{ID: receptionID, PVZID: pvzID, Status: "active"}
+		mockReceptionRepo.On("GetActiveReception", pvzID).Return(reception, nil)
+
+		_, err := svc.CloseActiveReception(employee, pvzID)
+		assert.ErrorIs(t, err, service.ErrInvalidReceptionTransition)
+		mockReceptionRepo.AssertNotCalled(t, "CloseReception", employee, receptionID)
+	})
 }
 
 func TestReceptionService_AddItem(t *testing.T) {
 	mockReceptionRepo := new(MockReceptionRepository)
 	mockPvzRepo := new(MockPvzRepository)
-	service := service.NewReceptionService(mockReceptionRepo, mockPvzRepo)
+	svc := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, newAssignedRepo())
 
 	t.Run("Error adding item", func(t *testing.T) {
 		pvzID := uuid.New()
 		itemType := "electronics"
-		mockReceptionRepo.On("AddItem", pvzID, itemType).Return(models.Item{}, errors.New("database error"))
+		mockPvzRepo.On("GetPVZByID", pvzID).Return(models.PVZ{ID: pvzID, City: "Москва"}, nil).Once()
+		mockReceptionRepo.On("AddItem", employee, pvzID, itemType, models.ItemDetails{}).Return(models.Item{}, errors.New("database error"))
 
-		_, err := service.AddItem(pvzID, itemType)
+		_, err := svc.AddItem(employee, pvzID, itemType, models.ItemDetails{})
 		assert.EqualError(t, err, "database error")
 		mockReceptionRepo.AssertExpectations(t)
 	})
@@ -118,35 +234,426 @@ func TestReceptionService_AddItem(t *testing.T) {
 		pvzID := uuid.New()
 		itemType := "electronics"
 		expectedItem := models.Item{ID: uuid.New(), ReceptionID: uuid.New(), Type: models.ItemTypeElectronics, AddedAt: time.Now()}
-		mockReceptionRepo.On("AddItem", pvzID, itemType).Return(expectedItem, nil)
+		mockPvzRepo.On("GetPVZByID", pvzID).Return(models.PVZ{ID: pvzID, City: "Казань"}, nil).Once()
+		mockReceptionRepo.On("AddItem", employee, pvzID, itemType, models.ItemDetails{}).Return(expectedItem, nil)
 
-		item, err := service.AddItem(pvzID, itemType)
+		before := testutil.ToFloat64(metrics.ProductsAddedTotal.WithLabelValues("Казань", "electronics"))
+		item, err := svc.AddItem(employee, pvzID, itemType, models.ItemDetails{})
 		assert.NoError(t, err)
 		assert.Equal(t, expectedItem, item)
+		assert.Equal(t, before+1, testutil.ToFloat64(metrics.ProductsAddedTotal.WithLabelValues("Казань", "electronics")))
 		mockReceptionRepo.AssertExpectations(t)
 	})
+
+	t.Run("Unknown item type", func(t *testing.T) {
+		pvzID := uuid.New()
+
+		_, err := svc.AddItem(employee, pvzID, "furniture", models.ItemDetails{})
+		var validationErr *apperr.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "type", validationErr.Field)
+		mockReceptionRepo.AssertNotCalled(t, "AddItem", employee, pvzID, "furniture", models.ItemDetails{})
+	})
+
+	t.Run("PVZ not assigned", func(t *testing.T) {
+		pvzID := uuid.New()
+		strangerID := uuid.New()
+		assignmentRepo := new(MockAssignmentRepository)
+		assignmentRepo.On("IsAssigned", strangerID, pvzID).Return(false, nil)
+		svc := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, assignmentRepo)
+
+		_, err := svc.AddItem(models.Actor{UserID: strangerID, Role: models.RoleEmployee}, pvzID, "shoes", models.ItemDetails{})
+		assert.ErrorIs(t, err, service.ErrPvzNotAssigned)
+		mockReceptionRepo.AssertNotCalled(t, "AddItem", employee, pvzID, "shoes", models.ItemDetails{})
+	})
+}
+
+func TestReceptionService_AddItem_Barcode(t *testing.T) {
+	mockReceptionRepo := new(MockReceptionRepository)
+	mockPvzRepo := new(MockPvzRepository)
+	svc := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, newAssignedRepo())
+
+	tests := []struct {
+		name    string
+		barcode string
+		reason  string
+	}{
+		{name: "EAN-13", barcode: "4006381333931"},
+		{name: "Code 128 payload", barcode: "PVZ-00042/A"},
+		{name: "EAN-13 check digit", barcode: "4006381333932", reason: "invalid EAN-13 check digit"},
+		{name: "Control character", barcode: "PVZ\t42", reason: "must be an EAN-13 code or printable ASCII"},
+		{name: "Too long", barcode: strings.Repeat("A", 49), reason: "must be at most 48 characters"},
+		{name: "Empty", barcode: "", reason: "must not be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pvzID := uuid.New()
+			barcode := tt.barcode
+			details := models.ItemDetails{Barcode: &barcode}
+			if tt.reason == "" {
+				item := models.Item{ID: uuid.New(), Type: models.ItemTypeShoes, ItemDetails: details}
+				mockPvzRepo.On("GetPVZByID", pvzID).Return(models.PVZ{ID: pvzID, City: "Москва"}, nil).Once()
+				mockReceptionRepo.On("AddItem", employee, pvzID, "shoes", details).Return(item, nil).Once()
+			}
+
+			_, err := svc.AddItem(employee, pvzID, "shoes", details)
+			if tt.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *apperr.ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, "barcode", validationErr.Field)
+			assert.Equal(t, tt.reason, validationErr.Reason)
+		})
+	}
+	mockReceptionRepo.AssertExpectations(t)
+}
+
+func TestReceptionService_AddItems(t *testing.T) {
+	mockReceptionRepo := new(MockReceptionRepository)
+	mockPvzRepo := new(MockPvzRepository)
+	svc := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, newAssignedRepo())
+
+	t.Run("Invalid products are reported", func(t *testing.T) {
+		reception := models.Reception{ID: uuid.New(), PVZID: uuid.New(), Status: models.ReceptionStatusInProgress}
+		shoes := models.Item{ID: uuid.New(), ReceptionID: reception.ID, Type: models.ItemTypeShoes}
+		clothing := models.Item{ID: uuid.New(), ReceptionID: reception.ID, Type: models.ItemTypeClothing}
+		mockReceptionRepo.On("GetReceptionByID", reception.ID).Return(reception, nil).Once()
+		mockPvzRepo.On("GetPVZByID", reception.PVZID).Return(models.PVZ{ID: reception.PVZID, City: "Казань"}, nil).Once()
+		mockReceptionRepo.On("AddItems", employee, reception.ID, []models.NewItem{{Type: models.ItemTypeShoes}, {Type: models.ItemTypeClothing}}).
+			Return([]models.Item{shoes, clothing}, nil, nil).Once()
+
+		before := testutil.ToFloat64(metrics.ProductsAddedTotal.WithLabelValues("Казань", "shoes"))
+		result, err := svc.AddItems(employee, reception.ID, []models.NewItem{{Type: models.ItemTypeShoes}, {Type: "furniture"}, {Type: models.ItemTypeClothing}})
+		assert.NoError(t, err)
+		assert.Equal(t, before+1, testutil.ToFloat64(metrics.ProductsAddedTotal.WithLabelValues("Казань", "shoes")))
+		assert.Equal(t, 2, result.Added)
+		assert.Equal(t, 1, result.Failed)
+		assert.Equal(t, &shoes, result.Results[0].Product)
+		assert.Equal(t, `invalid type "furniture": must be one of electronics, clothing, shoes`, result.Results[1].Error)
+		assert.Nil(t, result.Results[1].Product)
+		assert.Equal(t, &clothing, result.Results[2].Product)
+		mockReceptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Barcode repeated in the batch", func(t *testing.T) {
+		reception := models.Reception{ID: uuid.New(), PVZID: uuid.New(), Status: models.ReceptionStatusInProgress}
+		barcode := "4006381333931"
+		first := models.NewItem{Type: models.ItemTypeShoes, ItemDetails: models.ItemDetails{Barcode: &barcode}}
+		mockReceptionRepo.On("GetReceptionByID", reception.ID).Return(reception, nil).Once()
+		mockPvzRepo.On("GetPVZByID", reception.PVZID).Return(models.PVZ{ID: reception.PVZID, City: "Москва"}, nil).Once()
+		mockReceptionRepo.On("AddItems", employee, reception.ID, []models.NewItem{first}).
+			Return([]models.Item{{ID: uuid.New(), ReceptionID: reception.ID, Type: models.ItemTypeShoes, ItemDetails: first.ItemDetails}}, nil, nil).Once()
+
+		result, err := svc.AddItems(employee, reception.ID, []models.NewItem{first, first})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Added)
+		assert.Equal(t, `invalid barcode "4006381333931": repeats product 0 of the batch`, result.Results[1].Error)
+		mockReceptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Barcode already in an open reception", func(t *testing.T) {
+		reception := models.Reception{ID: uuid.New(), PVZID: uuid.New(), Status: models.ReceptionStatusInProgress}
+		received, fresh := "4006381333931", "PVZ-00042/A"
+		newItems := []models.NewItem{
+			{Type: models.ItemTypeShoes, ItemDetails: models.ItemDetails{Barcode: &received}},
+			{Type: models.ItemTypeClothing, ItemDetails: models.ItemDetails{Barcode: &fresh}},
+		}
+		clothing := models.Item{ID: uuid.New(), ReceptionID: reception.ID, Type: models.ItemTypeClothing, ItemDetails: newItems[1].ItemDetails}
+		mockReceptionRepo.On("GetReceptionByID", reception.ID).Return(reception, nil).Once()
+		mockPvzRepo.On("GetPVZByID", reception.PVZID).Return(models.PVZ{ID: reception.PVZID, City: "Москва"}, nil).Once()
+		mockReceptionRepo.On("AddItems", employee, reception.ID, newItems).
+			Return([]models.Item{clothing}, []string{received}, nil).Once()
+
+		result, err := svc.AddItems(employee, reception.ID, newItems)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Added)
+		assert.Equal(t, 1, result.Failed)
+		assert.Equal(t, "barcode is already in an open reception: 4006381333931", result.Results[0].Error)
+		assert.Nil(t, result.Results[0].Product)
+		assert.Equal(t, &clothing, result.Results[1].Product)
+	})
+
+	t.Run("Missing type and long SKU are reported per product", func(t *testing.T) {
+		reception := models.Reception{ID: uuid.New(), PVZID: uuid.New(), Status: models.ReceptionStatusInProgress}
+		sku := strings.Repeat("S", 65)
+		mockReceptionRepo.On("GetReceptionByID", reception.ID).Return(reception, nil).Once()
+
+		result, err := svc.AddItems(employee, reception.ID, []models.NewItem{
+			{},
+			{Type: models.ItemTypeShoes, ItemDetails: models.ItemDetails{SKU: &sku}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.Failed)
+		assert.Equal(t, `invalid type "": must be one of electronics, clothing, shoes`, result.Results[0].Error)
+		assert.Contains(t, result.Results[1].Error, "must be at most 64 characters")
+	})
+
+	t.Run("Nothing valid to add", func(t *testing.T) {
+		reception := models.Reception{ID: uuid.New(), PVZID: uuid.New(), Status: models.ReceptionStatusInProgress}
+		mockReceptionRepo.On("GetReceptionByID", reception.ID).Return(reception, nil).Once()
+
+		result, err := svc.AddItems(employee, reception.ID, []models.NewItem{{Type: "furniture"}})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, result.Added)
+		assert.Equal(t, 1, result.Failed)
+		mockReceptionRepo.AssertNotCalled(t, "AddItems", employee, reception.ID, mock.Anything)
+	})
+
+	t.Run("Reception closed", func(t *testing.T) {
+		reception := models.Reception{ID: uuid.New(), PVZID: uuid.New(), Status: models.ReceptionStatusClosed}
+		mockReceptionRepo.On("GetReceptionByID", reception.ID).Return(reception, nil).Once()
+
+		_, err := svc.AddItems(employee, reception.ID, []models.NewItem{{Type: models.ItemTypeShoes}})
+		assert.ErrorIs(t, err, repository.ErrNoActiveReception)
+	})
+
+	t.Run("Reception not found", func(t *testing.T) {
+		receptionID := uuid.New()
+		mockReceptionRepo.On("GetReceptionByID", receptionID).Return(models.Reception{}, nil).Once()
+
+		_, err := svc.AddItems(employee, receptionID, []models.NewItem{{Type: models.ItemTypeShoes}})
+		assert.ErrorIs(t, err, service.ErrReceptionNotFound)
+	})
+
+	t.Run("PVZ not assigned", func(t *testing.T) {
+		reception := models.Reception{ID: uuid.New(), PVZID: uuid.New(), Status: models.ReceptionStatusInProgress}
+		strangerID := uuid.New()
+		assignmentRepo := new(MockAssignmentRepository)
+		assignmentRepo.On("IsAssigned", strangerID, reception.PVZID).Return(false, nil)
+		mockReceptionRepo.On("GetReceptionByID", reception.ID).Return(reception, nil).Once()
+		svc := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, assignmentRepo)
+
+		_, err := svc.AddItems(models.Actor{UserID: strangerID, Role: models.RoleEmployee}, reception.ID, []models.NewItem{{Type: models.ItemTypeShoes}})
+		assert.ErrorIs(t, err, service.ErrPvzNotAssigned)
+	})
 }
 
 func TestReceptionService_DeleteItem(t *testing.T) {
 	mockReceptionRepo := new(MockReceptionRepository)
 	mockPvzRepo := new(MockPvzRepository)
-	service := service.NewReceptionService(mockReceptionRepo, mockPvzRepo)
+	service := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, newAssignedRepo())
 
 	t.Run("Error deleting item", func(t *testing.T) {
 		pvzID := uuid.New()
-		mockReceptionRepo.On("DeleteItem", pvzID).Return(errors.New("database error"))
+		mockReceptionRepo.On("DeleteItem", employee, pvzID).Return(errors.New("database error"))
 
-		err := service.DeleteItem(pvzID)
+		err := service.DeleteItem(employee, pvzID)
 		assert.EqualError(t, err, "database error")
 		mockReceptionRepo.AssertExpectations(t)
 	})
 
 	t.Run("Successful item deletion", func(t *testing.T) {
 		pvzID := uuid.New()
-		mockReceptionRepo.On("DeleteItem", pvzID).Return(nil)
+		mockReceptionRepo.On("DeleteItem", employee, pvzID).Return(nil)
+
+		err := service.DeleteItem(employee, pvzID)
+		assert.NoError(t, err)
+		mockReceptionRepo.AssertExpectations(t)
+	})
+}
+
+func TestReceptionService_DeleteItemByID(t *testing.T) {
+	mockReceptionRepo := new(MockReceptionRepository)
+	mockPvzRepo := new(MockPvzRepository)
+	svc := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, newAssignedRepo())
+
+	t.Run("Product deleted", func(t *testing.T) {
+		reception := models.Reception{ID: uuid.New(), PVZID: uuid.New(), Status: models.ReceptionStatusInProgress}
+		item := models.Item{ID: uuid.New(), ReceptionID: reception.ID, Type: models.ItemTypeShoes}
+		mockReceptionRepo.On("GetReceptionByID", reception.ID).Return(reception, nil).Once()
+		mockReceptionRepo.On("DeleteItemByID", employee, reception.ID, item.ID).Return(item, nil).Once()
+
+		assert.NoError(t, svc.DeleteItemByID(employee, reception.ID, item.ID))
+		mockReceptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		reception := models.Reception{ID: uuid.New(), PVZID: uuid.New(), Status: models.ReceptionStatusInProgress}
+		itemID := uuid.New()
+		mockReceptionRepo.On("GetReceptionByID", reception.ID).Return(reception, nil).Once()
+		mockReceptionRepo.On("DeleteItemByID", employee, reception.ID, itemID).Return(models.Item{}, nil).Once()
+
+		err := svc.DeleteItemByID(employee, reception.ID, itemID)
+		assert.ErrorIs(t, err, service.ErrProductNotFound)
+	})
+
+	t.Run("Reception closed", func(t *testing.T) {
+		reception := models.Reception{ID: uuid.New(), PVZID: uuid.New(), Status: models.ReceptionStatusClosed}
+		itemID := uuid.New()
+		mockReceptionRepo.On("GetReceptionByID", reception.ID).Return(reception, nil).Once()
+
+		err := svc.DeleteItemByID(employee, reception.ID, itemID)
+		assert.ErrorIs(t, err, repository.ErrNoActiveReception)
+		mockReceptionRepo.AssertNotCalled(t, "DeleteItemByID", employee, reception.ID, itemID)
+	})
+
+	t.Run("Reception not found", func(t *testing.T) {
+		receptionID := uuid.New()
+		mockReceptionRepo.On("GetReceptionByID", receptionID).Return(models.Reception{}, nil).Once()
 
-		err := service.DeleteItem(pvzID)
+		err := svc.DeleteItemByID(employee, receptionID, uuid.New())
+		assert.ErrorIs(t, err, service.ErrReceptionNotFound)
+	})
+}
+
+func TestReceptionService_FindProduct(t *testing.T) {
+	mockReceptionRepo := new(MockReceptionRepository)
+	mockPvzRepo := new(MockPvzRepository)
+	svc := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, newAssignedRepo())
+	barcode := "4006381333931"
+
+	t.Run("Found", func(t *testing.T) {
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва"}
+		reception := models.Reception{ID: uuid.New(), PVZID: pvz.ID, Status: models.ReceptionStatusInProgress}
+		item := models.Item{ID: uuid.New(), ReceptionID: reception.ID, Type: models.ItemTypeShoes, ItemDetails: models.ItemDetails{Barcode: &barcode}}
+		mockReceptionRepo.On("GetLatestItemByBarcode", barcode, false).Return(item, nil).Once()
+		mockReceptionRepo.On("GetReceptionByID", reception.ID).Return(reception, nil).Once()
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+
+		location, err := svc.FindProduct(barcode, false)
 		assert.NoError(t, err)
+		assert.Equal(t, models.ProductLocation{Product: item, Reception: reception, PVZ: pvz}, location)
 		mockReceptionRepo.AssertExpectations(t)
+		mockPvzRepo.AssertExpectations(t)
+	})
+
+	t.Run("Found in deactivated PVZ", func(t *testing.T) {
+		deactivatedAt := time.Now()
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва", DeactivatedAt: &deactivatedAt}
+		reception := models.Reception{ID: uuid.New(), PVZID: pvz.ID, Status: models.ReceptionStatusClosed}
+		item := models.Item{ID: uuid.New(), ReceptionID: reception.ID, Type: models.ItemTypeShoes, ItemDetails: models.ItemDetails{Barcode: &barcode}}
+		mockReceptionRepo.On("GetLatestItemByBarcode", barcode, true).Return(item, nil).Once()
+		mockReceptionRepo.On("GetReceptionByID", reception.ID).Return(reception, nil).Once()
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+
+		location, err := svc.FindProduct(barcode, true)
+		assert.NoError(t, err)
+		assert.Equal(t, pvz, location.PVZ)
+	})
+
+	t.Run("Never received", func(t *testing.T) {
+		mockReceptionRepo.On("GetLatestItemByBarcode", barcode, false).Return(models.Item{}, nil).Once()
+
+		_, err := svc.FindProduct(barcode, false)
+		assert.ErrorIs(t, err, service.ErrProductNotFound)
+	})
+
+	t.Run("Malformed barcode", func(t *testing.T) {
+		_, err := svc.FindProduct("4006381333932", false)
+		assert.ErrorIs(t, err, apperr.ErrValidation)
+		mockReceptionRepo.AssertNotCalled(t, "GetLatestItemByBarcode", "4006381333932", false)
+	})
+}
+
+func TestReceptionService_GetReceptions(t *testing.T) {
+	mockReceptionRepo := new(MockReceptionRepository)
+	mockPvzRepo := new(MockPvzRepository)
+	svc := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, newAssignedRepo())
+
+	t.Run("Receptions with products", func(t *testing.T) {
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва"}
+		filter := models.ReceptionFilter{Status: "closed", Limit: 10}
+		reception := models.Reception{ID: uuid.New(), PVZID: pvz.ID, Status: "closed"}
+		items := []models.Item{{ID: uuid.New(), ReceptionID: reception.ID, Type: models.ItemTypeElectronics}}
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+		mockReceptionRepo.On("GetReceptionsWithProducts", pvz.ID, filter).Return([]models.Reception{reception}, nil).Once()
+		mockReceptionRepo.On("GetItemsByReceptionIDs", []uuid.UUID{reception.ID}).Return(items, nil).Once()
+
+		blocks, err := svc.GetReceptions(pvz.ID, false, filter)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.ReceptionBlock{{Reception: reception, Products: items}}, blocks)
+		mockReceptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Deactivated PVZ hidden from employees", func(t *testing.T) {
+		deactivatedAt := time.Now()
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва", DeactivatedAt: &deactivatedAt}
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+
+		_, err := svc.GetReceptions(pvz.ID, false, models.ReceptionFilter{})
+		assert.ErrorIs(t, err, service.ErrPvzNotFound)
+	})
+
+	t.Run("Deactivated PVZ visible to moderators", func(t *testing.T) {
+		deactivatedAt := time.Now()
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва", DeactivatedAt: &deactivatedAt}
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+		mockReceptionRepo.On("GetReceptionsWithProducts", pvz.ID, models.ReceptionFilter{}).Return([]models.Reception{}, nil).Once()
+
+		blocks, err := svc.GetReceptions(pvz.ID, true, models.ReceptionFilter{})
+		assert.NoError(t, err)
+		assert.Empty(t, blocks)
+	})
+}
+
+func TestReceptionService_GetReceptions_QueryCount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockPvzRepo := new(MockPvzRepository)
+	svc := service.NewReceptionService(repository.NewReceptionPostgres(sqlx.NewDb(db, "sqlmock")), mockPvzRepo, newAssignedRepo())
+
+	pvz := models.PVZ{ID: uuid.New(), City: "Москва"}
+	mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+
+	now := time.Now()
+	receptionRows := sqlmock.NewRows([]string{"id", "pvz_id", "created_at", "status"})
+	itemRows := sqlmock.NewRows([]string{"id", "reception_id", "type", "added_at"})
+	for i := 0; i < 50; i++ {
+		receptionID := uuid.New()
+		receptionRows.AddRow(receptionID, pvz.ID, now, "closed")
+		for j := 0; j < 2; j++ {
+			itemRows.AddRow(uuid.New(), receptionID, "shoes", now)
+		}
+	}
+	mock.ExpectQuery(`SELECT id, pvz_id, created_at, status FROM receptions WHERE pvz_id = \$1`).WillReturnRows(receptionRows)
+	mock.ExpectQuery(`SELECT id, reception_id, type, added_at, barcode, sku, description FROM goods WHERE reception_id = ANY\(\$1\)`).WillReturnRows(itemRows)
+
+	blocks, err := svc.GetReceptions(pvz.ID, false, models.ReceptionFilter{Limit: 50})
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 50)
+	for _, block := range blocks {
+		assert.Len(t, block.Products, 2)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReceptionService_GetReception(t *testing.T) {
+	mockReceptionRepo := new(MockReceptionRepository)
+	mockPvzRepo := new(MockPvzRepository)
+	svc := service.NewReceptionService(mockReceptionRepo, mockPvzRepo, newAssignedRepo())
+
+	t.Run("Found", func(t *testing.T) {
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва"}
+		reception := models.Reception{ID: uuid.New(), PVZID: pvz.ID, Status: "in_progress"}
+		mockReceptionRepo.On("GetReceptionByID", reception.ID).Return(reception, nil).Once()
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+		mockReceptionRepo.On("GetItemsByReceptionID", reception.ID).Return([]models.Item{}, nil).Once()
+
+		block, err := svc.GetReception(reception.ID, false)
+		assert.NoError(t, err)
+		assert.Equal(t, reception, block.Reception)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		receptionID := uuid.New()
+		mockReceptionRepo.On("GetReceptionByID", receptionID).Return(models.Reception{}, nil).Once()
+
+		_, err := svc.GetReception(receptionID, true)
+		assert.ErrorIs(t, err, service.ErrReceptionNotFound)
+	})
+
+	t.Run("Deactivated PVZ hidden from employees", func(t *testing.T) {
+		deactivatedAt := time.Now()
+		pvz := models.PVZ{ID: uuid.New(), City: "Москва", DeactivatedAt: &deactivatedAt}
+		reception := models.Reception{ID: uuid.New(), PVZID: pvz.ID, Status: "closed"}
+		mockReceptionRepo.On("GetReceptionByID", reception.ID).Return(reception, nil).Once()
+		mockPvzRepo.On("GetPVZByID", pvz.ID).Return(pvz, nil).Once()
+
+		_, err := svc.GetReception(reception.ID, false)
+		assert.ErrorIs(t, err, service.ErrReceptionNotFound)
 	})
 }
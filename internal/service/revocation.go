@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"pvz-test/internal/repository"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// RevocationList keeps revoked access token ids in memory so JWTMiddleware
+// does not hit the database on every request. Other instances' revocations
+// are picked up by Sync.
+type RevocationList struct {
+	tokenRepo repository.TokenRepository
+
+	mu      sync.RWMutex
+	revoked map[uuid.UUID]time.Time
+}
+
+func NewRevocationList(tokenRepo repository.TokenRepository) *RevocationList {
+	return &RevocationList{
+		tokenRepo: tokenRepo,
+		revoked:   make(map[uuid.UUID]time.Time),
+	}
+}
+
+func (l *RevocationList) Revoke(jti uuid.UUID, expiresAt time.Time) error {
+	if err := l.tokenRepo.RevokeAccessToken(jti, expiresAt); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.revoked[jti] = expiresAt
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *RevocationList) IsRevoked(jti uuid.UUID) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	_, ok := l.revoked[jti]
+	return ok
+}
+
+func (l *RevocationList) Load() error {
+	tokens, err := l.tokenRepo.GetRevokedAccessTokens()
+	if err != nil {
+		return err
+	}
+
+	revoked := make(map[uuid.UUID]time.Time, len(tokens))
+	for _, token := range tokens {
+		revoked[token.JTI] = token.ExpiresAt
+	}
+
+	l.mu.Lock()
+	l.revoked = revoked
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *RevocationList) Sync(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.Load(); err != nil {
+				logrus.Errorf("revocation list sync error: %s", err.Error())
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"pvz-test/internal/models"
 	"pvz-test/internal/repository"
 	"time"
@@ -10,33 +11,105 @@ import (
 
 type Authorization interface {
 	Register(user models.RegisterRequest) (models.UserResponse, error)
-	Login(user models.LoginRequest) (string, error)
-	DummyLogin(role models.Role) (string, error)
+	Login(user models.LoginRequest) (models.TokenPair, error)
+	DummyLogin(role models.Role) (models.TokenPair, error)
+	Refresh(refreshToken string) (models.TokenPair, error)
+	Logout(accessToken, refreshToken string) error
 	ParseToken(token string) (uuid.UUID, models.Role, error)
 }
 
 type Reception interface {
-	CreateReception(pvzID uuid.UUID) (models.Reception, error)
-	CloseActiveReception(pvzID uuid.UUID) (models.Reception, error)
-	DeleteItem(pvzID uuid.UUID) error
-	AddItem(pvzID uuid.UUID, itemType string) (models.Item, error)
+	CreateReception(actor models.Actor, pvzID uuid.UUID) (models.Reception, error)
+	CloseActiveReception(actor models.Actor, pvzID uuid.UUID) (models.Reception, error)
+	DeleteItem(actor models.Actor, pvzID uuid.UUID) error
+	DeleteItemByID(actor models.Actor, receptionID, itemID uuid.UUID) error
+	AddItem(actor models.Actor, pvzID uuid.UUID, itemType string, details models.ItemDetails) (models.Item, error)
+	AddItems(actor models.Actor, receptionID uuid.UUID, newItems []models.NewItem) (models.BatchAddResult, error)
+	FindProduct(barcode string, includeDeactivated bool) (models.ProductLocation, error)
+	GetReceptions(pvzID uuid.UUID, includeDeactivated bool, filter models.ReceptionFilter) ([]models.ReceptionBlock, error)
+	GetReception(receptionID uuid.UUID, includeDeactivated bool) (models.ReceptionBlock, error)
 }
 
 type Pvz interface {
-	CreatePvz(city string) (models.PVZ, error)
-	GetFilteredPVZ(start, end *time.Time, limit, offset int) ([]models.PVZResponse, error)
+	CreatePvz(actor models.Actor, city string) (models.PVZ, error)
+	GetFilteredPVZ(start, end *time.Time, includeEmpty bool, status models.PVZStatus, limit, offset int) ([]models.PVZResponse, error)
+	GetPVZList() ([]models.PVZ, error)
+	GetPVZByID(pvzID uuid.UUID) (models.PVZ, error)
+	UpdatePVZ(actor models.Actor, pvzID uuid.UUID, city string) (models.PVZ, error)
+	DeactivatePVZ(actor models.Actor, pvzID uuid.UUID) (models.PVZ, error)
+}
+
+type City interface {
+	CreateCity(name string) (models.City, error)
+	GetCities() ([]models.City, error)
+	GetCityByID(cityID uuid.UUID) (models.City, error)
+	UpdateCity(cityID uuid.UUID, name string) (models.City, error)
+	DeleteCity(cityID uuid.UUID) error
+}
+
+type Assignment interface {
+	AssignEmployee(pvzID, userID uuid.UUID) (models.EmployeeAssignment, error)
+	UnassignEmployee(pvzID, userID uuid.UUID) error
+	GetPVZEmployees(pvzID uuid.UUID) ([]models.EmployeeAssignment, error)
+}
+
+type Audit interface {
+	GetAuditEvents(filter models.AuditFilter) ([]models.AuditEvent, error)
+}
+
+type Webhook interface {
+	CreateWebhook(req models.WebhookRequest) (models.Webhook, error)
+	GetWebhooks() ([]models.Webhook, error)
+	DeleteWebhook(webhookID uuid.UUID) error
+	GetDeliveries(webhookID uuid.UUID, filter models.DeliveryFilter) ([]models.WebhookDelivery, error)
+	RetryDelivery(webhookID, deliveryID uuid.UUID) (models.WebhookDelivery, error)
+}
+
+type Health interface {
+	Liveness() models.HealthReport
+	Readiness(ctx context.Context) models.HealthReport
 }
 
 type Service struct {
 	Authorization
 	Reception
 	Pvz
+	City
+	Assignment
+	Audit
+	Webhook
+	Health
+	Revocations *RevocationList
+	Outbox      *OutboxDispatcher
+	Webhooks    *WebhookDeliverer
+	Idempotency *IdempotencyStore
+}
+
+type Config struct {
+	Auth     AuthConfig
+	Health   HealthConfig
+	Outbox   OutboxConfig
+	Webhooks WebhookDeliveryConfig
+	// IdempotencyTTL is how long responses to Idempotency-Key requests are kept.
+	IdempotencyTTL time.Duration
+	CityCacheTTL   time.Duration
 }
 
-func NewService(repos *repository.Repository) *Service {
+func NewService(repos *repository.Repository, cfg Config) *Service {
+	revocations := NewRevocationList(repos.TokenRepository)
+	cities := NewCityCatalog(repos.CityRepository, cfg.CityCacheTTL)
 	return &Service{
-		Authorization: NewAuthService(repos.UserRepository),
-		Reception:     NewReceptionService(repos.ReceptionRepository, repos.PvzRepository),
-		Pvz:           NewPvzService(repos.PvzRepository, repos.ReceptionRepository),
+		Revocations:   revocations,
+		Outbox:        NewOutboxDispatcher(repos.OutboxRepository, cfg.Outbox),
+		Webhooks:      NewWebhookDeliverer(repos.WebhookRepository, cfg.Webhooks),
+		Idempotency:   NewIdempotencyStore(repos.IdempotencyRepository, cfg.IdempotencyTTL),
+		Authorization: NewAuthService(repos.UserRepository, repos.TokenRepository, revocations, cfg.Auth),
+		Reception:     NewReceptionService(repos.ReceptionRepository, repos.PvzRepository, repos.AssignmentRepository),
+		Pvz:           NewPvzService(repos.PvzRepository, repos.ReceptionRepository, cities),
+		City:          NewCityService(repos.CityRepository, cities),
+		Assignment:    NewAssignmentService(repos.AssignmentRepository, repos.PvzRepository, repos.UserRepository),
+		Audit:         NewAuditService(repos.AuditRepository),
+		Webhook:       NewWebhookService(repos.WebhookRepository, repos.PvzRepository, cities),
+		Health:        NewHealthService(repos.HealthRepository, cfg.Health),
 	}
 }
@@ -17,11 +17,16 @@ func TestNewService(t *testing.T) {
 		UserRepository:      mockUserRepo,
 		PvzRepository:       mockPvzRepo,
 		ReceptionRepository: mockReceptionRepo,
+		TokenRepository:     new(MockTokenRepository),
+		CityRepository:      new(MockCityRepository),
+		HealthRepository:    new(MockHealthRepository),
 	}
 
-	svc := service.NewService(repos)
+	svc := service.NewService(repos, service.Config{Auth: testAuthConfig()})
 
 	assert.NotNil(t, svc.Authorization)
 	assert.NotNil(t, svc.Reception)
 	assert.NotNil(t, svc.Pvz)
+	assert.NotNil(t, svc.City)
+	assert.NotNil(t, svc.Health)
 }
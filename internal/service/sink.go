@@ -0,0 +1,91 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"pvz-test/internal/models"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogSink writes events to the service log.
+type LogSink struct{}
+
+func (LogSink) Publish(ctx context.Context, event models.Event) error {
+	logrus.WithFields(logrus.Fields{
+		"event_id":     event.ID,
+		"event_type":   event.Type,
+		"aggregate_id": event.AggregateID,
+		"pvz_id":       event.PVZID,
+		"payload":      string(event.Payload),
+	}).Info("domain event")
+	return nil
+}
+
+// WebhookSink POSTs each event as JSON to a fixed URL. Any status outside
+// 2xx is a failed delivery.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, event models.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Id", event.ID.String())
+	req.Header.Set("X-Event-Type", string(event.Type))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// MemorySink keeps published events in memory, for tests.
+type MemorySink struct {
+	mu     sync.Mutex
+	events []models.Event
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Publish(ctx context.Context, event models.Event) error {
+	s.mu.Lock()
+	s.events = append(s.events, event)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemorySink) Events() []models.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := make([]models.Event, len(s.events))
+	copy(events, s.events)
+	return events
+}
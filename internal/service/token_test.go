@@ -0,0 +1,140 @@
+package service_test
+
+import (
+	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
+	"pvz-test/internal/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockTokenRepository) CreateRefreshToken(token models.RefreshToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RotateRefreshToken(tokenHash string, next models.RefreshToken) (models.RefreshToken, error) {
+	args := m.Called(tokenHash, next)
+	return args.Get(0).(models.RefreshToken), args.Error(1)
+}
+
+func (m *MockTokenRepository) RevokeRefreshTokenFamily(tokenHash string) error {
+	args := m.Called(tokenHash)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RevokeAccessToken(jti uuid.UUID, expiresAt time.Time) error {
+	args := m.Called(jti, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) GetRevokedAccessTokens() ([]models.RevokedToken, error) {
+	args := m.Called()
+	return args.Get(0).([]models.RevokedToken), args.Error(1)
+}
+
+func newTokenRepository() *MockTokenRepository {
+	tokenRepo := new(MockTokenRepository)
+	tokenRepo.On("CreateRefreshToken", mock.Anything).Return(nil).Maybe()
+	return tokenRepo
+}
+
+func TestAuthorizationService_DummyLogin_RefreshToken(t *testing.T) {
+	tokenRepo := new(MockTokenRepository)
+	authService := newTestAuthService(nil, tokenRepo)
+
+	var stored models.RefreshToken
+	tokenRepo.On("CreateRefreshToken", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(models.RefreshToken)
+	}).Return(nil)
+
+	tokens, err := authService.DummyLogin(models.RoleEmployee)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.NotEqual(t, tokens.RefreshToken, stored.TokenHash, "only the hash is stored")
+	assert.Equal(t, uuid.Max, stored.UserID)
+	assert.Equal(t, models.RoleEmployee, stored.Role)
+	assert.NotEqual(t, uuid.Nil, stored.FamilyID)
+	assert.WithinDuration(t, time.Now().Add(refreshTTL), stored.ExpiresAt, time.Minute)
+	tokenRepo.AssertExpectations(t)
+}
+
+func TestAuthorizationService_Refresh(t *testing.T) {
+	t.Run("Rotates refresh token", func(t *testing.T) {
+		tokenRepo := newTokenRepository()
+		authService := newTestAuthService(nil, tokenRepo)
+
+		tokens, err := authService.DummyLogin(models.RoleModerator)
+		require.NoError(t, err)
+
+		userID := uuid.New()
+		tokenRepo.On("RotateRefreshToken", mock.AnythingOfType("string"), mock.AnythingOfType("models.RefreshToken")).
+			Return(models.RefreshToken{UserID: userID, Role: models.RoleModerator}, nil)
+
+		refreshed, err := authService.Refresh(tokens.RefreshToken)
+		require.NoError(t, err)
+		assert.NotEqual(t, tokens.RefreshToken, refreshed.RefreshToken)
+
+		parsedID, role, err := authService.ParseToken(refreshed.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, userID, parsedID)
+		assert.Equal(t, models.RoleModerator, role)
+		tokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reused refresh token", func(t *testing.T) {
+		tokenRepo := newTokenRepository()
+		authService := newTestAuthService(nil, tokenRepo)
+
+		tokenRepo.On("RotateRefreshToken", mock.AnythingOfType("string"), mock.AnythingOfType("models.RefreshToken")).
+			Return(models.RefreshToken{}, repository.ErrRefreshTokenReused)
+
+		tokens, err := authService.Refresh("already-used")
+		assert.ErrorIs(t, err, repository.ErrRefreshTokenReused)
+		assert.Empty(t, tokens.AccessToken)
+		tokenRepo.AssertExpectations(t)
+	})
+}
+
+func TestAuthorizationService_Logout(t *testing.T) {
+	tokenRepo := newTokenRepository()
+	authService := newTestAuthService(nil, tokenRepo)
+
+	tokens, err := authService.DummyLogin(models.RoleEmployee)
+	require.NoError(t, err)
+
+	tokenRepo.On("RevokeAccessToken", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("time.Time")).Return(nil)
+	tokenRepo.On("RevokeRefreshTokenFamily", mock.AnythingOfType("string")).Return(nil)
+
+	err = authService.Logout(tokens.AccessToken, tokens.RefreshToken)
+	require.NoError(t, err)
+
+	_, _, err = authService.ParseToken(tokens.AccessToken)
+	assert.ErrorIs(t, err, service.ErrTokenRevoked)
+	tokenRepo.AssertExpectations(t)
+}
+
+func TestRevocationList_Load(t *testing.T) {
+	tokenRepo := new(MockTokenRepository)
+	revocations := service.NewRevocationList(tokenRepo)
+
+	jti := uuid.New()
+	tokenRepo.On("GetRevokedAccessTokens").Return([]models.RevokedToken{
+		{JTI: jti, ExpiresAt: time.Now().Add(time.Minute)},
+	}, nil)
+
+	require.NoError(t, revocations.Load())
+	assert.True(t, revocations.IsRevoked(jti))
+	assert.False(t, revocations.IsRevoked(uuid.New()))
+	tokenRepo.AssertExpectations(t)
+}
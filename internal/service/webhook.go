@@ -0,0 +1,279 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/models"
+	"pvz-test/internal/repository"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrWebhookNotFound      = apperr.New(apperr.ErrNotFound, "webhook not found")
+	ErrDeadDeliveryNotFound = apperr.New(apperr.ErrNotFound, "dead delivery not found")
+)
+
+const (
+	WebhookIDHeader        = "X-Webhook-Id"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+var eventTypes = map[models.EventType]struct{}{
+	models.EventPVZCreated:      {},
+	models.EventReceptionOpened: {},
+	models.EventReceptionClosed: {},
+	models.EventProductAdded:    {},
+	models.EventProductRemoved:  {},
+}
+
+type WebhookService struct {
+	webhookRepo repository.WebhookRepository
+	pvzRepo     repository.PvzRepository
+	cities      *CityCatalog
+}
+
+func NewWebhookService(webhookRepo repository.WebhookRepository, pvzRepo repository.PvzRepository, cities *CityCatalog) *WebhookService {
+	return &WebhookService{webhookRepo: webhookRepo, pvzRepo: pvzRepo, cities: cities}
+}
+
+func (s *WebhookService) CreateWebhook(req models.WebhookRequest) (models.Webhook, error) {
+	for _, eventType := range req.EventTypes {
+		if _, ok := eventTypes[eventType]; !ok {
+			return models.Webhook{}, &apperr.ValidationError{Field: "eventTypes", Value: string(eventType), Reason: "unknown event type"}
+		}
+	}
+	if req.PVZID != nil {
+		pvz, err := s.pvzRepo.GetPVZByID(*req.PVZID)
+		if err != nil {
+			return models.Webhook{}, err
+		}
+		if (pvz == models.PVZ{}) {
+			return models.Webhook{}, fmt.Errorf("%w: %s", ErrPvzNotFound, req.PVZID.String())
+		}
+	}
+	if req.City != nil {
+		supported, err := s.cities.Contains(*req.City)
+		if err != nil {
+			return models.Webhook{}, fmt.Errorf("city catalog error: %w", err)
+		}
+		if !supported {
+			return models.Webhook{}, cityNotSupported(*req.City)
+		}
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	return s.webhookRepo.CreateWebhook(models.Webhook{
+		URL:        req.URL,
+		Secret:     secret,
+		EventTypes: req.EventTypes,
+		PVZID:      req.PVZID,
+		City:       req.City,
+	})
+}
+
+func (s *WebhookService) GetWebhooks() ([]models.Webhook, error) {
+	return s.webhookRepo.GetWebhooks()
+}
+
+func (s *WebhookService) DeleteWebhook(webhookID uuid.UUID) error {
+	deleted, err := s.webhookRepo.DeleteWebhook(webhookID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("%w: %s", ErrWebhookNotFound, webhookID.String())
+	}
+	return nil
+}
+
+func (s *WebhookService) GetDeliveries(webhookID uuid.UUID, filter models.DeliveryFilter) ([]models.WebhookDelivery, error) {
+	webhook, err := s.webhookRepo.GetWebhookByID(webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook.ID == uuid.Nil {
+		return nil, fmt.Errorf("%w: %s", ErrWebhookNotFound, webhookID.String())
+	}
+	return s.webhookRepo.GetDeliveries(webhookID, filter)
+}
+
+func (s *WebhookService) RetryDelivery(webhookID, deliveryID uuid.UUID) (models.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.RetryDelivery(webhookID, deliveryID)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	if delivery.ID == uuid.Nil {
+		return models.WebhookDelivery{}, fmt.Errorf("%w: %s", ErrDeadDeliveryNotFound, deliveryID.String())
+	}
+	return delivery, nil
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>" under
+// secret. Receivers recompute it to check X-Webhook-Signature.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SubscriptionSink turns outbox events into pending deliveries for every
+// webhook subscribed to them.
+type SubscriptionSink struct {
+	webhookRepo repository.WebhookRepository
+}
+
+func NewSubscriptionSink(webhookRepo repository.WebhookRepository) *SubscriptionSink {
+	return &SubscriptionSink{webhookRepo: webhookRepo}
+}
+
+func (s *SubscriptionSink) Publish(ctx context.Context, event models.Event) error {
+	_, err := s.webhookRepo.EnqueueDeliveries(event)
+	return err
+}
+
+type WebhookDeliveryConfig struct {
+	BatchSize int
+	Lease     time.Duration
+	Timeout   time.Duration
+	// MaxAttempts is how many times a delivery is tried before it is dead.
+	MaxAttempts int
+	RetryBase   time.Duration
+	RetryMax    time.Duration
+}
+
+// WebhookDeliverer sends pending deliveries to subscribers, signed with the
+// subscription secret, and retries failures with exponential backoff.
+type WebhookDeliverer struct {
+	webhookRepo repository.WebhookRepository
+	client      *http.Client
+	cfg         WebhookDeliveryConfig
+}
+
+func NewWebhookDeliverer(webhookRepo repository.WebhookRepository, cfg WebhookDeliveryConfig) *WebhookDeliverer {
+	return &WebhookDeliverer{
+		webhookRepo: webhookRepo,
+		client:      &http.Client{Timeout: cfg.Timeout},
+		cfg:         cfg,
+	}
+}
+
+// Deliver sends one batch of due deliveries and returns how many were claimed.
+func (d *WebhookDeliverer) Deliver(ctx context.Context) (int, error) {
+	attempts, err := d.webhookRepo.ClaimDeliveries(d.cfg.BatchSize, d.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, attempt := range attempts {
+		if ctx.Err() != nil {
+			return len(attempts), ctx.Err()
+		}
+
+		status, err := d.send(ctx, attempt)
+		if err == nil {
+			if err := d.webhookRepo.MarkDeliveryDelivered(attempt.ID, status); err != nil {
+				return len(attempts), err
+			}
+			continue
+		}
+
+		var responseStatus *int
+		if status != 0 {
+			responseStatus = &status
+		}
+		var retryAt *time.Time
+		if attempt.Attempts < d.cfg.MaxAttempts {
+			at := time.Now().Add(retryDelay(attempt.Attempts, d.cfg.RetryBase, d.cfg.RetryMax))
+			retryAt = &at
+		} else {
+			logrus.Warnf("webhook delivery %s to %s is dead after %d attempts: %s",
+				attempt.ID, attempt.URL, attempt.Attempts, err.Error())
+		}
+		if err := d.webhookRepo.MarkDeliveryFailed(attempt.ID, err.Error(), responseStatus, retryAt); err != nil {
+			return len(attempts), err
+		}
+	}
+	return len(attempts), nil
+}
+
+// Run delivers due webhooks every interval until ctx is cancelled.
+func (d *WebhookDeliverer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				claimed, err := d.Deliver(ctx)
+				if err != nil {
+					if !errors.Is(err, context.Canceled) {
+						logrus.Errorf("webhook delivery error: %s", err.Error())
+					}
+					break
+				}
+				if claimed < d.cfg.BatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// send posts the delivery and returns the response status, zero if there
+// was no response.
+func (d *WebhookDeliverer) send(ctx context.Context, attempt models.WebhookAttempt) (int, error) {
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, attempt.URL, bytes.NewReader(attempt.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, attempt.ID.String())
+	req.Header.Set(WebhookEventHeader, string(attempt.EventType))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(attempt.Secret, timestamp, attempt.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,248 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"pvz-test/internal/apperr"
+	"pvz-test/internal/models"
+	"pvz-test/internal/service"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) CreateWebhook(webhook models.Webhook) (models.Webhook, error) {
+	args := m.Called(webhook)
+	return args.Get(0).(models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetWebhooks() ([]models.Webhook, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetWebhookByID(webhookID uuid.UUID) (models.Webhook, error) {
+	args := m.Called(webhookID)
+	return args.Get(0).(models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) DeleteWebhook(webhookID uuid.UUID) (bool, error) {
+	args := m.Called(webhookID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWebhookRepository) EnqueueDeliveries(event models.Event) (int64, error) {
+	args := m.Called(event)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ClaimDeliveries(limit int, lease time.Duration) ([]models.WebhookAttempt, error) {
+	args := m.Called(limit, lease)
+	return args.Get(0).([]models.WebhookAttempt), args.Error(1)
+}
+
+func (m *MockWebhookRepository) MarkDeliveryDelivered(deliveryID uuid.UUID, responseStatus int) error {
+	args := m.Called(deliveryID, responseStatus)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) MarkDeliveryFailed(deliveryID uuid.UUID, reason string, responseStatus *int, retryAt *time.Time) error {
+	args := m.Called(deliveryID, reason, responseStatus, retryAt)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetDeliveries(webhookID uuid.UUID, filter models.DeliveryFilter) ([]models.WebhookDelivery, error) {
+	args := m.Called(webhookID, filter)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) RetryDelivery(webhookID, deliveryID uuid.UUID) (models.WebhookDelivery, error) {
+	args := m.Called(webhookID, deliveryID)
+	return args.Get(0).(models.WebhookDelivery), args.Error(1)
+}
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	mockWebhookRepo := new(MockWebhookRepository)
+	mockPvzRepo := new(MockPvzRepository)
+	mockCityRepo := new(MockCityRepository)
+	mockCityRepo.On("GetCities").Return([]models.City{{Name: "Москва"}}, nil)
+	svc := service.NewWebhookService(mockWebhookRepo, mockPvzRepo, service.NewCityCatalog(mockCityRepo, time.Minute))
+
+	t.Run("Unknown event type", func(t *testing.T) {
+		_, err := svc.CreateWebhook(models.WebhookRequest{
+			URL:        "https://partner.example/hooks",
+			EventTypes: []models.EventType{models.EventReceptionClosed, "ReceptionDeleted"},
+		})
+		assert.ErrorIs(t, err, apperr.ErrValidation)
+		assert.ErrorContains(t, err, "ReceptionDeleted")
+	})
+
+	t.Run("Unknown PVZ", func(t *testing.T) {
+		pvzID := uuid.New()
+		mockPvzRepo.On("GetPVZByID", pvzID).Return(models.PVZ{}, nil).Once()
+
+		_, err := svc.CreateWebhook(models.WebhookRequest{
+			URL:        "https://partner.example/hooks",
+			EventTypes: []models.EventType{models.EventReceptionClosed},
+			PVZID:      &pvzID,
+		})
+		assert.ErrorIs(t, err, service.ErrPvzNotFound)
+	})
+
+	t.Run("Unsupported city", func(t *testing.T) {
+		city := "Атлантида"
+		_, err := svc.CreateWebhook(models.WebhookRequest{
+			URL:        "https://partner.example/hooks",
+			EventTypes: []models.EventType{models.EventReceptionClosed},
+			City:       &city,
+		})
+		assert.ErrorIs(t, err, service.ErrCityNotSupported)
+	})
+
+	t.Run("Created with a generated secret", func(t *testing.T) {
+		city := "Москва"
+		mockWebhookRepo.On("CreateWebhook", mock.MatchedBy(func(webhook models.Webhook) bool {
+			return webhook.URL == "https://partner.example/hooks" && len(webhook.Secret) == 64 && *webhook.City == city
+		})).Return(models.Webhook{ID: uuid.New(), Secret: "generated"}, nil).Once()
+
+		webhook, err := svc.CreateWebhook(models.WebhookRequest{
+			URL:        "https://partner.example/hooks",
+			EventTypes: []models.EventType{models.EventReceptionClosed},
+			City:       &city,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "generated", webhook.Secret)
+		mockWebhookRepo.AssertExpectations(t)
+	})
+}
+
+func TestWebhookService_GetDeliveries(t *testing.T) {
+	mockWebhookRepo := new(MockWebhookRepository)
+	svc := service.NewWebhookService(mockWebhookRepo, new(MockPvzRepository), nil)
+
+	t.Run("Webhook not found", func(t *testing.T) {
+		webhookID := uuid.New()
+		mockWebhookRepo.On("GetWebhookByID", webhookID).Return(models.Webhook{}, nil).Once()
+
+		_, err := svc.GetDeliveries(webhookID, models.DeliveryFilter{})
+		assert.ErrorIs(t, err, service.ErrWebhookNotFound)
+	})
+
+	t.Run("Dead delivery not found", func(t *testing.T) {
+		webhookID, deliveryID := uuid.New(), uuid.New()
+		mockWebhookRepo.On("RetryDelivery", webhookID, deliveryID).Return(models.WebhookDelivery{}, nil).Once()
+
+		_, err := svc.RetryDelivery(webhookID, deliveryID)
+		assert.ErrorIs(t, err, service.ErrDeadDeliveryNotFound)
+	})
+}
+
+func webhookDeliveryConfig() service.WebhookDeliveryConfig {
+	return service.WebhookDeliveryConfig{
+		BatchSize:   10,
+		Lease:       time.Minute,
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		RetryBase:   time.Second,
+		RetryMax:    time.Minute,
+	}
+}
+
+func newWebhookAttempt(url string, attempts int) models.WebhookAttempt {
+	payload, _ := json.Marshal(models.Event{ID: uuid.New(), Type: models.EventReceptionClosed})
+	return models.WebhookAttempt{
+		WebhookDelivery: models.WebhookDelivery{
+			ID:        uuid.New(),
+			WebhookID: uuid.New(),
+			EventType: models.EventReceptionClosed,
+			Status:    models.DeliveryStatusPending,
+			Attempts:  attempts,
+			Payload:   payload,
+		},
+		URL:    url,
+		Secret: "partner-secret",
+	}
+}
+
+func TestWebhookDeliverer_Deliver(t *testing.T) {
+	t.Run("Signed delivery", func(t *testing.T) {
+		var attempt models.WebhookAttempt
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			timestamp, err := strconv.ParseInt(r.Header.Get(service.WebhookTimestampHeader), 10, 64)
+			assert.NoError(t, err)
+			assert.Equal(t, "sha256="+service.SignWebhook("partner-secret", timestamp, body), r.Header.Get(service.WebhookSignatureHeader))
+			assert.Equal(t, attempt.ID.String(), r.Header.Get(service.WebhookIDHeader))
+			assert.Equal(t, "ReceptionClosed", r.Header.Get(service.WebhookEventHeader))
+			assert.JSONEq(t, string(attempt.Payload), string(body))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer receiver.Close()
+
+		attempt = newWebhookAttempt(receiver.URL, 1)
+		repo := new(MockWebhookRepository)
+		repo.On("ClaimDeliveries", 10, time.Minute).Return([]models.WebhookAttempt{attempt}, nil).Once()
+		repo.On("MarkDeliveryDelivered", attempt.ID, http.StatusNoContent).Return(nil).Once()
+
+		claimed, err := service.NewWebhookDeliverer(repo, webhookDeliveryConfig()).Deliver(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, claimed)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Failed delivery is retried with backoff", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer receiver.Close()
+
+		attempt := newWebhookAttempt(receiver.URL, 2)
+		repo := new(MockWebhookRepository)
+		repo.On("ClaimDeliveries", 10, time.Minute).Return([]models.WebhookAttempt{attempt}, nil).Once()
+		repo.On("MarkDeliveryFailed", attempt.ID, "webhook responded with status 502",
+			mock.MatchedBy(func(status *int) bool { return status != nil && *status == http.StatusBadGateway }),
+			mock.MatchedBy(func(retryAt *time.Time) bool {
+				return retryAt != nil && time.Until(*retryAt) > time.Second && time.Until(*retryAt) <= 2*time.Second
+			})).Return(nil).Once()
+
+		_, err := service.NewWebhookDeliverer(repo, webhookDeliveryConfig()).Deliver(context.Background())
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Last attempt goes to dead letter", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		receiver.Close()
+
+		attempt := newWebhookAttempt(receiver.URL, 3)
+		repo := new(MockWebhookRepository)
+		repo.On("ClaimDeliveries", 10, time.Minute).Return([]models.WebhookAttempt{attempt}, nil).Once()
+		repo.On("MarkDeliveryFailed", attempt.ID, mock.AnythingOfType("string"), (*int)(nil), (*time.Time)(nil)).Return(nil).Once()
+
+		_, err := service.NewWebhookDeliverer(repo, webhookDeliveryConfig()).Deliver(context.Background())
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestSubscriptionSink_Publish(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	sink := service.NewSubscriptionSink(repo)
+
+	event := newTestEvent(models.EventReceptionClosed, 1)
+	repo.On("EnqueueDeliveries", event).Return(int64(0), errors.New("database error")).Once()
+
+	assert.EqualError(t, sink.Publish(context.Background(), event), "database error")
+}
@@ -0,0 +1,3 @@
+ALTER TABLE pvz DROP CONSTRAINT IF EXISTS fk_pvz_city;
+
+DROP TABLE IF EXISTS cities;
@@ -0,0 +1,14 @@
+CREATE TABLE cities (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT UNIQUE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO cities (name) VALUES ('Москва'), ('Санкт-Петербург'), ('Казань');
+
+INSERT INTO cities (name)
+SELECT DISTINCT city FROM pvz
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE pvz
+    ADD CONSTRAINT fk_pvz_city FOREIGN KEY (city) REFERENCES cities(name) ON UPDATE CASCADE;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pvz_active;
+
+ALTER TABLE pvz DROP COLUMN IF EXISTS deactivated_at;
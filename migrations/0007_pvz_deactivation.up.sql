@@ -0,0 +1,3 @@
+ALTER TABLE pvz ADD COLUMN deactivated_at TIMESTAMP;
+
+CREATE INDEX idx_pvz_active ON pvz(registration_date DESC) WHERE deactivated_at IS NULL;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS unique_in_progress_reception_per_pvz;
+
+CREATE UNIQUE INDEX unique_active_reception_per_pvz
+ON receptions(pvz_id)
+WHERE status = 'active';
+
+ALTER TABLE receptions DROP CONSTRAINT IF EXISTS receptions_status_check;
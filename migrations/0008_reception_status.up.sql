@@ -0,0 +1,20 @@
+UPDATE receptions SET status = 'closed' WHERE status <> 'in_progress';
+
+UPDATE receptions r
+SET status = 'closed'
+WHERE r.status = 'in_progress'
+  AND EXISTS (
+    SELECT 1 FROM receptions newer
+    WHERE newer.pvz_id = r.pvz_id
+      AND newer.status = 'in_progress'
+      AND (newer.created_at, newer.id) > (r.created_at, r.id)
+  );
+
+ALTER TABLE receptions
+ADD CONSTRAINT receptions_status_check CHECK (status IN ('in_progress', 'closed'));
+
+DROP INDEX IF EXISTS unique_active_reception_per_pvz;
+
+CREATE UNIQUE INDEX unique_in_progress_reception_per_pvz
+ON receptions(pvz_id)
+WHERE status = 'in_progress';
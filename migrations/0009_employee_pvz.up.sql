@@ -0,0 +1,10 @@
+-- user_id has no foreign key: dummy tokens are issued for a user that is not
+-- stored in users, the same as in refresh_tokens.
+CREATE TABLE employee_pvz (
+    user_id UUID NOT NULL,
+    pvz_id UUID NOT NULL REFERENCES pvz(id) ON DELETE CASCADE,
+    assigned_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, pvz_id)
+);
+
+CREATE INDEX idx_employee_pvz_pvz_id ON employee_pvz(pvz_id);
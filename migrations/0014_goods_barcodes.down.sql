@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_goods_barcode;
+
+ALTER TABLE goods
+    DROP COLUMN IF EXISTS barcode,
+    DROP COLUMN IF EXISTS sku,
+    DROP COLUMN IF EXISTS description;
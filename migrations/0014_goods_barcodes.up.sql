@@ -0,0 +1,8 @@
+-- A barcode may be received again once its reception is closed, so
+-- uniqueness across open receptions is checked by the repository.
+ALTER TABLE goods
+    ADD COLUMN barcode TEXT,
+    ADD COLUMN sku TEXT,
+    ADD COLUMN description TEXT;
+
+CREATE INDEX idx_goods_barcode ON goods(barcode, added_at DESC) WHERE barcode IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE webhook_subscriptions DROP CONSTRAINT IF EXISTS fk_webhook_subscriptions_city;
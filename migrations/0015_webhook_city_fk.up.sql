@@ -0,0 +1,7 @@
+-- Keeps city-filtered subscriptions matching after a city is renamed.
+INSERT INTO cities (name)
+SELECT DISTINCT city FROM webhook_subscriptions WHERE city IS NOT NULL
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE webhook_subscriptions
+    ADD CONSTRAINT fk_webhook_subscriptions_city FOREIGN KEY (city) REFERENCES cities(name) ON UPDATE CASCADE;
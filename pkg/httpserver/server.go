@@ -2,26 +2,55 @@ package httpserver
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 )
 
+type Config struct {
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	MaxHeaderBytes int
+}
+
 type Server struct {
 	httpServer *http.Server
 }
 
-func (s *Server) Start(serverAddress string, handler http.Handler) error {
-	s.httpServer = &http.Server{
-		Addr:           serverAddress,
-		Handler:        handler,
-		MaxHeaderBytes: 1 << 20,
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
+// New creates a Server for handler on address. Zero limits in cfg are
+// replaced with the defaults below.
+func New(address string, handler http.Handler, cfg Config) *Server {
+	if cfg.ReadTimeout == 0 {
+		cfg.ReadTimeout = 10 * time.Second
+	}
+	if cfg.WriteTimeout == 0 {
+		cfg.WriteTimeout = 10 * time.Second
+	}
+	if cfg.MaxHeaderBytes == 0 {
+		cfg.MaxHeaderBytes = 1 << 20
+	}
+
+	return &Server{
+		httpServer: &http.Server{
+			Addr:           address,
+			Handler:        handler,
+			MaxHeaderBytes: cfg.MaxHeaderBytes,
+			ReadTimeout:    cfg.ReadTimeout,
+			WriteTimeout:   cfg.WriteTimeout,
+		},
 	}
+}
 
-	return s.httpServer.ListenAndServe()
+// Start blocks until the server stops. It returns nil after a graceful
+// Shutdown, including one that happened before Start.
+func (s *Server) Start() error {
+	err := s.httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
 
-func (s Server) Shutdown(ctx context.Context) error {
+func (s *Server) Shutdown(ctx context.Context) error {
 	return s.httpServer.Shutdown(ctx)
 }
@@ -0,0 +1,28 @@
+package httpserver_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"pvz-test/pkg/httpserver"
+)
+
+func TestServer_ShutdownBeforeStart(t *testing.T) {
+	srv := httpserver.New("127.0.0.1:0", http.NotFoundHandler(), httpserver.Config{})
+
+	assert.NoError(t, srv.Shutdown(context.Background()))
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Start() }()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Start kept serving after Shutdown")
+	}
+}
@@ -17,8 +17,12 @@ const baseURL = "http://localhost:8080/api"
 func TestIntegration_ProcessPVZReception(t *testing.T) {
 
 	t.Skip("Skipping test in short mode.")
-	var pvzID uuid.UUID
+	var pvzID, employeeID uuid.UUID
 	var token string
+	// dummyLogin tokens share one user that cannot be assigned to a PVZ, so
+	// receptions are opened by a registered employee.
+	employeeEmail := "employee-" + uuid.NewString() + "@example.com"
+	employeePassword := "password"
 
 	t.Run("Moderator dummy login", func(t *testing.T) {
 		reqBody := models.DummyLoginRequest{Role: models.RoleModerator}
@@ -60,12 +64,41 @@ func TestIntegration_ProcessPVZReception(t *testing.T) {
 		pvzID = createdPVZ.ID
 	})
 
-	t.Run("Employee dummy login", func(t *testing.T) {
-		reqBody := models.DummyLoginRequest{Role: models.RoleEmployee}
+	t.Run("Register employee", func(t *testing.T) {
+		reqBody := models.RegisterRequest{Email: employeeEmail, Password: employeePassword, Role: string(models.RoleEmployee)}
 		body, err := json.Marshal(reqBody)
 		require.NoError(t, err)
 
-		resp, err := http.Post(baseURL+"/dummyLogin", "application/json", bytes.NewBuffer(body))
+		resp, err := http.Post(baseURL+"/register", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		defer resp.Body.Close()
+
+		var user models.UserResponse
+		err = json.NewDecoder(resp.Body).Decode(&user)
+		require.NoError(t, err)
+
+		employeeID = user.ID
+	})
+
+	t.Run("Assign employee", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPut, baseURL+"/pvz/"+pvzID.String()+"/employees/"+employeeID.String(), nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		client := &http.Client{}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		defer resp.Body.Close()
+	})
+
+	t.Run("Employee login", func(t *testing.T) {
+		reqBody := models.LoginRequest{Email: employeeEmail, Password: employeePassword}
+		body, err := json.Marshal(reqBody)
+		require.NoError(t, err)
+
+		resp, err := http.Post(baseURL+"/login", "application/json", bytes.NewBuffer(body))
 		require.NoError(t, err)
 		require.Equal(t, http.StatusOK, resp.StatusCode)
 		defer resp.Body.Close()